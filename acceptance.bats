@@ -54,6 +54,15 @@
   [[ "$output" =~ "Containers must not run as root" ]]
 }
 
+@test "Route files to their own policies with mappings from configuration file" {
+  cd examples/mapping
+  run ../../conftest test --no-color .
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "FAIL - terraform/main.tf - terraform - Bucket logs must not be public" ]]
+  [[ "$output" =~ "FAIL - k8s/deployment.yaml - kubernetes - Deployment web must have at least 2 replicas" ]]
+  [[ "$output" =~ "FAIL - docker/Dockerfile - docker - Image nginx:latest must not use the latest tag" ]]
+}
+
 @test "Has version flag" {
   run ./conftest --version
   [ "$status" -eq 0 ]
@@ -281,6 +290,155 @@
   [[ "$output" =~ "ALB \`my-alb-listener\` is using HTTP rather than HTTPS" ]]
 }
 
+@test "Can evaluate the variables and locals of hcl files" {
+  run ./conftest test -p examples/hcl2-evaluate/policy --hcl2-evaluate examples/hcl2-evaluate/main.tf
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "S3 bucket ACL \`logs\` must not be publicly readable" ]]
+  [[ "$output" =~ "found 7" ]]
+}
+
+@test "Can evaluate hcl files with the variable files tested along with them" {
+  run ./conftest test -p examples/hcl2-evaluate/policy --hcl2-evaluate examples/hcl2-evaluate/main.tf examples/hcl2-evaluate/prod.tfvars
+  [ "$status" -eq 0 ]
+}
+
+@test "Can evaluate hcl files with an explicit variable file" {
+  run ./conftest test -p examples/hcl2-evaluate/policy --hcl2-evaluate --hcl2-var-file examples/hcl2-evaluate/prod.tfvars examples/hcl2-evaluate/main.tf
+  [ "$status" -eq 0 ]
+}
+
+@test "Can identify the objects of kubernetes manifests" {
+  run ./conftest test --no-color -p examples/kubernetes-schema/policy --parser kubernetes examples/kubernetes-schema/manifests.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "FAIL - examples/kubernetes-schema/manifests.yaml (Service web/hello-kubernetes) - main" ]]
+}
+
+@test "Can validate kubernetes manifests against the schemas of their kinds" {
+  run ./conftest test --no-color -p examples/kubernetes-schema/policy --parser kubernetes --kubernetes-schema-dir examples/kubernetes-schema/schemas examples/kubernetes-schema/manifests.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "apps/v1 Deployment does not match its schema at /spec/replicas: got string, want integer" ]]
+  [[ "$output" =~ "no schema found for v1 ConfigMap" ]]
+}
+
+@test "Can render and test helm charts" {
+  run ./conftest test --no-color --render -p examples/helm/policy examples/helm/chart
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "FAIL - examples/helm/chart/templates/deployment.yaml (Deployment release-name-hello-kubernetes) - main - Deployment release-name-hello-kubernetes must run at least 2 replicas" ]]
+}
+
+@test "Can render helm charts with values files" {
+  run ./conftest test --render -p examples/helm/policy examples/helm/chart --values examples/helm/values-prod.yaml
+  [ "$status" -eq 0 ]
+}
+
+@test "Can build and test kustomizations" {
+  run ./conftest test --no-color --render -p examples/kustomize/policy examples/kustomize
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "FAIL - examples/kustomize/deployment.yaml (Deployment the-deployment) - main - Containers must not run as root" ]]
+  [[ "$output" =~ "WARN - examples/kustomize/service.yaml (Service the-service) - main - Services are not allowed" ]]
+}
+
+@test "Test the files of kustomizations as they are without --render" {
+  run ./conftest test --no-color -p examples/kustomize/policy examples/kustomize
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "12 tests" ]]
+}
+
+@test "Can parse compose files with their override files" {
+  run ./conftest test --parser compose -p examples/compose-override/policy examples/compose-override/docker-compose.yml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "Service web publishes port 8443 on all interfaces" ]]
+  [[ "$output" =~ "Service worker must not mount the Docker socket" ]]
+  [[ "$output" =~ "3 tests, 1 passed, 0 warnings, 2 failures" ]]
+}
+
+@test "Can parse github actions workflows" {
+  run ./conftest test --no-color -p examples/github-actions/policy examples/github-actions/.github/workflows/ci.yml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "Job test uses actions/setup-go@v5, which must be pinned to a commit SHA" ]]
+  [[ "$output" =~ "2 tests, 0 passed, 0 warnings, 2 failures" ]]
+}
+
+@test "Can parse gitlab ci configurations with their includes" {
+  run ./conftest test --no-color -p examples/gitlab-ci/policy examples/gitlab-ci/.gitlab-ci.yml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "Job deploy must not use the latest tag of alpine:latest" ]]
+  [[ "$output" =~ "2 tests, 0 passed, 0 warnings, 2 failures" ]]
+}
+
+@test "Can add the comments and duplicate keys of yaml documents" {
+  run ./conftest test --no-color --yaml-metadata -p examples/yaml-metadata/policy examples/yaml-metadata/config.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "Key replicas is declared more than once in /services/worker, on lines [14, 15]" ]]
+  [[ "$output" =~ "The secret reference of worker must have an owner comment" ]]
+}
+
+@test "Can report duplicate keys and yaml 1.1 pitfalls with --strict-parse" {
+  run ./conftest test --no-color --strict-parse -p examples/strict-parse/policy examples/strict-parse/config.yaml examples/strict-parse/package.json
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "strict - NO is read as the boolean false, quote it to keep it a string" ]]
+  [[ "$output" =~ "strict - 0644 is read as the octal number 420" ]]
+  [[ "$output" =~ "examples/strict-parse/package.json - strict - key \"test\" is declared on line 5 and again on line 6" ]]
+}
+
+@test "Does not report yaml 1.1 pitfalls without --strict-parse" {
+  run ./conftest test --no-color -p examples/strict-parse/policy examples/strict-parse/config.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" != *"strict"* ]]
+}
+
+@test "Can print the fixes of policies as a diff with fix --dry-run" {
+  run ./conftest fix --dry-run -p examples/fix/policy examples/fix/deployment.yaml examples/fix/package.json examples/fix/main.tf
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "+        image: nginx:1.27" ]]
+  [[ "$output" =~ "+  acl    = \"private\" # TODO: review" ]]
+  [[ "$output" =~ "+    \"private\": true," ]]
+}
+
+@test "Only tests the files that changed since a ref with --changed-since" {
+  run ./conftest test --no-color --changed-since HEAD -p examples/kubernetes/policy examples/kubernetes
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "0 tests, 0 passed" ]]
+}
+
+@test "Requires --combine and --changed-since for --combine-unchanged" {
+  run ./conftest test --combine-unchanged -p examples/kubernetes/policy examples/kubernetes
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "including unchanged files requires combining the files that changed since a ref" ]]
+}
+
+@test "Skip the paths of .conftestignore files in directories" {
+  DIR="$(mktemp -d)"
+  mkdir -p "$DIR/vendor"
+  echo "vendor/" > "$DIR/.conftestignore"
+  cp examples/kubernetes/service.yaml "$DIR/service.yaml"
+  cp examples/kubernetes/deployment.yaml "$DIR/vendor/deployment.yaml"
+
+  run ./conftest test -p examples/kubernetes/policy "$DIR"
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "0 failures" ]]
+
+  run ./conftest parse "$DIR"
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "\"kind\": \"Service\"" ]]
+  [[ ! "$output" =~ "Deployment" ]]
+  rm -rf "$DIR"
+}
+
+@test "Detect the parser of files with an unknown extension from their contents" {
+  DIR="$(mktemp -d)"
+  printf '[server]\nhost = example.com\n' > "$DIR/app.conf"
+
+  run ./conftest parse "$DIR/app.conf"
+  [ "$status" -eq 1 ]
+
+  run ./conftest parse --detect-parser "$DIR/app.conf"
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "Detected the ini parser for $DIR/app.conf" ]]
+  [[ "$output" =~ "\"host\": \"example.com\"" ]]
+  rm -rf "$DIR"
+}
+
 @test "Can parse properties files" {
   run ./conftest test -p examples/properties/policy/ examples/properties/sample.properties
   [ "$status" -eq 0 ]
@@ -314,6 +472,13 @@
   [[ "$output" =~ "unallowed image found [\"openjdk:8-jdk-alpine\"]" ]]
 }
 
+@test "Can follow the stages of multi-stage docker files" {
+  run ./conftest test --no-color -p examples/docker-multistage/policy examples/docker-multistage/Dockerfile
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "Stage build pipes a downloaded script to sh" ]]
+  [[ "$output" =~ "3 tests, 2 passed, 0 warnings, 1 failure" ]]
+}
+
 @test "Can parse newly introduced keywords for docker" {
   run bash -c "cat <<EOF | ./conftest parse --parser dockerfile -
 # syntax=docker/dockerfile:1.4
@@ -572,6 +737,19 @@ EOF"
   [[ "$output" =~ "look up message type" ]]
 }
 
+@test "Can evaluate the normalized resources of a Terraform plan" {
+  run ./conftest test -p examples/tfplan/policy --parser tfplan examples/tfplan/prod.tfplan.json
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "aws_s3_bucket_acl.logs must not be publicly readable" ]]
+  [[ "$output" =~ "module.network.aws_subnet.private[0] will be replaced" ]]
+}
+
+@test "Terraform plan parser rejects JSON that is not a plan" {
+  run ./conftest test -p examples/tfplan/policy --parser tfplan examples/ts/package.json
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "not a Terraform plan" ]]
+}
+
 @test "Can parse files from a symlinked directory" {
   TMPDIR="$(mktemp -d -u)"
   ln -s $(pwd)/examples/hcl2 ${TMPDIR}
@@ -0,0 +1,180 @@
+// Package baseline records the failures and warnings found by a test run, so
+// that later runs only report the findings that are not part of the baseline.
+package baseline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/open-policy-agent/conftest/output"
+)
+
+// version is the version of the baseline file format.
+const version = 1
+
+// Baseline is a set of known findings.
+type Baseline struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+// Entry is the fingerprint of a single failure or warning. The message is
+// hashed so the baseline does not grow with the length of the messages.
+type Entry struct {
+	File        string `json:"file"`
+	Namespace   string `json:"namespace"`
+	Rule        string `json:"rule,omitempty"`
+	MessageHash string `json:"message_hash"`
+}
+
+// New creates a baseline of the failures and warnings in the given results.
+func New(results output.CheckResults) *Baseline {
+	baseline := Baseline{
+		Version: version,
+		Entries: []Entry{},
+	}
+
+	for _, checkResult := range results {
+		for _, result := range findings(checkResult) {
+			baseline.Entries = append(baseline.Entries, newEntry(checkResult, result))
+		}
+	}
+
+	// Sort the entries so that the file does not change between runs that
+	// found the same results.
+	sort.Slice(baseline.Entries, func(i, j int) bool {
+		a, b := baseline.Entries[i], baseline.Entries[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.MessageHash < b.MessageHash
+	})
+
+	return &baseline
+}
+
+// Load reads the baseline at the given path.
+func Load(path string) (*Baseline, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read baseline: %w", err)
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(contents, &baseline); err != nil {
+		return nil, fmt.Errorf("unmarshal baseline: %w", err)
+	}
+
+	if baseline.Version != version {
+		return nil, fmt.Errorf("unsupported baseline version %d", baseline.Version)
+	}
+
+	return &baseline, nil
+}
+
+// Save writes the baseline to the given path.
+func (b *Baseline) Save(path string) error {
+	contents, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal baseline: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(contents, '\n'), 0600); err != nil {
+		return fmt.Errorf("write baseline: %w", err)
+	}
+
+	return nil
+}
+
+// Filter removes the failures and warnings that are part of the baseline from
+// the given results. The entries of the baseline that were not found in the
+// results of their file and namespace have been fixed since the baseline was
+// created, and are returned so that the baseline can be updated. Entries of
+// files and namespaces that are not in the results, because they were not
+// tested, are not considered fixed.
+//
+// Every entry suppresses a single finding, so a finding that now occurs more
+// often than when the baseline was created is still reported.
+func (b *Baseline) Filter(results output.CheckResults) (output.CheckResults, []Entry) {
+	remaining := make(map[Entry]int, len(b.Entries))
+	for _, entry := range b.Entries {
+		remaining[entry]++
+	}
+
+	filter := func(checkResult output.CheckResult, results []output.Result) []output.Result {
+		var filtered []output.Result
+		for _, result := range results {
+			entry := newEntry(checkResult, result)
+			if remaining[entry] > 0 {
+				remaining[entry]--
+				continue
+			}
+
+			filtered = append(filtered, result)
+		}
+
+		return filtered
+	}
+
+	type scope struct{ file, namespace string }
+	tested := make(map[scope]bool, len(results))
+
+	filtered := make(output.CheckResults, 0, len(results))
+	for _, checkResult := range results {
+		tested[scope{filepath.ToSlash(checkResult.FileName), checkResult.Namespace}] = true
+		checkResult.Failures = filter(checkResult, checkResult.Failures)
+		checkResult.Warnings = filter(checkResult, checkResult.Warnings)
+		filtered = append(filtered, checkResult)
+	}
+
+	var fixed []Entry
+	for _, entry := range b.Entries {
+		if !tested[scope{entry.File, entry.Namespace}] {
+			continue
+		}
+		if remaining[entry] > 0 {
+			remaining[entry]--
+			fixed = append(fixed, entry)
+		}
+	}
+
+	return filtered, fixed
+}
+
+func findings(checkResult output.CheckResult) []output.Result {
+	results := make([]output.Result, 0, len(checkResult.Failures)+len(checkResult.Warnings))
+	results = append(results, checkResult.Failures...)
+	results = append(results, checkResult.Warnings...)
+
+	return results
+}
+
+// newEntry creates the fingerprint of the given result. The rule is identified
+// by its ID or name when known, and by the query that produced it otherwise.
+func newEntry(checkResult output.CheckResult, result output.Result) Entry {
+	var rule string
+	if result.Rule != nil {
+		rule = result.Rule.String()
+	} else if query, ok := result.Metadata["query"].(string); ok {
+		rule = query
+	}
+
+	hash := sha256.Sum256([]byte(result.Message))
+	return Entry{
+		File:        filepath.ToSlash(checkResult.FileName),
+		Namespace:   checkResult.Namespace,
+		Rule:        rule,
+		MessageHash: hex.EncodeToString(hash[:]),
+	}
+}
@@ -0,0 +1,103 @@
+package baseline
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/open-policy-agent/conftest/output"
+)
+
+func TestFilter(t *testing.T) {
+	created := output.CheckResults{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "main",
+			Failures: []output.Result{
+				{Message: "too few replicas", Rule: &output.Rule{Package: "main", Name: "deny", ID: "K8S-001"}},
+				{Message: "missing labels", Rule: &output.Rule{Package: "main", Name: "deny"}},
+			},
+			Warnings: []output.Result{
+				{Message: "latest tag", Metadata: map[string]any{"query": "data.main.warn"}},
+			},
+		},
+		{
+			FileName:  "ingress.yaml",
+			Namespace: "main",
+			Failures: []output.Result{
+				{Message: "missing tls", Rule: &output.Rule{Package: "main", Name: "deny"}},
+			},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := New(created).Save(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	baseline, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	// The labels were fixed, the tag warning is found twice, a new file was added
+	// and the ingress was not tested.
+	current := output.CheckResults{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "main",
+			Failures: []output.Result{
+				{Message: "too few replicas", Rule: &output.Rule{Package: "main", Name: "deny", ID: "K8S-001"}},
+			},
+			Warnings: []output.Result{
+				{Message: "latest tag", Metadata: map[string]any{"query": "data.main.warn"}},
+				{Message: "latest tag", Metadata: map[string]any{"query": "data.main.warn"}},
+			},
+		},
+		{
+			FileName:  "service.yaml",
+			Namespace: "main",
+			Failures: []output.Result{
+				{Message: "missing labels", Rule: &output.Rule{Package: "main", Name: "deny"}},
+			},
+		},
+	}
+
+	filtered, fixed := baseline.Filter(current)
+
+	want := output.CheckResults{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "main",
+			Warnings: []output.Result{
+				{Message: "latest tag", Metadata: map[string]any{"query": "data.main.warn"}},
+			},
+		},
+		{
+			FileName:  "service.yaml",
+			Namespace: "main",
+			Failures: []output.Result{
+				{Message: "missing labels", Rule: &output.Rule{Package: "main", Name: "deny"}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(filtered, want) {
+		t.Errorf("unexpected results. got %+v, want %+v", filtered, want)
+	}
+
+	if len(fixed) != 1 || fixed[0].File != "deployment.yaml" || fixed[0].Rule != "main.deny" {
+		t.Errorf("unexpected fixed entries: %+v", fixed)
+	}
+}
+
+func TestLoadUnsupportedVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	baseline := Baseline{Version: 2}
+	if err := baseline.Save(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("expected an error for an unsupported version")
+	}
+}
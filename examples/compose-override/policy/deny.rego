@@ -0,0 +1,22 @@
+package main
+
+deny contains msg if {
+	some name, service in input.services
+	some port in service.ports
+	not port.host_ip
+	msg := sprintf("Service %s publishes port %s on all interfaces", [name, port.published])
+}
+
+deny contains msg if {
+	some name, service in input.services
+	some volume in service.volumes
+	volume.type == "bind"
+	volume.source == "/var/run/docker.sock"
+	msg := sprintf("Service %s must not mount the Docker socket", [name])
+}
+
+deny contains msg if {
+	some name, service in input.services
+	endswith(service.image, ":latest")
+	msg := sprintf("Service %s must not use the latest tag", [name])
+}
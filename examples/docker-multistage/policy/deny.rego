@@ -0,0 +1,30 @@
+package main
+
+root_users := {"", "root", "0"}
+
+deny contains msg if {
+	final := [cmd | some cmd in input; cmd.Final]
+	last := final[count(final) - 1]
+	root_users[split(last.User, ":")[0]]
+	msg := "The final stage must not run as root"
+}
+
+deny contains msg if {
+	some cmd in input
+	cmd.Cmd == "run"
+	some i, download in cmd.Run
+	download.Args[0] in {"curl", "wget"}
+	download.Pipe
+	cmd.Run[i + 1].Args[0] in {"sh", "bash"}
+	msg := sprintf("Stage %s pipes a downloaded script to %s", [cmd.StageName, cmd.Run[i + 1].Args[0]])
+}
+
+deny contains msg if {
+	some cmd in input
+	cmd.Cmd == "copy"
+	cmd.Final
+	cmd.FromStage == null
+	some flag in cmd.Flags
+	startswith(flag, "--from=")
+	msg := sprintf("The final stage copies from the image %s instead of a build stage", [substring(flag, 7, -1)])
+}
@@ -0,0 +1,9 @@
+# Bucket for the build artifacts.
+resource "aws_s3_bucket" "artifacts" {
+  bucket = "artifacts-${var.env}"
+  acl    = "public-read" # TODO: review
+
+  tags = {
+    team = "build"
+  }
+}
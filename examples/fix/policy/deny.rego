@@ -0,0 +1,37 @@
+package main
+
+import rego.v1
+
+deny contains {
+	"msg": sprintf("Container %s must not use the latest tag", [container.name]),
+	"fix": [{"op": "replace", "path": sprintf("/spec/template/spec/containers/%d/image", [i]), "value": "nginx:1.27"}],
+} if {
+	input.kind == "Deployment"
+	some i, container in input.spec.template.spec.containers
+	endswith(container.image, ":latest")
+}
+
+warn contains {
+	"msg": sprintf("Container %s should not run as root", [container.name]),
+	"fix": [{"op": "add", "path": sprintf("/spec/template/spec/containers/%d/securityContext", [i]), "value": {"runAsNonRoot": true}}],
+} if {
+	input.kind == "Deployment"
+	some i, container in input.spec.template.spec.containers
+	not container.securityContext.runAsNonRoot
+}
+
+deny contains {
+	"msg": "Packages must be private",
+	"fix": {"private": true},
+} if {
+	input.name
+	input.private == false
+}
+
+deny contains {
+	"msg": sprintf("Bucket %s must not be public", [name]),
+	"fix": [{"op": "replace", "path": sprintf("/resource/aws_s3_bucket/%s/0/acl", [name]), "value": "private"}],
+} if {
+	some name, buckets in input.resource.aws_s3_bucket
+	buckets[0].acl == "public-read"
+}
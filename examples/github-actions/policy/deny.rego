@@ -0,0 +1,9 @@
+package main
+
+deny contains msg if {
+	some id, job in input.jobs
+	some step in job.steps
+	step.action.kind == "repository"
+	not step.action.pinned
+	msg := sprintf("Job %s uses %s/%s@%s, which must be pinned to a commit SHA", [id, step.action.owner, step.action.repo, step.action.ref])
+}
@@ -0,0 +1,14 @@
+package main
+
+deny contains msg if {
+	some name, job in input.jobs
+	endswith(job.image.name, ":latest")
+	msg := sprintf("Job %s must not use the latest tag of %s", [name, job.image.name])
+}
+
+deny contains msg if {
+	some name, job in input.jobs
+	some command in job.script
+	regex.match(`curl[^|]*\|\s*(ba)?sh`, command)
+	msg := sprintf("Job %s must not pipe a downloaded script to a shell", [name])
+}
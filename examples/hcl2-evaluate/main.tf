@@ -0,0 +1,18 @@
+locals {
+  bucket_name = "${var.environment}-logs"
+  public      = var.environment != "prod"
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = local.bucket_name
+}
+
+resource "aws_s3_bucket_acl" "logs" {
+  bucket = aws_s3_bucket.logs.id
+  acl    = local.public ? "public-read" : "private"
+}
+
+resource "aws_cloudwatch_log_group" "logs" {
+  name              = upper(local.bucket_name)
+  retention_in_days = var.log_retention_days
+}
@@ -0,0 +1,15 @@
+package main
+
+deny contains msg if {
+	some name
+	some acl in input.resource.aws_s3_bucket_acl[name]
+	acl.acl == "public-read"
+	msg := sprintf("S3 bucket ACL `%v` must not be publicly readable", [name])
+}
+
+deny contains msg if {
+	some name
+	some group in input.resource.aws_cloudwatch_log_group[name]
+	group.retention_in_days < 30
+	msg := sprintf("Log group `%v` must retain logs for at least 30 days, found %v", [name, group.retention_in_days])
+}
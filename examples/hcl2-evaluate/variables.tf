@@ -0,0 +1,9 @@
+variable "environment" {
+  type    = string
+  default = "dev"
+}
+
+variable "log_retention_days" {
+  type    = number
+  default = 7
+}
@@ -0,0 +1,13 @@
+package main
+
+deny contains msg if {
+	input.kind == "Deployment"
+	not input.spec.template.spec.securityContext.runAsNonRoot
+	msg := sprintf("Containers must not run as root in Deployment %s", [input.metadata.name])
+}
+
+deny contains msg if {
+	input.kind == "Deployment"
+	input.spec.replicas < 2
+	msg := sprintf("Deployment %s must run at least 2 replicas", [input.metadata.name])
+}
@@ -0,0 +1,7 @@
+package main
+
+deny contains msg if {
+	input.kind == "Service"
+	input.spec.type == "LoadBalancer"
+	msg := "Services must not be exposed through a load balancer"
+}
@@ -0,0 +1,10 @@
+package docker
+
+import rego.v1
+
+deny contains msg if {
+	some command in input
+	command.Cmd == "from"
+	endswith(command.Value[0], ":latest")
+	msg := sprintf("Image %s must not use the latest tag", [command.Value[0]])
+}
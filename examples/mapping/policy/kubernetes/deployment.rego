@@ -0,0 +1,9 @@
+package kubernetes
+
+import rego.v1
+
+deny contains msg if {
+	input.kind == "Deployment"
+	input.spec.replicas < 2
+	msg := sprintf("Deployment %s must have at least 2 replicas", [input.metadata.name])
+}
@@ -0,0 +1,9 @@
+package terraform
+
+import rego.v1
+
+deny contains msg if {
+	some name, buckets in input.resource.aws_s3_bucket
+	buckets[0].acl == "public-read"
+	msg := sprintf("Bucket %s must not be public", [name])
+}
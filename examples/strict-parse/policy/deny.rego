@@ -0,0 +1,7 @@
+package main
+
+deny contains msg if {
+	country := input.service.country
+	not is_string(country)
+	msg := sprintf("The country of the service must be a country code, found %v", [country])
+}
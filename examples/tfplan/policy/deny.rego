@@ -0,0 +1,14 @@
+package main
+
+deny contains {"msg": msg, "path": ["resources", i, "after", "acl"]} if {
+	some i, resource in input.resources
+	resource.type == "aws_s3_bucket_acl"
+	resource.after.acl == "public-read"
+	msg := sprintf("%s must not be publicly readable", [resource.address])
+}
+
+warn contains msg if {
+	some resource in input.resources
+	resource.action == "replace"
+	msg := sprintf("%s will be replaced", [resource.address])
+}
@@ -0,0 +1,16 @@
+package main
+
+deny contains msg if {
+	some name, service in input.services
+	service.secretRef
+	path := sprintf("/services/%s/secretRef", [name])
+	not has_owner(path)
+	msg := sprintf("The secret reference of %s must have an owner comment", [name])
+}
+
+has_owner(path) if startswith(input.__comments[path].head, "owner:")
+
+deny contains msg if {
+	some duplicate in input.__duplicate_keys
+	msg := sprintf("Key %s is declared more than once in %s, on lines %v", [duplicate.key, duplicate.path, duplicate.lines])
+}
@@ -54,6 +54,7 @@ func NewDefaultCommand() *cobra.Command {
 	cmd.AddCommand(NewPushCommand(ctx, logger))
 	cmd.AddCommand(NewPullCommand(ctx))
 	cmd.AddCommand(NewVerifyCommand(ctx))
+	cmd.AddCommand(NewFixCommand(ctx))
 	cmd.AddCommand(NewPluginCommand(ctx))
 	cmd.AddCommand(NewFormatCommand())
 	cmd.AddCommand(NewReformatCommand())
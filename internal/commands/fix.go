@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/parser"
+	"github.com/open-policy-agent/conftest/runner"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const fixDesc = `
+This command applies the fixes of the policies to your configuration files.
+
+Failures and warnings can carry a fix under the 'fix' key of the result, as
+either a JSON Patch (RFC 6902), which is a list of operations, or a JSON merge
+patch (RFC 7396), which is an object. The paths of the patches are relative to
+the document that produced the result, e.g.:
+
+	deny contains {
+		"msg": "Containers must not run as root",
+		"fix": [{"op": "add", "path": "/spec/securityContext/runAsNonRoot", "value": true}],
+	} if {
+		not input.spec.securityContext.runAsNonRoot
+	}
+
+The fix command tests the given files in the same way as the test command, and
+writes the fixes of the failures and warnings back to the files they were found
+in. Only the values that change are written again, so that the comments and
+formatting of YAML, JSON and HCL2 files are kept. Every file is tested on its
+own and as it is written, so the combine, stream and render settings of the
+configuration file do not apply.
+
+The '--dry-run' flag prints the changes as a unified diff instead of writing
+them, e.g.:
+
+	$ conftest fix --dry-run deployment.yaml
+
+Fixes that cannot be applied, for example because an earlier fix removed the
+value they patch, are reported and skipped.
+`
+
+// NewFixCommand creates a new fix command.
+func NewFixCommand(ctx context.Context) *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "fix <path> [path [...]]",
+		Short: "Apply the fixes of the policies to your configuration files",
+		Long:  fixDesc,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			flagNames := []string{
+				"all-namespaces",
+				"capabilities",
+				"data",
+				"detect-parser",
+				"dry-run",
+				"gitignore",
+				"ignore",
+				"namespace",
+				"parser",
+				"policy",
+				"rego-version",
+				"strict",
+				"tls",
+				"update",
+			}
+			for _, name := range flagNames {
+				if err := viper.BindPFlag(name, cmd.Flags().Lookup(name)); err != nil {
+					return fmt.Errorf("bind flag: %w", err)
+				}
+			}
+
+			return nil
+		},
+
+		RunE: func(cmd *cobra.Command, fileList []string) error {
+			if len(fileList) < 1 {
+				cmd.Usage() //nolint
+				return fmt.Errorf("missing required arguments")
+			}
+
+			var runner runner.FixRunner
+			if err := viper.Unmarshal(&runner); err != nil {
+				return fmt.Errorf("unmarshal parameters: %w", err)
+			}
+
+			fileFixes, err := runner.Run(ctx, fileList)
+			if err != nil {
+				return fmt.Errorf("running fix: %w", err)
+			}
+
+			var failed bool
+			for _, fileFix := range fileFixes {
+				for _, err := range fileFix.Errors {
+					fmt.Fprintf(os.Stderr, "%s: skipped: %v\n", fileFix.FileName, err)
+					failed = true
+				}
+
+				if !fileFix.Changed() {
+					continue
+				}
+
+				if runner.DryRun {
+					diff, err := unifiedDiff(fileFix)
+					if err != nil {
+						return fmt.Errorf("diff %s: %w", fileFix.FileName, err)
+					}
+					fmt.Print(diff)
+					continue
+				}
+
+				fmt.Printf("%s: applied %d %s\n", fileFix.FileName, len(fileFix.Applied), plural(len(fileFix.Applied), "fix", "fixes"))
+			}
+
+			if failed {
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("dry-run", false, "Print the changes as a unified diff instead of writing them to the files")
+	cmd.Flags().Bool("all-namespaces", false, "Apply the fixes of policies found in all namespaces")
+	cmd.Flags().Bool("strict", false, "Enable strict mode for Rego policies")
+	cmd.Flags().Bool("tls", true, "Use TLS to access the registry")
+
+	cmd.Flags().Bool("detect-parser", false, "Detect the parser of the files whose name and extension are unknown from their contents")
+	cmd.Flags().Bool("gitignore", false, "Also skip the paths ignored by .gitignore files when looking for files in directories")
+	cmd.Flags().String("ignore", "", "A regex pattern which can be used for ignoring paths")
+	cmd.Flags().String("parser", "", fmt.Sprintf("Parser to use to parse the configurations. Valid parsers: %s", parser.Parsers()))
+	cmd.Flags().String("capabilities", "", "Path to JSON file that can restrict opa functionality against a given policy. Default: all operations allowed")
+	cmd.Flags().String("rego-version", "v1", "Which version of Rego syntax to use. Options: v0, v1")
+
+	cmd.Flags().StringSliceP("policy", "p", []string{"policy"}, "Path to the Rego policy files directory")
+	cmd.Flags().StringSliceP("update", "u", []string{}, "A list of URLs can be provided to the update flag, which will download before the fixes are applied")
+	cmd.Flags().StringSliceP("namespace", "n", []string{"main"}, "Apply the fixes of policies in specific namespaces. Supports glob wildcards (*, ?, [...])")
+	cmd.Flags().StringSliceP("data", "d", []string{}, "A list of paths from which data for the rego policies will be recursively loaded")
+
+	return &cmd
+}
+
+// unifiedDiff returns the changes the fixes make to the file as a unified
+// diff.
+func unifiedDiff(fileFix runner.FileFix) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(fileFix.Original),
+		B:        splitLines(fileFix.Fixed),
+		FromFile: "a/" + fileFix.FileName,
+		ToFile:   "b/" + fileFix.FileName,
+		Context:  3,
+	})
+}
+
+// splitLines splits the contents into lines that keep their line breaks.
+func splitLines(contents []byte) []string {
+	lines := strings.SplitAfter(string(contents), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
@@ -3,14 +3,20 @@ package commands
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
+	"os"
+	"slices"
 
 	"github.com/open-policy-agent/conftest/parser"
+	"github.com/open-policy-agent/conftest/runner"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 const parseDesc = `
-This command prints the internal representation of your input files.
+This command prints the internal representation of your input files. The
+supported files within directories are parsed, except for the paths ignored by
+.conftestignore files or the '--ignore' flag.
 
 This can be useful in helping to write Rego policies. It is not always clear how 
 your input file will be represented in the Rego policies. The type of the input is inferred
@@ -19,6 +25,11 @@ the '--parser' flag can be used to explicitly set the parser, e.g.:
 
 	$ conftest parse --parser toml <input-file(s)>
 
+Alternatively, the '--detect-parser' flag detects the parser of the files with an unknown
+name or extension from their contents, and reports the detected parsers, e.g.:
+
+	$ conftest parse --detect-parser app.conf
+
 See the documentation of the '--parser' flag for the supported parsers.
 `
 
@@ -30,7 +41,7 @@ func NewParseCommand() *cobra.Command {
 		Short: "Print out structured data from your input files",
 		Long:  parseDesc,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			flagNames := []string{"parser", "combine"}
+			flagNames := []string{"parser", "combine", "detect-parser", "gitignore", "hcl2-evaluate", "hcl2-var-file", "ignore", "yaml-metadata"}
 			for _, name := range flagNames {
 				if err := viper.BindPFlag(name, cmd.Flags().Lookup(name)); err != nil {
 					return fmt.Errorf("bind flag: %w", err)
@@ -42,18 +53,23 @@ func NewParseCommand() *cobra.Command {
 
 			return nil
 		},
-		RunE: func(_ *cobra.Command, files []string) error {
-			var configurations map[string]any
-			var err error
-			if viper.GetString("parser") != "" {
-				configurations, err = parser.ParseConfigurationsAs(files, viper.GetString("parser"))
-			} else {
-				configurations, err = parser.ParseConfigurations(files)
+		RunE: func(_ *cobra.Command, fileList []string) error {
+			files, err := runner.ListFiles(fileList, viper.GetString("ignore"), viper.GetBool("gitignore"))
+			if err != nil {
+				return fmt.Errorf("list files: %w", err)
 			}
+
+			configurations, detected, err := parser.ParseConfigurationsWithParsers(files, viper.GetString("parser"))
 			if err != nil {
 				return fmt.Errorf("parse configurations: %w", err)
 			}
 
+			// The detected parsers are reported on stderr, so that the output
+			// remains valid JSON.
+			for _, path := range slices.Sorted(maps.Keys(detected)) {
+				fmt.Fprintf(os.Stderr, "Detected the %s parser for %s\n", detected[path], path)
+			}
+
 			var output string
 			if viper.GetBool("combine") {
 				output, err = parser.FormatCombined(configurations)
@@ -72,7 +88,13 @@ func NewParseCommand() *cobra.Command {
 	}
 
 	cmd.Flags().Bool("combine", false, "Combine all config files to be evaluated together")
+	cmd.Flags().Bool("detect-parser", false, "Detect the parser of the files whose name and extension are unknown from their contents")
+	cmd.Flags().Bool("gitignore", false, "Also skip the paths ignored by .gitignore files when looking for files in directories")
+	cmd.Flags().String("ignore", "", "A regex pattern which can be used for ignoring paths")
 	cmd.Flags().String("parser", "", fmt.Sprintf("Parser to use to parse the configurations. Valid parsers: %s", parser.Parsers()))
+	cmd.Flags().Bool("hcl2-evaluate", false, "Evaluate the variables, locals and functions of HCL2 files when their values can be determined")
+	cmd.Flags().StringSlice("hcl2-var-file", []string{}, "A list of Terraform variable files to use when evaluating HCL2 files")
+	cmd.Flags().Bool("yaml-metadata", false, "Add the comments, anchors, key order and duplicate keys of YAML documents under the __comments, __anchors, __keys and __duplicate_keys keys")
 
 	return &cmd
 }
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 
+	"github.com/open-policy-agent/conftest/baseline"
 	"github.com/open-policy-agent/conftest/output"
 	"github.com/open-policy-agent/conftest/parser"
 	"github.com/open-policy-agent/conftest/runner"
@@ -94,25 +96,44 @@ func NewTestCommand(ctx context.Context) *cobra.Command {
 		PreRunE: func(cmd *cobra.Command, _ []string) error {
 			flagNames := []string{
 				"all-namespaces",
+				"baseline",
+				"baseline-create",
+				"changed-since",
 				"combine",
+				"combine-unchanged",
 				"data",
+				"detect-parser",
+				"fail-on",
 				"fail-on-warn",
+				"hcl2-evaluate",
+				"hcl2-var-file",
+				"gitignore",
 				"ignore",
+				"kubernetes-schema-dir",
 				"namespace",
 				"no-color",
 				"no-fail",
 				"suppress-exceptions",
 				"output",
+				"parallel",
 				"parser",
 				"policy",
 				"proto-file-dirs",
+				"render",
+				"require-suppression-expiry",
+				"require-suppression-reason",
 				"stdin-filename",
+				"stream",
+				"watch",
 				"capabilities",
 				"rego-version",
 				"trace",
 				"strict",
+				"strict-parse",
 				"show-builtin-errors",
 				"update",
+				"values",
+				"yaml-metadata",
 				"junit-hide-message",
 				"github-hide-passed",
 				"quiet",
@@ -138,33 +159,34 @@ func NewTestCommand(ctx context.Context) *cobra.Command {
 				return fmt.Errorf("unmarshal parameters: %w", err)
 			}
 
-			results, err := runner.Run(ctx, fileList)
-			if err != nil {
-				return fmt.Errorf("running test: %w", err)
-			}
+			if runner.FailOn != "" {
+				if runner.FailOnWarn {
+					return fmt.Errorf("the fail-on and fail-on-warn flags cannot be used together")
+				}
 
-			exitCode := results.ExitCode()
-			if runner.FailOnWarn {
-				exitCode = results.ExitCodeFailOnWarn()
+				severity, err := output.ParseSeverity(runner.FailOn)
+				if err != nil {
+					return fmt.Errorf("fail on: %w", err)
+				}
+				runner.FailOn = severity
 			}
 
-			if !runner.Quiet || exitCode != 0 {
-				outputter := output.Get(runner.Output, output.Options{
-					NoColor:            runner.NoColor,
-					SuppressExceptions: runner.SuppressExceptions,
-					Tracing:            runner.Trace,
-					JUnitHideMessage:   viper.GetBool("junit-hide-message"),
-					GitHubHidePassed:   viper.GetBool("github-hide-passed"),
-				})
-				if err := outputter.Output(results); err != nil {
-					return fmt.Errorf("output results: %w", err)
+			if runner.Watch {
+				if slices.Contains(fileList, "-") {
+					return fmt.Errorf("watching standard input is not supported")
 				}
 
-				// When the no-fail parameter is set, there is no need to figure out the error code
-				// as we always want to return zero.
-				if runner.NoFail {
-					return nil
-				}
+				paths := slices.Concat(runner.Policy, runner.Data, fileList)
+				return watch(ctx, paths, runner.Output, func(changed []string) error {
+					runner.Invalidate(changed)
+					_, err := runTest(ctx, &runner, fileList)
+					return err
+				})
+			}
+
+			exitCode, err := runTest(ctx, &runner, fileList)
+			if err != nil {
+				return err
 			}
 
 			os.Exit(exitCode)
@@ -173,9 +195,12 @@ func NewTestCommand(ctx context.Context) *cobra.Command {
 	}
 
 	cmd.Flags().Bool("fail-on-warn", false, "Return a non-zero exit code if warnings or errors are found")
+	cmd.Flags().String("fail-on", "", fmt.Sprintf("Return a non-zero exit code only if failures or warnings of at least the given severity are found. Valid severities: %s", output.Severities()))
 	cmd.Flags().Bool("no-fail", false, "Return an exit code of zero even if a policy fails")
 	cmd.Flags().Bool("no-color", false, "Disable color when printing")
 	cmd.Flags().Bool("suppress-exceptions", false, "Do not include exceptions in output")
+	cmd.Flags().Bool("require-suppression-reason", false, "Only apply inline conftest:ignore directives that declare a reason")
+	cmd.Flags().Bool("require-suppression-expiry", false, "Only apply inline conftest:ignore directives that declare an expiry date")
 	cmd.Flags().Bool("all-namespaces", false, "Test policies found in all namespaces")
 	cmd.Flags().Bool("quiet", false, "Disable successful test output")
 
@@ -183,8 +208,19 @@ func NewTestCommand(ctx context.Context) *cobra.Command {
 	cmd.Flags().Bool("strict", false, "Enable strict mode for Rego policies")
 	cmd.Flags().Bool("show-builtin-errors", false, "Collect and return all encountered built-in errors")
 	cmd.Flags().Bool("combine", false, "Combine all config files to be evaluated together")
+	cmd.Flags().Bool("combine-unchanged", false, "Include the files that did not change since the --changed-since ref in the combined configurations, as context for the files that did")
+	cmd.Flags().Bool("watch", false, "Watch the policies, data and input files and run the tests again when they change")
+	cmd.Flags().Bool("render", false, "Render the directories of Helm charts and build the directories of kustomizations given as inputs, instead of testing their files")
+	cmd.Flags().Bool("stream", false, "Evaluate the documents of YAML files one at a time as they are read, instead of loading whole files into memory")
 
+	cmd.Flags().Int("parallel", 1, "Number of files to evaluate concurrently. A value of 0 uses the number of available CPUs")
+
+	cmd.Flags().String("baseline", "", "Path to a baseline file of known failures and warnings, which are not reported")
+	cmd.Flags().String("baseline-create", "", "Path to write a baseline file of the failures and warnings that were found to")
+	cmd.Flags().Bool("detect-parser", false, "Detect the parser of the files whose name and extension are unknown from their contents")
+	cmd.Flags().Bool("gitignore", false, "Also skip the paths ignored by .gitignore files when looking for files in directories")
 	cmd.Flags().String("ignore", "", "A regex pattern which can be used for ignoring paths")
+	cmd.Flags().String("changed-since", "", "Only test the files that changed between the given git ref and the working tree")
 	cmd.Flags().String("parser", "", fmt.Sprintf("Parser to use to parse the configurations. Valid parsers: %s", parser.Parsers()))
 	cmd.Flags().String("stdin-filename", "", "Filename to use in output when testing configuration from stdin")
 	cmd.Flags().String("capabilities", "", "Path to JSON file that can restrict opa functionality against a given policy. Default: all operations allowed")
@@ -200,7 +236,90 @@ func NewTestCommand(ctx context.Context) *cobra.Command {
 	cmd.Flags().StringSliceP("data", "d", []string{}, "A list of paths from which data for the rego policies will be recursively loaded")
 
 	cmd.Flags().StringSlice("proto-file-dirs", []string{}, "A list of directories containing Protocol Buffer definitions")
+	cmd.Flags().Bool("hcl2-evaluate", false, "Evaluate the variables, locals and functions of HCL2 files when their values can be determined")
+	cmd.Flags().StringSlice("hcl2-var-file", []string{}, "A list of Terraform variable files to use when evaluating HCL2 files")
+	cmd.Flags().String("kubernetes-schema-dir", "", "A directory of JSON schemas that documents parsed with the kubernetes parser are validated against")
+	cmd.Flags().StringSlice("values", []string{}, "A list of values files to render the Helm charts being tested with, when rendering with --render")
+	cmd.Flags().Bool("strict-parse", false, "Report the duplicate keys, YAML 1.1 booleans and numbers with leading zeros of YAML and JSON files as failures")
+	cmd.Flags().Bool("yaml-metadata", false, "Add the comments, anchors, key order and duplicate keys of YAML documents under the __comments, __anchors, __keys and __duplicate_keys keys")
 	cmd.Flags().Bool("tls", true, "Use TLS to access the registry")
 
 	return &cmd
 }
+
+// runTest runs the tests and outputs the results, returning the exit code the
+// results call for.
+func runTest(ctx context.Context, runner *runner.TestRunner, fileList []string) (int, error) {
+	results, err := runner.Run(ctx, fileList)
+	if err != nil {
+		return 0, fmt.Errorf("running test: %w", err)
+	}
+
+	// The baseline is created from all of the results, so that it can also be
+	// recreated from a run that used an existing baseline.
+	if runner.BaselineCreate != "" {
+		if err := baseline.New(results).Save(runner.BaselineCreate); err != nil {
+			return 0, fmt.Errorf("create baseline: %w", err)
+		}
+	}
+
+	if runner.Baseline != "" {
+		results, err = applyBaseline(runner.Baseline, results)
+		if err != nil {
+			return 0, fmt.Errorf("apply baseline: %w", err)
+		}
+	}
+
+	exitCode := results.ExitCode()
+	if runner.FailOnWarn {
+		exitCode = results.ExitCodeFailOnWarn()
+	} else if runner.FailOn != "" {
+		exitCode = results.ExitCodeFailOn(runner.FailOn)
+	}
+
+	if !runner.Quiet || exitCode != 0 {
+		outputter := output.Get(runner.Output, output.Options{
+			NoColor:            runner.NoColor,
+			SuppressExceptions: runner.SuppressExceptions,
+			Tracing:            runner.Trace,
+			JUnitHideMessage:   viper.GetBool("junit-hide-message"),
+			GitHubHidePassed:   viper.GetBool("github-hide-passed"),
+		})
+		if err := outputter.Output(results); err != nil {
+			return 0, fmt.Errorf("output results: %w", err)
+		}
+
+		// When the no-fail parameter is set, there is no need to figure out the error code
+		// as we always want to return zero.
+		if runner.NoFail {
+			return 0, nil
+		}
+	}
+
+	return exitCode, nil
+}
+
+// applyBaseline removes the findings in the baseline at the given path from the
+// results. The entries of the baseline that were not found are reported on
+// stderr, so that the baseline can be shrunk.
+func applyBaseline(path string, results output.CheckResults) (output.CheckResults, error) {
+	b, err := baseline.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("load baseline: %w", err)
+	}
+
+	results, fixed := b.Filter(results)
+	if len(fixed) > 0 {
+		entries := "entries are"
+		if len(fixed) == 1 {
+			entries = "entry is"
+		}
+
+		fmt.Fprintf(os.Stderr, "%d baseline %s fixed and can be removed from %s:\n", len(fixed), entries, path)
+		for _, entry := range fixed {
+			fmt.Fprintf(os.Stderr, "  %s - %s - %s\n", entry.File, entry.Namespace, entry.Rule)
+		}
+	}
+
+	return results, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 
 	"github.com/open-policy-agent/conftest/output"
 	"github.com/open-policy-agent/conftest/runner"
@@ -85,6 +86,7 @@ func NewVerifyCommand(ctx context.Context) *cobra.Command {
 				"show-builtin-errors",
 				"var-values",
 				"namespace",
+				"watch",
 			}
 			for _, name := range flagNames {
 				if err := viper.BindPFlag(name, cmd.Flags().Lookup(name)); err != nil {
@@ -104,35 +106,18 @@ func NewVerifyCommand(ctx context.Context) *cobra.Command {
 				runner.Report = "fails"
 			}
 
-			results, raw, err := runner.Run(ctx)
-			if err != nil {
-				return fmt.Errorf("running verification: %w", err)
+			if runner.Watch {
+				paths := slices.Concat(runner.Policy, runner.Data)
+				return watch(ctx, paths, runner.Output, func(changed []string) error {
+					runner.Invalidate(changed)
+					_, err := runVerify(ctx, &runner)
+					return err
+				})
 			}
 
-			exitCode := results.ExitCode()
-			if !runner.Quiet || exitCode != 0 {
-				outputter := output.Get(runner.Output, output.Options{
-					NoColor:          runner.NoColor,
-					Tracing:          runner.Trace,
-					ShowSkipped:      true,
-					JUnitHideMessage: viper.GetBool("junit-hide-message"),
-					GitHubHidePassed: viper.GetBool("github-hide-passed"),
-					VarValues:        runner.VarValues,
-				})
-				if runner.IsReportOptionOn() {
-					// report currently available with stdout only
-					if runner.Output != output.OutputStandard {
-						return fmt.Errorf("report flag is supported with stdout only")
-					}
-
-					if err := outputter.Report(raw, runner.Report); err != nil {
-						return fmt.Errorf("report results: %w", err)
-					}
-				} else {
-					if err := outputter.Output(results); err != nil {
-						return fmt.Errorf("output results: %w", err)
-					}
-				}
+			exitCode, err := runVerify(ctx, &runner)
+			if err != nil {
+				return err
 			}
 
 			if exitCode > 0 {
@@ -149,6 +134,7 @@ func NewVerifyCommand(ctx context.Context) *cobra.Command {
 	cmd.Flags().Bool("strict", false, "Enable strict mode for Rego policies")
 	cmd.Flags().String("report", "", "Shows output for Rego queries as a report with summary. Available options are {full|notes|fails}.")
 	cmd.Flags().Bool("show-builtin-errors", false, "Collect and return all encountered built-in errors")
+	cmd.Flags().Bool("watch", false, "Watch the policies and data and run the tests again when they change")
 
 	cmd.Flags().StringP("output", "o", output.OutputStandard, fmt.Sprintf("Output format for conftest results - valid options are: %s", output.Outputs()))
 	cmd.Flags().Bool("junit-hide-message", false, "Do not include the violation message in the JUnit test name")
@@ -165,3 +151,40 @@ func NewVerifyCommand(ctx context.Context) *cobra.Command {
 
 	return &cmd
 }
+
+// runVerify runs the Rego unit tests and outputs the results, returning the
+// exit code the results call for.
+func runVerify(ctx context.Context, runner *runner.VerifyRunner) (int, error) {
+	results, raw, err := runner.Run(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("running verification: %w", err)
+	}
+
+	exitCode := results.ExitCode()
+	if !runner.Quiet || exitCode != 0 {
+		outputter := output.Get(runner.Output, output.Options{
+			NoColor:          runner.NoColor,
+			Tracing:          runner.Trace,
+			ShowSkipped:      true,
+			JUnitHideMessage: viper.GetBool("junit-hide-message"),
+			GitHubHidePassed: viper.GetBool("github-hide-passed"),
+			VarValues:        runner.VarValues,
+		})
+		if runner.IsReportOptionOn() {
+			// report currently available with stdout only
+			if runner.Output != output.OutputStandard {
+				return 0, fmt.Errorf("report flag is supported with stdout only")
+			}
+
+			if err := outputter.Report(raw, runner.Report); err != nil {
+				return 0, fmt.Errorf("report results: %w", err)
+			}
+		} else {
+			if err := outputter.Output(results); err != nil {
+				return 0, fmt.Errorf("output results: %w", err)
+			}
+		}
+	}
+
+	return exitCode, nil
+}
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/open-policy-agent/conftest/output"
+	"github.com/open-policy-agent/conftest/runner"
+)
+
+// clearScreen moves the cursor to the top of the terminal and clears it.
+const clearScreen = "\033[H\033[2J"
+
+// watch calls run once, and again with the changed paths every time one of the
+// given paths changes. Errors are printed rather than returned, so that a
+// mistake in a policy does not stop the watch.
+//
+// When the results are printed in the standard format, the terminal is cleared
+// before every run so that only the latest results are shown.
+func watch(ctx context.Context, paths []string, outputFormat string, run func(changed []string) error) error {
+	rerun := func(changed []string) {
+		if outputFormat == output.OutputStandard {
+			fmt.Fprint(os.Stdout, clearScreen)
+		}
+
+		if err := run(changed); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+	}
+
+	rerun(nil)
+	if err := runner.Watch(ctx, paths, rerun); err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+
+	return nil
+}
@@ -37,15 +37,15 @@ func (t *AzureDevOps) Output(checkResults CheckResults) error {
 		fmt.Fprintf(t.writer, "##[section]Testing '%v' against %v policies in namespace '%v'\n", result.FileName, totalPolicies, result.Namespace)
 		fmt.Fprintf(t.writer, "##[group]See conftest results\n")
 		for _, failure := range result.Failures {
-			fmt.Fprintf(t.writer, "##vso[task.logissue type=error] file=%v --> %v\n", result.FileName, failure.Message)
+			fmt.Fprintf(t.writer, "##vso[task.logissue type=error%s] file=%v --> %v\n", logIssueLocation(failure.Location), result.FileName, failure.Message)
 		}
 
 		for _, warning := range result.Warnings {
-			fmt.Fprintf(t.writer, "##vso[task.logissue type=warning] file=%v --> %v\n", result.FileName, warning.Message)
+			fmt.Fprintf(t.writer, "##vso[task.logissue type=warning%s] file=%v --> %v\n", logIssueLocation(warning.Location), result.FileName, warning.Message)
 		}
 
 		for _, exception := range result.Exceptions {
-			fmt.Fprintf(t.writer, "##vso[task.logissue type=warning] file=%v --> %v\n", result.FileName, exception.Message)
+			fmt.Fprintf(t.writer, "##vso[task.logissue type=warning%s] file=%v --> %v\n", logIssueLocation(exception.Location), result.FileName, exception.Message)
 		}
 
 		for _, skipped := range result.Skipped {
@@ -99,6 +99,21 @@ func (t *AzureDevOps) Output(checkResults CheckResults) error {
 	return nil
 }
 
+// logIssueLocation returns the properties of a task.logissue command that point
+// the issue at the given location. No properties are returned without a location.
+func logIssueLocation(loc *Location) string {
+	if loc == nil {
+		return ""
+	}
+
+	properties := fmt.Sprintf(";sourcepath=%v;linenumber=%v", loc.File, loc.Line)
+	if loc.Column != "" {
+		properties += fmt.Sprintf(";columnnumber=%v", loc.Column)
+	}
+
+	return properties
+}
+
 func (t *AzureDevOps) Report(_ []*tester.Result, _ string) error {
 	return fmt.Errorf("report is not supported in AzureDevOps output")
 }
@@ -48,6 +48,26 @@ func TestAzureDevOps(t *testing.T) {
 				"",
 			},
 		},
+		{
+			name: "records locations",
+			input: CheckResults{
+				{
+					FileName:  "examples/kubernetes/deployment.yaml",
+					Namespace: "namespace",
+					Failures:  []Result{{Message: "first failure", Location: &Location{File: "examples/kubernetes/deployment.yaml", Line: "12", Column: "7"}}},
+					Warnings:  []Result{{Message: "first warning", Location: &Location{File: "examples/kubernetes/deployment.yaml", Line: "3"}}},
+				},
+			},
+			expected: []string{
+				"##[section]Testing 'examples/kubernetes/deployment.yaml' against 2 policies in namespace 'namespace'",
+				"##[group]See conftest results",
+				"##vso[task.logissue type=error;sourcepath=examples/kubernetes/deployment.yaml;linenumber=12;columnnumber=7] file=examples/kubernetes/deployment.yaml --> first failure",
+				"##vso[task.logissue type=warning;sourcepath=examples/kubernetes/deployment.yaml;linenumber=3] file=examples/kubernetes/deployment.yaml --> first warning",
+				"##[endgroup]",
+				"2 tests, 0 passed, 1 warning, 1 failure, 0 exceptions",
+				"",
+			},
+		},
 		{
 			name: "mixed failure, warnings and skipped",
 			input: CheckResults{
@@ -17,6 +17,22 @@ const (
 	githubError githubLevel = "error"
 )
 
+// severityLevel returns the level to annotate a result with. Results that
+// declare a severity are annotated at the level matching it, others at the
+// given default level.
+func severityLevel(result Result, defaultLevel githubLevel) githubLevel {
+	switch result.Severity {
+	case SeverityCritical, SeverityHigh:
+		return githubError
+	case SeverityMedium:
+		return githubWarn
+	case SeverityLow, SeverityInfo:
+		return githubInfo
+	default:
+		return defaultLevel
+	}
+}
+
 // GitHub represents an Outputter that outputs
 // results in GitHub workflow format.
 // https://docs.github.com/en/actions/reference/workflow-commands-for-github-actions
@@ -66,10 +82,10 @@ func (g *GitHub) Output(checkResults CheckResults) error {
 
 		g.writeLn("::group::Testing %q against %d policies in namespace %q", result.FileName, numPolicies, result.Namespace)
 		for _, failure := range result.Failures {
-			g.writeLocs(githubError, fileLoc, failure.Location, failure.Message)
+			g.writeLocs(severityLevel(failure, githubError), fileLoc, failure.Location, failure.Message)
 		}
 		for _, warning := range result.Warnings {
-			g.writeLocs(githubWarn, fileLoc, warning.Location, warning.Message)
+			g.writeLocs(severityLevel(warning, githubWarn), fileLoc, warning.Location, warning.Message)
 		}
 		for _, exception := range result.Exceptions {
 			g.writeLocs(githubInfo, fileLoc, exception.Location, exception.Message)
@@ -115,6 +131,10 @@ func (g *GitHub) writeLoc(level githubLevel, loc *Location, msg string, args ...
 	// Format the message first: the escaped file name contains '%' sequences
 	// that must not be read as verbs by the final Fprintf.
 	msg = fmt.Sprintf(msg, args...)
+	if loc.Column != "" {
+		g.writeLn("%s", fmt.Sprintf("::%s file=%s,line=%s,col=%s::%s", level, escapeProperty(loc.File), loc.Line, loc.Column, msg))
+		return
+	}
 	g.writeLn("%s", fmt.Sprintf("::%s file=%s,line=%s::%s", level, escapeProperty(loc.File), loc.Line, msg))
 }
 
@@ -134,8 +154,9 @@ func (g *GitHub) writeLocs(level githubLevel, fileLoc, ogLoc *Location, msg stri
 	// If different files, produce messages for both locations.
 	// Always produce a relattive path as some inputs may be a long absolute path.
 	og := &Location{
-		File: relPath(ogLoc.File),
-		Line: ogLoc.Line,
+		File:   relPath(ogLoc.File),
+		Line:   ogLoc.Line,
+		Column: ogLoc.Column,
 	}
 	g.writeLoc(level, og, msg, args...)
 	g.writeLoc(level, fileLoc, fmt.Sprintf("(ORIGINATING FROM %s) %s", og, msg), args...)
@@ -52,6 +52,26 @@ func TestGitHub(t *testing.T) {
 				"",
 			},
 		},
+		{
+			name: "annotates results at the level of their severity",
+			input: CheckResults{
+				{
+					FileName:  "examples/kubernetes/service.yaml",
+					Namespace: "namespace",
+					Warnings:  []Result{{Message: "critical warning", Severity: SeverityCritical}},
+					Failures:  []Result{{Message: "low failure", Severity: SeverityLow}},
+				},
+			},
+			expected: []string{
+				"::group::Testing \"examples/kubernetes/service.yaml\" against 2 policies in namespace \"namespace\"",
+				"::notice file=examples/kubernetes/service.yaml,line=1::low failure",
+				"::error file=examples/kubernetes/service.yaml,line=1::critical warning",
+				"::notice file=examples/kubernetes/service.yaml,line=1::Number of successful checks: 0",
+				"::endgroup::",
+				"2 tests, 0 passed, 1 warning, 1 failure, 0 exceptions",
+				"",
+			},
+		},
 		{
 			name: "mixed failure, warnings, successes and skipped",
 			input: CheckResults{
@@ -35,7 +35,7 @@ func (j *JUnit) Output(results CheckResults) error {
 		ns := result.Namespace
 		for _, warning := range result.Warnings {
 			warningTest := parser.Test{
-				Name:   j.formatTestName(result.FileName, warning.Message),
+				Name:   j.formatTestName(result.FileName, warning),
 				Result: parser.FAIL,
 				Output: strings.Split(warning.Message, "\n"),
 			}
@@ -45,7 +45,7 @@ func (j *JUnit) Output(results CheckResults) error {
 
 		for _, failure := range result.Failures {
 			failingTest := parser.Test{
-				Name:   j.formatTestName(result.FileName, failure.Message),
+				Name:   j.formatTestName(result.FileName, failure),
 				Result: parser.FAIL,
 				Output: strings.Split(failure.Message, "\n"),
 			}
@@ -55,7 +55,7 @@ func (j *JUnit) Output(results CheckResults) error {
 
 		for _, skipped := range result.Skipped {
 			skippedTest := parser.Test{
-				Name:   j.formatTestName(result.FileName, skipped.Message),
+				Name:   j.formatTestName(result.FileName, skipped),
 				Result: parser.SKIP,
 				Output: strings.Split(skipped.Message, "\n"),
 			}
@@ -65,7 +65,7 @@ func (j *JUnit) Output(results CheckResults) error {
 
 		for s := 0; s < result.Successes; s++ {
 			successfulTest := parser.Test{
-				Name:   j.formatTestName(result.FileName, ""),
+				Name:   j.formatTestName(result.FileName, Result{}),
 				Result: parser.PASS,
 			}
 
@@ -88,12 +88,19 @@ func (j *JUnit) Output(results CheckResults) error {
 	return nil
 }
 
-func (j JUnit) formatTestName(fileName, message string) string {
-	if j.hideMessage || message == "" {
-		return fileName
+// formatTestName names the test of a result after the file, the rule that
+// produced the result when it is known, and the first line of the message.
+func (j JUnit) formatTestName(fileName string, result Result) string {
+	name := fileName
+	if result.Rule != nil {
+		name = fmt.Sprintf("%s - %s", name, result.Rule)
 	}
-	summary := strings.Split(message, "\n")[0]
-	return fmt.Sprintf("%s - %s", fileName, summary)
+
+	if j.hideMessage || result.Message == "" {
+		return name
+	}
+	summary := strings.Split(result.Message, "\n")[0]
+	return fmt.Sprintf("%s - %s", name, summary)
 }
 
 func (j *JUnit) Report(_ []*tester.Result, _ string) error {
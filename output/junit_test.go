@@ -61,6 +61,37 @@ func TestJUnit(t *testing.T) {
 				``,
 			},
 		},
+		{
+			name: "Failure with a rule",
+			input: CheckResults{
+				{
+					FileName:  "examples/kubernetes/service.yaml",
+					Namespace: "namespace",
+					Failures: []Result{
+						{Message: "first failure", Rule: &Rule{Package: "main", Name: "deny"}},
+						{Message: "second failure", Rule: &Rule{Package: "main", Name: "deny", ID: "K8S-001"}},
+					},
+				},
+			},
+			hideMessage: true,
+			expected: []string{
+				`<?xml version="1.0" encoding="UTF-8"?>`,
+				`<testsuites>`,
+				`	<testsuite tests="2" failures="2" time="0.000" name="conftest.namespace">`,
+				`		<properties>`,
+				`			<property name="go.version" value="%s"></property>`,
+				`		</properties>`,
+				`		<testcase classname="conftest.namespace" name="examples/kubernetes/service.yaml - main.deny" time="0.000">`,
+				`			<failure message="Failed" type="">first failure</failure>`,
+				`		</testcase>`,
+				`		<testcase classname="conftest.namespace" name="examples/kubernetes/service.yaml - K8S-001" time="0.000">`,
+				`			<failure message="Failed" type="">second failure</failure>`,
+				`		</testcase>`,
+				`	</testsuite>`,
+				`</testsuites>`,
+				``,
+			},
+		},
 		{
 			name: "Failure with a long description",
 			input: CheckResults{
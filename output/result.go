@@ -10,24 +10,103 @@ import (
 type Result struct {
 	Message  string         `json:"msg"`
 	Location *Location      `json:"loc,omitempty"`
+	Object   *Object        `json:"object,omitempty"`
+	Rule     *Rule          `json:"rule,omitempty"`
+	Severity string         `json:"severity,omitempty"`
 	Metadata map[string]any `json:"metadata,omitempty"`
 	Outputs  []string       `json:"outputs,omitempty"`
+	Fix      *Fix           `json:"fix,omitempty"`
+}
+
+// The types of the patches of fixes.
+const (
+	// FixJSONPatch is a JSON Patch (RFC 6902), a list of operations.
+	FixJSONPatch = "json-patch"
+
+	// FixMergePatch is a JSON merge patch (RFC 7396), an object that is
+	// merged into the value.
+	FixMergePatch = "merge-patch"
+)
+
+// Fix is a patch that fixes the input that produced a result, as applied by
+// the fix command.
+type Fix struct {
+	Type  string `json:"type"`
+	Patch any    `json:"patch"`
+
+	// Document is the index of the document of the file the patch applies to,
+	// and Path the JSON pointer of the value within the document, when the
+	// items of a document were checked one by one.
+	Document int    `json:"document"`
+	Path     string `json:"path,omitempty"`
+}
+
+// Rule describes the policy rule that produced a result.
+type Rule struct {
+	// Package and Name identify the rule, for example "main" and "deny".
+	Package string `json:"package"`
+	Name    string `json:"name"`
+
+	// File and Row are where the body of the rule that produced the
+	// result is declared. A rule can have many bodies.
+	File string `json:"file,omitempty"`
+	Row  int    `json:"row,omitempty"`
+
+	// The remaining fields are read from the METADATA annotations of the
+	// rule. ID and Severity are custom annotations.
+	ID          string `json:"id,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Severity    string `json:"severity,omitempty"`
+}
+
+// String returns the ID of the rule when it has one, and its package
+// and name otherwise.
+func (r Rule) String() string {
+	if r.ID != "" {
+		return r.ID
+	}
+
+	return r.Package + "." + r.Name
 }
 
 // Location describes the origin location in the configuration file that
 // caused the result to be produced.
 type Location struct {
-	File string      `json:"file,omitempty"`
-	Line json.Number `json:"line,omitempty"`
+	File   string      `json:"file,omitempty"`
+	Line   json.Number `json:"line,omitempty"`
+	Column json.Number `json:"column,omitempty"`
 }
 
 func (l Location) String() string {
 	return fmt.Sprintf("%s L%s", l.File, l.Line.String())
 }
 
+// Object identifies the Kubernetes object described by the document that
+// produced the result, when the document was parsed as a Kubernetes manifest.
+type Object struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+// String returns the kind of the object followed by its name, prefixed by its
+// namespace when it has one, e.g. Deployment default/web.
+func (o Object) String() string {
+	name := o.Name
+	if o.Namespace != "" {
+		name = o.Namespace + "/" + name
+	}
+
+	return o.Kind + " " + name
+}
+
 const (
-	msgField = "msg"
-	locField = "_loc"
+	msgField      = "msg"
+	locField      = "_loc"
+	severityField = "severity"
+	fixField      = "fix"
 )
 
 var reservedFields = []string{
@@ -57,10 +136,27 @@ func NewResult(metadata map[string]any) (Result, error) {
 		}
 	}
 
+	// Policies written before severities were supported may use the field for
+	// other values, which are left in the metadata without a severity.
+	if name, ok := lookup[string](metadata, severityField); ok {
+		if severity, err := ParseSeverity(name); err == nil {
+			result.Severity = severity
+		}
+	}
+
+	// Likewise, policies written before fixes were supported may use the field
+	// for free-form text, which is left in the metadata without a fix.
+	if patch, ok := metadata[fixField]; ok {
+		if fix, err := parseFix(patch); err == nil {
+			result.Fix = fix
+		}
+	}
+
 	for k, v := range metadata {
-		if !slices.Contains(reservedFields, k) {
-			result.Metadata[k] = v
+		if slices.Contains(reservedFields, k) || (k == fixField && result.Fix != nil) {
+			continue
 		}
+		result.Metadata[k] = v
 	}
 
 	return result, nil
@@ -79,6 +175,9 @@ func parseLocation(location any) *Location {
 	if line, ok := lookup[json.Number](loc, "line"); ok {
 		l.Line = line
 	}
+	if column, ok := lookup[json.Number](loc, "column"); ok {
+		l.Column = column
+	}
 
 	if l.File == "" && l.Line.String() == "" {
 		return nil
@@ -87,6 +186,31 @@ func parseLocation(location any) *Location {
 	return l
 }
 
+// parseFix returns the fix of a patch, which is a JSON Patch when it is a list
+// of operations and a merge patch when it is an object.
+func parseFix(patch any) (*Fix, error) {
+	switch patch := patch.(type) {
+	case []any:
+		for _, operation := range patch {
+			operation, ok := operation.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("JSON Patch operations must be objects")
+			}
+			if _, ok := lookup[string](operation, "op"); !ok {
+				return nil, fmt.Errorf("JSON Patch operations must have an op")
+			}
+			if _, ok := lookup[string](operation, "path"); !ok {
+				return nil, fmt.Errorf("JSON Patch operations must have a path")
+			}
+		}
+		return &Fix{Type: FixJSONPatch, Patch: patch}, nil
+	case map[string]any:
+		return &Fix{Type: FixMergePatch, Patch: patch}, nil
+	default:
+		return nil, fmt.Errorf("must be a list of JSON Patch operations or a merge patch object")
+	}
+}
+
 func lookup[T any](m map[string]any, k string) (value T, ok bool) {
 	x, ok := m[k]
 	if !ok {
@@ -146,6 +270,10 @@ type CheckResult struct {
 	Failures   []Result      `json:"failures,omitempty"`
 	Exceptions []Result      `json:"exceptions,omitempty"`
 	Queries    []QueryResult `json:"queries,omitempty"`
+
+	// ExpiredExceptions are the exceptions that applied to the input, but are
+	// no longer honored as they have expired.
+	ExpiredExceptions []Result `json:"expired_exceptions,omitempty"`
 }
 
 // HasFailure returns true if any failures were encountered.
@@ -200,6 +328,29 @@ func (cr CheckResults) ExitCode() int {
 	return 0
 }
 
+// ExitCodeFailOn returns the exit code that should be returned given all of the
+// returned results, and will only consider failures and warnings that have at
+// least the given severity. Failures and warnings that do not declare a severity
+// are considered to be of high and medium severity respectively.
+func (cr CheckResults) ExitCodeFailOn(severity string) int {
+	threshold := severityRank(severity)
+	for _, checkResult := range cr {
+		for _, failure := range checkResult.Failures {
+			if severityRank(severityOrDefault(failure, defaultFailureSeverity)) >= threshold {
+				return 1
+			}
+		}
+
+		for _, warning := range checkResult.Warnings {
+			if severityRank(severityOrDefault(warning, defaultWarningSeverity)) >= threshold {
+				return 1
+			}
+		}
+	}
+
+	return 0
+}
+
 // ExitCodeFailOnWarn returns the exit code that should be returned
 // given all of the returned results, and will consider warnings
 // as failures.
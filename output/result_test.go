@@ -58,6 +58,69 @@ func TestNewResult(t *testing.T) {
 				Metadata: map[string]any{"other": "metadata"},
 			},
 		},
+		{
+			desc:  "msg with severity",
+			input: map[string]any{"msg": "message", "severity": "HIGH"},
+			want: Result{
+				Message:  "message",
+				Severity: SeverityHigh,
+				Metadata: map[string]any{"severity": "HIGH"},
+			},
+		},
+		{
+			desc:  "unknown severity is kept as metadata",
+			input: map[string]any{"msg": "message", "severity": "P1"},
+			want: Result{
+				Message:  "message",
+				Metadata: map[string]any{"severity": "P1"},
+			},
+		},
+		{
+			desc: "list fix is a JSON Patch",
+			input: map[string]any{
+				"msg": "message",
+				"fix": []any{map[string]any{"op": "remove", "path": "/a"}},
+			},
+			want: Result{
+				Message:  "message",
+				Metadata: make(map[string]any),
+				Fix: &Fix{
+					Type:  FixJSONPatch,
+					Patch: []any{map[string]any{"op": "remove", "path": "/a"}},
+				},
+			},
+		},
+		{
+			desc: "object fix is a merge patch",
+			input: map[string]any{
+				"msg": "message",
+				"fix": map[string]any{"a": nil},
+			},
+			want: Result{
+				Message:  "message",
+				Metadata: make(map[string]any),
+				Fix: &Fix{
+					Type:  FixMergePatch,
+					Patch: map[string]any{"a": nil},
+				},
+			},
+		},
+		{
+			desc:  "fix without operations is kept as metadata",
+			input: map[string]any{"msg": "message", "fix": []any{map[string]any{"path": "/a"}}},
+			want: Result{
+				Message:  "message",
+				Metadata: map[string]any{"fix": []any{map[string]any{"path": "/a"}}},
+			},
+		},
+		{
+			desc:  "string fix is kept as metadata",
+			input: map[string]any{"msg": "message", "fix": "remove it"},
+			want: Result{
+				Message:  "message",
+				Metadata: map[string]any{"fix": "remove it"},
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -234,3 +297,75 @@ func TestExitCodeFailOnWarn(t *testing.T) {
 		})
 	}
 }
+
+func TestExitCodeFailOn(t *testing.T) {
+	t.Parallel()
+
+	warning := CheckResult{
+		Warnings: []Result{{}},
+	}
+
+	failure := CheckResult{
+		Failures: []Result{{}},
+	}
+
+	lowFailure := CheckResult{
+		Failures: []Result{{Severity: SeverityLow}},
+	}
+
+	criticalWarning := CheckResult{
+		Warnings: []Result{{Severity: SeverityCritical}},
+	}
+
+	testCases := []struct {
+		results  CheckResults
+		severity string
+		expected int
+	}{
+		{results: CheckResults{}, severity: SeverityInfo, expected: 0},
+		{results: CheckResults{warning}, severity: SeverityMedium, expected: 1},
+		{results: CheckResults{warning}, severity: SeverityHigh, expected: 0},
+		{results: CheckResults{failure}, severity: SeverityHigh, expected: 1},
+		{results: CheckResults{failure}, severity: SeverityCritical, expected: 0},
+		{results: CheckResults{lowFailure}, severity: SeverityMedium, expected: 0},
+		{results: CheckResults{lowFailure}, severity: SeverityLow, expected: 1},
+		{results: CheckResults{criticalWarning}, severity: SeverityCritical, expected: 1},
+	}
+
+	for i, testCase := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Parallel()
+
+			actual := testCase.results.ExitCodeFailOn(testCase.severity)
+			if actual != testCase.expected {
+				t.Errorf("Unexpected error code. expected %v, actual %v", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "critical", want: SeverityCritical},
+		{name: "Medium", want: SeverityMedium},
+		{name: "INFO", want: SeverityInfo},
+		{name: "error", wantErr: true},
+		{name: "", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		got, err := ParseSeverity(tc.name)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("ParseSeverity(%q) error = %v, want %v", tc.name, err, tc.wantErr)
+		}
+		if got != tc.want {
+			t.Errorf("ParseSeverity(%q) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package output
 import (
 	"fmt"
 	"io"
+	"maps"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -24,6 +25,7 @@ const (
 	failureDesc   = "Policy violation"
 	warningDesc   = "Policy warning"
 	exceptionDesc = "Policy exception"
+	expiredDesc   = "Expired policy exception"
 
 	// Exit code descriptions
 	exitNoViolations = "No policy violations found"
@@ -48,45 +50,105 @@ func getRuleID(namespace string, ruleType string) string {
 	return fmt.Sprintf("%s/%s", namespace, ruleType)
 }
 
-// getRuleDescription returns the appropriate description based on the rule type
-func getRuleDescription(ruleID string) string {
+// reportsRule returns whether results of the given rule type are reported under
+// the rule that produced them. Exceptions keep the rule of the failure they
+// apply to, so they are reported under a rule of their own instead.
+func reportsRule(ruleType string, result Result) bool {
+	return result.Rule != nil && (ruleType == "deny" || ruleType == "warn")
+}
+
+// getResultRuleID returns the ID of the rule that produced the result. Rules with
+// an id annotation are identified by it, other rules by their package and name.
+// When the rule is unknown, or the result is not a failure or warning, the ID
+// is based on the namespace and rule type.
+func getResultRuleID(namespace string, ruleType string, result Result) string {
 	switch {
-	case strings.HasSuffix(ruleID, "/success"):
+	case !reportsRule(ruleType, result):
+		return getRuleID(namespace, ruleType)
+	case result.Rule.ID != "":
+		return result.Rule.ID
+	default:
+		return getRuleID(result.Rule.Package, result.Rule.Name)
+	}
+}
+
+// getRuleDescription returns the appropriate description based on the rule type
+func getRuleDescription(ruleType string) string {
+	switch ruleType {
+	case "success":
 		return successDesc
-	case strings.HasSuffix(ruleID, "/skip"):
+	case "skip":
 		return skippedDesc
-	case strings.HasSuffix(ruleID, "/allow"):
+	case "allow":
 		return exceptionDesc
-	case strings.HasSuffix(ruleID, "/warn"):
+	case "expired":
+		return expiredDesc
+	case "warn":
 		return warningDesc
 	default:
 		return failureDesc
 	}
 }
 
+// getLevel returns the SARIF level of a result. Results that declare a severity
+// are reported at the level matching it, others at the given default level.
+func getLevel(result Result, defaultLevel string) string {
+	switch result.Severity {
+	case SeverityCritical, SeverityHigh:
+		return "error"
+	case SeverityMedium:
+		return "warning"
+	case SeverityLow, SeverityInfo:
+		return "note"
+	default:
+		return defaultLevel
+	}
+}
+
 // addRuleIndex adds a new rule to the SARIF run and returns its index.
-func addRuleIndex(run *sarif.Run, ruleID string, result Result, indices map[string]int) int {
-	addRule(run, ruleID, result)
+func addRuleIndex(run *sarif.Run, ruleID string, ruleType string, result Result, indices map[string]int) int {
+	addRule(run, ruleID, ruleType, result)
 	idx := len(run.Tool.Driver.Rules) - 1
 	indices[ruleID] = idx
 	return idx
 }
 
 // addRule adds a new rule to the SARIF run with the given ID and result metadata.
-func addRule(run *sarif.Run, ruleID string, result Result) {
-	desc := getRuleDescription(ruleID)
-	run.AddRule(ruleID).
-		WithDescription(desc).
-		WithProperties(result.Metadata).
+// The title and description annotations of the policy rule, when present, take
+// the place of the generic descriptions.
+func addRule(run *sarif.Run, ruleID string, ruleType string, result Result) {
+	desc := getRuleDescription(ruleType)
+	properties := result.Metadata
+	rule := run.AddRule(ruleID)
+
+	if r := result.Rule; reportsRule(ruleType, result) {
+		if r.Title != "" {
+			desc = r.Title
+			rule.WithName(r.Title)
+		}
+		if r.Description != "" {
+			rule.WithFullDescription(sarif.NewMultiformatMessageString(r.Description))
+		}
+		if r.Severity != "" {
+			properties = maps.Clone(properties)
+			if properties == nil {
+				properties = make(map[string]any)
+			}
+			properties["severity"] = r.Severity
+		}
+	}
+
+	rule.WithDescription(desc).
+		WithProperties(properties).
 		WithShortDescription(sarif.NewMultiformatMessageString(desc))
 }
 
 // addResult adds a result to the SARIF run
 func addResult(run *sarif.Run, result Result, namespace, ruleType, level, fileName string, indices map[string]int) {
-	ruleID := getRuleID(namespace, ruleType)
+	ruleID := getResultRuleID(namespace, ruleType, result)
 	idx, ok := indices[ruleID]
 	if !ok {
-		idx = addRuleIndex(run, ruleID, result, indices)
+		idx = addRuleIndex(run, ruleID, ruleType, result, indices)
 	}
 
 	location := sarif.NewPhysicalLocation()
@@ -94,15 +156,37 @@ func addResult(run *sarif.Run, result Result, namespace, ruleType, level, fileNa
 		line, _ := strconv.Atoi(loc.Line.String())
 		location.ArtifactLocation = sarif.NewSimpleArtifactLocation(filepath.ToSlash(loc.File))
 		location.Region = sarif.NewRegion().WithStartLine(line).WithEndLine(line)
+		if column, err := strconv.Atoi(loc.Column.String()); err == nil {
+			location.Region.WithStartColumn(column)
+		}
 	} else {
 		location.ArtifactLocation = sarif.NewSimpleArtifactLocation(filepath.ToSlash(fileName))
 	}
 
-	run.CreateResultForRule(ruleID).
+	sarifResult := run.CreateResultForRule(ruleID).
 		WithRuleIndex(idx).
 		WithLevel(level).
-		WithMessage(sarif.NewTextMessage(result.Message)).
-		AddLocation(sarif.NewLocationWithPhysicalLocation(location))
+		WithMessage(sarif.NewTextMessage(result.Message))
+	resultLocation := sarif.NewLocationWithPhysicalLocation(location)
+	if object := result.Object; object != nil {
+		resultLocation.WithLogicalLocations([]*sarif.LogicalLocation{
+			sarif.NewLogicalLocation().
+				WithName(object.Name).
+				WithFullyQualifiedName(object.String()).
+				WithKind(object.Kind),
+		})
+	}
+	sarifResult.AddLocation(resultLocation)
+
+	// A rule can have many bodies, so the body that produced the result is
+	// referenced as a related location.
+	if r := result.Rule; r != nil && r.File != "" {
+		ruleLocation := sarif.NewPhysicalLocation().
+			WithArtifactLocation(sarif.NewSimpleArtifactLocation(filepath.ToSlash(r.File))).
+			WithRegion(sarif.NewRegion().WithStartLine(r.Row))
+		sarifResult.AddRelatedLocation(sarif.NewLocationWithPhysicalLocation(ruleLocation).
+			WithDescriptionText(fmt.Sprintf("Rule %s.%s", r.Package, r.Name)))
+	}
 }
 
 // Output outputs the results in SARIF format.
@@ -121,12 +205,12 @@ func (s *SARIF) Output(results CheckResults) error {
 	for _, result := range results {
 		// Process failures
 		for _, failure := range result.Failures {
-			addResult(run, failure, result.Namespace, "deny", "error", result.FileName, indices)
+			addResult(run, failure, result.Namespace, "deny", getLevel(failure, "error"), result.FileName, indices)
 		}
 
 		// Process warnings
 		for _, warning := range result.Warnings {
-			addResult(run, warning, result.Namespace, "warn", "warning", result.FileName, indices)
+			addResult(run, warning, result.Namespace, "warn", getLevel(warning, "warning"), result.FileName, indices)
 		}
 
 		// Process exceptions (treated as successes)
@@ -136,6 +220,11 @@ func (s *SARIF) Output(results CheckResults) error {
 			hasSuccesses = true
 		}
 
+		// Process expired exceptions, which no longer suppress any results
+		for _, exception := range result.ExpiredExceptions {
+			addResult(run, exception, result.Namespace, "expired", "note", result.FileName, indices)
+		}
+
 		// Don't add success/skip results if there are failures or warnings
 		hasErrors := result.HasFailure() || result.HasWarning()
 		if hasErrors {
@@ -115,6 +115,101 @@ func TestSARIF_Output(t *testing.T) {
 				},
 			}),
 		},
+		{
+			name: "failure with rule identity",
+			results: []CheckResult{
+				{
+					FileName:  "test.yaml",
+					Namespace: "main",
+					Failures: []Result{
+						{
+							Message: "too few replicas",
+							Rule: &Rule{
+								Package:     "main",
+								Name:        "deny",
+								File:        "policy/replicas.rego",
+								Row:         9,
+								ID:          "K8S-001",
+								Title:       "Replicas",
+								Description: "Deployments need at least two replicas",
+								Severity:    "high",
+							},
+						},
+					},
+				},
+			},
+			wantJSON: mustJSON(t, map[string]any{
+				"version": "2.1.0",
+				"$schema": "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/main/sarif-2.1/schema/sarif-schema-2.1.0.json",
+				"runs": []map[string]any{
+					{
+						"tool": map[string]any{
+							"driver": map[string]any{
+								"informationUri": toolURI,
+								"name":           toolName,
+								"version":        version.Version,
+								"rules": []map[string]any{
+									{
+										"id":   "K8S-001",
+										"name": "Replicas",
+										"shortDescription": map[string]any{
+											"text": "Replicas",
+										},
+										"fullDescription": map[string]any{
+											"text": "Deployments need at least two replicas",
+										},
+										"properties": map[string]any{
+											"severity": "high",
+										},
+									},
+								},
+							},
+						},
+						"invocations": []map[string]any{
+							{
+								"executionSuccessful": true,
+								"exitCode":            1,
+								"exitCodeDescription": "Policy violations found",
+							},
+						},
+						"results": []map[string]any{
+							{
+								"ruleId":    "K8S-001",
+								"ruleIndex": 0,
+								"level":     "error",
+								"message": map[string]any{
+									"text": "too few replicas",
+								},
+								"locations": []map[string]any{
+									{
+										"physicalLocation": map[string]any{
+											"artifactLocation": map[string]any{
+												"uri": "test.yaml",
+											},
+										},
+									},
+								},
+								"relatedLocations": []map[string]any{
+									{
+										"physicalLocation": map[string]any{
+											"artifactLocation": map[string]any{
+												"uri": "policy/replicas.rego",
+											},
+											"region": map[string]any{
+												"startLine": 9,
+											},
+										},
+										"message": map[string]any{
+											"text": "Rule main.deny",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}),
+		},
 		{
 			name: "single warning",
 			results: []CheckResult{
@@ -287,6 +382,104 @@ func TestSARIF_Output(t *testing.T) {
 				},
 			}),
 		},
+		{
+			name: "expired exception",
+			results: []CheckResult{
+				{
+					FileName:  "test.yaml",
+					Namespace: "main",
+					Successes: 1,
+					ExpiredExceptions: []Result{
+						{
+							Message: `exception for rules ["run_as_root"] owned by team-a expired on 2025-01-01`,
+							Metadata: map[string]any{
+								"owner":   "team-a",
+								"expires": "2025-01-01",
+							},
+						},
+					},
+				},
+			},
+			wantJSON: mustJSON(t, map[string]any{
+				"version": "2.1.0",
+				"$schema": "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/main/sarif-2.1/schema/sarif-schema-2.1.0.json",
+				"runs": []map[string]any{
+					{
+						"tool": map[string]any{
+							"driver": map[string]any{
+								"informationUri": toolURI,
+								"name":           toolName,
+								"version":        version.Version,
+								"rules": []map[string]any{
+									{
+										"id": "main/expired",
+										"shortDescription": map[string]any{
+											"text": "Expired policy exception",
+										},
+										"properties": map[string]any{
+											"owner":   "team-a",
+											"expires": "2025-01-01",
+										},
+									},
+									{
+										"id": "main/success",
+										"shortDescription": map[string]any{
+											"text": "Policy was satisfied successfully",
+										},
+										"properties": map[string]any{
+											"description": "Policy was satisfied successfully",
+										},
+									},
+								},
+							},
+						},
+						"invocations": []map[string]any{
+							{
+								"executionSuccessful": true,
+								"exitCode":            0,
+								"exitCodeDescription": "No policy violations found",
+							},
+						},
+						"results": []map[string]any{
+							{
+								"ruleId":    "main/expired",
+								"ruleIndex": 0,
+								"level":     "note",
+								"message": map[string]any{
+									"text": `exception for rules ["run_as_root"] owned by team-a expired on 2025-01-01`,
+								},
+								"locations": []map[string]any{
+									{
+										"physicalLocation": map[string]any{
+											"artifactLocation": map[string]any{
+												"uri": "test.yaml",
+											},
+										},
+									},
+								},
+							},
+							{
+								"ruleId":    "main/success",
+								"ruleIndex": 1,
+								"level":     "none",
+								"message": map[string]any{
+									"text": "Policy was satisfied successfully",
+								},
+								"locations": []map[string]any{
+									{
+										"physicalLocation": map[string]any{
+											"artifactLocation": map[string]any{
+												"uri": "test.yaml",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}),
+		},
 		{
 			name: "skipped result",
 			results: []CheckResult{
@@ -574,6 +767,78 @@ func TestGetRuleID(t *testing.T) {
 	}
 }
 
+func TestGetResultRuleID(t *testing.T) {
+	rule := &Rule{Package: "main", Name: "deny"}
+
+	tests := []struct {
+		name     string
+		ruleType string
+		result   Result
+		want     string
+	}{
+		{
+			name:     "failure with rule",
+			ruleType: "deny",
+			result:   Result{Rule: rule},
+			want:     "main/deny",
+		},
+		{
+			name:     "failure with rule id",
+			ruleType: "deny",
+			result:   Result{Rule: &Rule{Package: "main", Name: "deny", ID: "K8S-001"}},
+			want:     "K8S-001",
+		},
+		{
+			name:     "failure without rule",
+			ruleType: "deny",
+			result:   Result{},
+			want:     "policies/deny",
+		},
+		{
+			name:     "exception with the rule of the failure",
+			ruleType: "allow",
+			result:   Result{Rule: rule},
+			want:     "policies/allow",
+		},
+		{
+			name:     "expired exception with the rule of the failure",
+			ruleType: "expired",
+			result:   Result{Rule: rule},
+			want:     "policies/expired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getResultRuleID("policies", tt.ruleType, tt.result); got != tt.want {
+				t.Errorf("getResultRuleID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetLevel(t *testing.T) {
+	tests := []struct {
+		severity     string
+		defaultLevel string
+		want         string
+	}{
+		{severity: "", defaultLevel: "error", want: "error"},
+		{severity: "", defaultLevel: "warning", want: "warning"},
+		{severity: SeverityCritical, defaultLevel: "warning", want: "error"},
+		{severity: SeverityHigh, defaultLevel: "warning", want: "error"},
+		{severity: SeverityMedium, defaultLevel: "error", want: "warning"},
+		{severity: SeverityLow, defaultLevel: "error", want: "note"},
+		{severity: SeverityInfo, defaultLevel: "error", want: "note"},
+	}
+
+	for _, tt := range tests {
+		if got := getLevel(Result{Severity: tt.severity}, tt.defaultLevel); got != tt.want {
+			t.Errorf("getLevel(%q, %q) = %v, want %v", tt.severity, tt.defaultLevel, got, tt.want)
+		}
+	}
+}
+
 func TestSARIF_Report(t *testing.T) {
 	var buf bytes.Buffer
 	s := NewSARIF(&buf)
@@ -0,0 +1,59 @@
+package output
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// The severities that can be declared for a result, either through a severity
+// field on the result object or the severity annotation of the rule.
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+	SeverityInfo     = "info"
+)
+
+// The severities of results that do not declare one.
+const (
+	defaultFailureSeverity = SeverityHigh
+	defaultWarningSeverity = SeverityMedium
+)
+
+// Severities returns the available severities, from the least to the most severe.
+func Severities() []string {
+	return []string{
+		SeverityInfo,
+		SeverityLow,
+		SeverityMedium,
+		SeverityHigh,
+		SeverityCritical,
+	}
+}
+
+// ParseSeverity returns the severity with the given name, ignoring its case.
+func ParseSeverity(name string) (string, error) {
+	severity := strings.ToLower(name)
+	if !slices.Contains(Severities(), severity) {
+		return "", fmt.Errorf("unknown severity %q, valid severities are: %s", name, Severities())
+	}
+
+	return severity, nil
+}
+
+// severityRank orders severities from the least to the most severe.
+func severityRank(severity string) int {
+	return slices.Index(Severities(), severity)
+}
+
+// severityOrDefault returns the severity of the result, or the given default
+// when the result does not declare one.
+func severityOrDefault(result Result, defaultSeverity string) string {
+	if result.Severity != "" {
+		return result.Severity
+	}
+
+	return defaultSeverity
+}
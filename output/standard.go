@@ -79,16 +79,16 @@ func (s *Standard) Output(results CheckResults) error {
 		}
 
 		for _, warning := range result.Warnings {
-			fmt.Fprintln(s.Writer, colorizer.Colorize("WARN", aurora.YellowFg), indicator, namespace, warning.Message)
+			fmt.Fprintln(s.Writer, colorizer.Colorize("WARN", aurora.YellowFg), withObject(indicator, warning), namespace, warning.Message)
 		}
 
 		for _, failure := range result.Failures {
-			fmt.Fprintln(s.Writer, colorizer.Colorize("FAIL", aurora.RedFg), indicator, namespace, failure.Message)
+			fmt.Fprintln(s.Writer, colorizer.Colorize("FAIL", aurora.RedFg), withObject(indicator, failure), namespace, failure.Message)
 		}
 
 		if !s.SuppressExceptions {
 			for _, exception := range result.Exceptions {
-				fmt.Fprintln(s.Writer, colorizer.Colorize("EXCP", aurora.CyanFg), indicator, namespace, exception.Message)
+				fmt.Fprintln(s.Writer, colorizer.Colorize("EXCP", aurora.CyanFg), withObject(indicator, exception), namespace, exception.Message)
 			}
 		}
 
@@ -149,6 +149,16 @@ func (s *Standard) Output(results CheckResults) error {
 	return nil
 }
 
+// withObject appends the object the result was produced for to the indicator
+// of the file, so that the documents of a file can be told apart.
+func withObject(indicator string, result Result) string {
+	if result.Object == nil {
+		return indicator
+	}
+
+	return fmt.Sprintf("%s (%s)", indicator, result.Object)
+}
+
 func (s *Standard) outputPrints(results CheckResults, colorizer aurora.Aurora) {
 	for _, result := range results {
 		for _, query := range result.Queries {
@@ -32,6 +32,26 @@ func TestStandard(t *testing.T) {
 				"",
 			},
 		},
+		{
+			name: "identifies the object of the results",
+			input: CheckResults{
+				{
+					FileName:  "foo.yaml",
+					Namespace: "namespace",
+					Failures: []Result{
+						{Message: "first failure", Object: &Object{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "web", Name: "app"}},
+						{Message: "second failure", Object: &Object{APIVersion: "v1", Kind: "Namespace", Name: "web"}},
+					},
+				},
+			},
+			expected: []string{
+				"FAIL - foo.yaml (Deployment web/app) - namespace - first failure",
+				"FAIL - foo.yaml (Namespace web) - namespace - second failure",
+				"",
+				"2 tests, 0 passed, 0 warnings, 2 failures, 0 exceptions",
+				"",
+			},
+		},
 		{
 			name: "skips filenames for stdin",
 			input: CheckResults{
@@ -0,0 +1,250 @@
+// Package compose parses Docker Compose files into the effective configuration
+// of their services, as docker compose config would show it.
+package compose
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dotenv "github.com/open-policy-agent/conftest/parser/dotenv"
+	"github.com/open-policy-agent/conftest/parser/location"
+	"github.com/open-policy-agent/conftest/parser/suppression"
+	"github.com/open-policy-agent/conftest/parser/yaml"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// Parser is a Docker Compose file parser. The file is merged with its override
+// file, the services that extend other services are merged with them, the
+// variables are interpolated, and the short syntax of ports and volumes, and the
+// list syntax of environment variables and labels, are normalized to their long
+// syntax.
+type Parser struct {
+	path string
+	yaml yaml.Parser
+}
+
+// SetPath sets the path of the file being parsed, relative to which the
+// override file, the .env file and the files of extended services are found.
+func (p *Parser) SetPath(path string) {
+	p.path = path
+}
+
+// Unmarshal unmarshals Docker Compose files.
+func (p *Parser) Unmarshal(data []byte, v any) error {
+	dir := "."
+	if p.path != "" && p.path != "-" {
+		dir = filepath.Dir(p.path)
+	}
+
+	env, err := environment(dir)
+	if err != nil {
+		return err
+	}
+
+	project, err := load(data, env)
+	if err != nil {
+		return err
+	}
+
+	if override, ok := overridePath(p.path); ok {
+		contents, err := os.ReadFile(override)
+		if err != nil {
+			return fmt.Errorf("read override file: %w", err)
+		}
+
+		overrideProject, err := load(contents, env)
+		if err != nil {
+			return fmt.Errorf("load override file %s: %w", override, err)
+		}
+		project = merge(project, overrideProject, nil).(map[string]any)
+	}
+
+	if err := resolveExtends(project, p.path, env); err != nil {
+		return err
+	}
+
+	j, err := json.Marshal(project)
+	if err != nil {
+		return fmt.Errorf("marshal compose file to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal compose json: %w", err)
+	}
+
+	return nil
+}
+
+// Locate returns the position of the values in the Compose file, which are
+// those of the file itself rather than of its override file.
+func (p *Parser) Locate(data []byte) ([]location.Map, error) {
+	return p.yaml.Locate(data)
+}
+
+// Suppressions returns the inline suppression directives in the comments of the
+// Compose file.
+func (p *Parser) Suppressions(data []byte) ([][]suppression.Directive, error) {
+	return p.yaml.Suppressions(data)
+}
+
+// overridePath returns the path of the override file of the Compose file at the
+// path, when it exists, e.g. docker-compose.override.yml for docker-compose.yml.
+func overridePath(path string) (string, bool) {
+	if path == "" || path == "-" {
+		return "", false
+	}
+
+	ext := filepath.Ext(path)
+	name := strings.TrimSuffix(filepath.Base(path), ext)
+	if strings.HasSuffix(name, ".override") {
+		return "", false
+	}
+
+	override := filepath.Join(filepath.Dir(path), name+".override"+ext)
+	if info, err := os.Stat(override); err != nil || info.IsDir() {
+		return "", false
+	}
+
+	return override, true
+}
+
+// environment returns the variables the Compose files in the directory are
+// interpolated with: those of the .env file in the directory, overridden by
+// those of the environment.
+func environment(dir string) (map[string]string, error) {
+	env := make(map[string]string)
+
+	contents, err := os.ReadFile(filepath.Join(dir, ".env"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("read .env file: %w", err)
+	}
+	if err == nil {
+		var values map[string]any
+		if err := (&dotenv.Parser{}).Unmarshal(contents, &values); err != nil {
+			return nil, err
+		}
+		for name, value := range values {
+			env[name] = fmt.Sprint(value)
+		}
+	}
+
+	for _, variable := range os.Environ() {
+		if name, value, ok := strings.Cut(variable, "="); ok {
+			env[name] = value
+		}
+	}
+
+	return env, nil
+}
+
+// load unmarshals a Compose file, interpolating its variables and normalizing
+// its services.
+func load(data []byte, env map[string]string) (map[string]any, error) {
+	var config any
+	if err := sigsyaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("unmarshal yaml: %w", err)
+	}
+	if config == nil {
+		return map[string]any{}, nil
+	}
+
+	config, err := interpolate(config, env)
+	if err != nil {
+		return nil, fmt.Errorf("interpolate: %w", err)
+	}
+
+	project, ok := config.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("compose file must be a mapping, found %T", config)
+	}
+
+	services, _ := project["services"].(map[string]any)
+	for name, service := range services {
+		definition, ok := service.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		normalized, err := normalizeService(definition)
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %w", name, err)
+		}
+		services[name] = normalized
+	}
+
+	return project, nil
+}
+
+// resolveExtends merges every service that extends another service with the
+// service it extends, which is either in the same file or in another file,
+// relative to the file at the given path.
+func resolveExtends(project map[string]any, path string, env map[string]string) error {
+	services, _ := project["services"].(map[string]any)
+	for name := range services {
+		service, err := extendedService(services, name, path, env, nil)
+		if err != nil {
+			return err
+		}
+		services[name] = service
+	}
+
+	return nil
+}
+
+// extendedService returns the service with the given name in the file at the
+// given path, merged with the services it extends. The services being extended
+// are tracked by their file and name, to report services that extend
+// themselves.
+func extendedService(services map[string]any, name, path string, env map[string]string, extending []string) (map[string]any, error) {
+	service, ok := services[name].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("extended service %s not found", name)
+	}
+
+	extends, ok := service["extends"].(map[string]any)
+	if !ok {
+		return service, nil
+	}
+
+	key := filepath.Clean(path) + "#" + name
+	for _, extended := range extending {
+		if extended == key {
+			return nil, fmt.Errorf("service %s extends itself", name)
+		}
+	}
+	extending = append(extending, key)
+
+	baseName, _ := extends["service"].(string)
+	baseServices, basePath := services, path
+	if file, ok := extends["file"].(string); ok {
+		basePath = filepath.Join(filepath.Dir(path), file)
+		contents, err := os.ReadFile(basePath)
+		if err != nil {
+			return nil, fmt.Errorf("read extended file: %w", err)
+		}
+
+		baseProject, err := load(contents, env)
+		if err != nil {
+			return nil, fmt.Errorf("load extended file %s: %w", basePath, err)
+		}
+		baseServices, _ = baseProject["services"].(map[string]any)
+	}
+
+	base, err := extendedService(baseServices, baseName, basePath, env, extending)
+	if err != nil {
+		return nil, fmt.Errorf("service %s: %w", name, err)
+	}
+
+	extended := make(map[string]any, len(service))
+	for field, value := range service {
+		if field != "extends" {
+			extended[field] = value
+		}
+	}
+
+	return merge(base, extended, []string{"services", name}).(map[string]any), nil
+}
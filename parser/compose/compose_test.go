@@ -0,0 +1,185 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUnmarshal(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"docker-compose.yml": `services:
+  web:
+    extends:
+      file: common.yml
+      service: base
+    image: "web:${TAG:-latest}"
+    ports:
+      - "5000:5000"
+      - "127.0.0.1:9090-9091:8080-8081/udp"
+    volumes:
+      - ./data:/data:ro
+      - cache:/cache
+    environment:
+      - DEBUG=1
+      - TOKEN
+    command: ["serve", "--port", "5000"]
+  worker:
+    extends: web
+    command: ["work"]
+`,
+		"docker-compose.override.yml": `services:
+  web:
+    ports:
+      - target: 6000
+        published: 6000
+    volumes:
+      - ./override:/data
+    environment:
+      DEBUG: "0"
+`,
+		"common.yml": `services:
+  base:
+    restart: always
+    labels:
+      - team=${CONFTEST_COMPOSE_TEAM}
+`,
+		".env": "CONFTEST_COMPOSE_TEAM=platform\n",
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	p := &Parser{}
+	p.SetPath(filepath.Join(dir, "docker-compose.yml"))
+
+	var got map[string]any
+	if err := p.Unmarshal([]byte(files["docker-compose.yml"]), &got); err != nil {
+		t.Fatalf("Unmarshal: unexpected error %v", err)
+	}
+
+	web := map[string]any{
+		"image":   "web:latest",
+		"restart": "always",
+		"labels":  map[string]any{"team": "platform"},
+		"ports": []any{
+			map[string]any{"target": float64(5000), "published": "5000", "protocol": "tcp", "mode": "ingress"},
+			map[string]any{"target": float64(8080), "published": "9090", "host_ip": "127.0.0.1", "protocol": "udp", "mode": "ingress"},
+			map[string]any{"target": float64(8081), "published": "9091", "host_ip": "127.0.0.1", "protocol": "udp", "mode": "ingress"},
+			map[string]any{"target": float64(6000), "published": "6000", "protocol": "tcp", "mode": "ingress"},
+		},
+		"volumes": []any{
+			map[string]any{"type": "bind", "source": "./override", "target": "/data"},
+			map[string]any{"type": "volume", "source": "cache", "target": "/cache"},
+		},
+		"environment": map[string]any{"DEBUG": "0", "TOKEN": nil},
+		"command":     []any{"serve", "--port", "5000"},
+	}
+	worker := map[string]any{}
+	for key, value := range web {
+		worker[key] = value
+	}
+	worker["command"] = []any{"work"}
+
+	want := map[string]any{
+		"services": map[string]any{
+			"web":    web,
+			"worker": worker,
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected project (-want +got):\n%s", diff)
+	}
+}
+
+func TestUnmarshalExtendsItself(t *testing.T) {
+	p := &Parser{}
+
+	var got map[string]any
+	err := p.Unmarshal([]byte(`services:
+  a:
+    extends: b
+  b:
+    extends: a
+`), &got)
+	if err == nil {
+		t.Error("expected an error for services that extend each other")
+	}
+}
+
+func TestInterpolateString(t *testing.T) {
+	env := map[string]string{
+		"NAME":  "web",
+		"EMPTY": "",
+	}
+
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "$NAME-app", want: "web-app"},
+		{input: "${NAME}app", want: "webapp"},
+		{input: "$$NAME", want: "$NAME"},
+		{input: "${MISSING}", want: ""},
+		{input: "${MISSING:-default}", want: "default"},
+		{input: "${EMPTY:-default}", want: "default"},
+		{input: "${EMPTY-default}", want: ""},
+		{input: "${MISSING:-${NAME}-default}", want: "web-default"},
+		{input: "${NAME:+set}", want: "set"},
+		{input: "${EMPTY:+set}", want: ""},
+		{input: "${EMPTY+set}", want: "set"},
+		{input: "${NAME:?required}", want: "web"},
+		{input: "${EMPTY?required}", want: ""},
+		{input: "${EMPTY:?required}", wantErr: true},
+		{input: "${MISSING?required}", wantErr: true},
+		{input: "${NAME", wantErr: true},
+		{input: "${NAME:}", wantErr: true},
+		{input: "cost: 5$", want: "cost: 5$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := interpolateString(tt.input, env)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVolume(t *testing.T) {
+	tests := []struct {
+		input string
+		want  map[string]any
+	}{
+		{
+			input: "/var/lib/data",
+			want:  map[string]any{"type": "volume", "target": "/var/lib/data"},
+		},
+		{
+			input: "data:/var/lib/data:nocopy",
+			want:  map[string]any{"type": "volume", "source": "data", "target": "/var/lib/data", "volume": map[string]any{"nocopy": true}},
+		},
+		{
+			input: "~/config:/etc/config:ro,z",
+			want:  map[string]any{"type": "bind", "source": "~/config", "target": "/etc/config", "read_only": true, "bind": map[string]any{"selinux": "z"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, parseVolume(tt.input)); diff != "" {
+				t.Errorf("unexpected volume (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package compose
+
+import (
+	"fmt"
+	"strings"
+)
+
+// interpolate replaces the variables in every string value of the
+// configuration with their values in the environment.
+func interpolate(config any, env map[string]string) (any, error) {
+	switch config := config.(type) {
+	case map[string]any:
+		for key, value := range config {
+			interpolated, err := interpolate(value, env)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			config[key] = interpolated
+		}
+		return config, nil
+	case []any:
+		for i, value := range config {
+			interpolated, err := interpolate(value, env)
+			if err != nil {
+				return nil, err
+			}
+			config[i] = interpolated
+		}
+		return config, nil
+	case string:
+		return interpolateString(config, env)
+	default:
+		return config, nil
+	}
+}
+
+// interpolateString replaces the variables in the string with their values in
+// the environment. Variables are written as $NAME or ${NAME}, with the braced
+// form supporting the same modifiers as the shell:
+//
+//   - ${NAME:-default} and ${NAME-default} are replaced by the default when the
+//     variable is unset or empty, or only when it is unset, respectively.
+//   - ${NAME:?error} and ${NAME?error} are an error when the variable is unset
+//     or empty, or only when it is unset, respectively.
+//   - ${NAME:+replacement} and ${NAME+replacement} are replaced by the
+//     replacement when the variable is set and not empty, or only when it is
+//     set, respectively.
+//
+// Defaults and replacements can contain variables themselves, and $$ is a
+// literal $.
+func interpolateString(s string, env map[string]string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		switch next := s[i+1]; {
+		case next == '$':
+			b.WriteByte('$')
+			i++
+		case next == '{':
+			end := closingBrace(s, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("missing closing brace in %q", s)
+			}
+
+			value, err := substitute(s[i+2:end], env)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i = end
+		case isNameStart(next):
+			end := i + 1
+			for end < len(s) && isNameChar(s[end]) {
+				end++
+			}
+			b.WriteString(env[s[i+1:end]])
+			i = end - 1
+		default:
+			b.WriteByte('$')
+		}
+	}
+
+	return b.String(), nil
+}
+
+// substitute returns the value of the braced variable expression, without its
+// braces.
+func substitute(expression string, env map[string]string) (string, error) {
+	end := 0
+	for end < len(expression) && isNameChar(expression[end]) {
+		end++
+	}
+	name, modifier := expression[:end], expression[end:]
+	if name == "" || !isNameStart(name[0]) {
+		return "", fmt.Errorf("invalid variable name in ${%s}", expression)
+	}
+
+	value, set := env[name]
+	if modifier == "" {
+		return value, nil
+	}
+
+	unsetOnly := !strings.HasPrefix(modifier, ":")
+	operator := strings.TrimPrefix(modifier, ":")
+	if operator == "" {
+		return "", fmt.Errorf("invalid modifier in ${%s}", expression)
+	}
+	missing := !set || (!unsetOnly && value == "")
+
+	argument, err := interpolateString(operator[1:], env)
+	if err != nil {
+		return "", err
+	}
+
+	switch operator[0] {
+	case '-':
+		if missing {
+			return argument, nil
+		}
+		return value, nil
+	case '?':
+		if missing {
+			return "", fmt.Errorf("required variable %s is missing a value: %s", name, argument)
+		}
+		return value, nil
+	case '+':
+		if missing {
+			return "", nil
+		}
+		return argument, nil
+	default:
+		return "", fmt.Errorf("invalid modifier in ${%s}", expression)
+	}
+}
+
+// closingBrace returns the index of the brace that closes the braced variable
+// starting at the given index, or -1 when it is not closed.
+func closingBrace(s string, start int) int {
+	depth := 1
+	for i := start; i < len(s); i++ {
+		switch {
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		case s[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
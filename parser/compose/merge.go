@@ -0,0 +1,135 @@
+package compose
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// merge merges the override value into the base value at the given path of the
+// project, following the rules Compose merges override files and extended
+// services with: mappings are merged, sequences are merged by the key of their
+// items or appended to, and any other value is replaced.
+func merge(base, override any, path []string) any {
+	switch override := override.(type) {
+	case map[string]any:
+		base, ok := base.(map[string]any)
+		if !ok {
+			return override
+		}
+
+		merged := make(map[string]any, len(base)+len(override))
+		for key, value := range base {
+			merged[key] = value
+		}
+		for key, value := range override {
+			if existing, ok := merged[key]; ok {
+				merged[key] = merge(existing, value, append(slices.Clone(path), key))
+				continue
+			}
+			merged[key] = value
+		}
+
+		return merged
+	case []any:
+		base, ok := base.([]any)
+		if !ok || replacesSequence(path) {
+			return override
+		}
+
+		return mergeSequence(base, override, sequenceKey(path))
+	default:
+		return override
+	}
+}
+
+// replacesSequence returns true when the sequence at the path replaces the
+// sequence it overrides rather than being merged with it.
+func replacesSequence(path []string) bool {
+	if field, ok := serviceField(path); ok {
+		return slices.Contains([]string{"command", "entrypoint", "test"}, field)
+	}
+
+	return len(path) == 4 && path[0] == "services" && path[2] == "healthcheck" && path[3] == "test"
+}
+
+// sequenceKey returns the function that returns the key that identifies the
+// items of the sequence at the path, which replace the items of the sequence
+// they override with the same key, or nil when the items are appended.
+func sequenceKey(path []string) func(any) (string, bool) {
+	field, _ := serviceField(path)
+	switch field {
+	case "volumes":
+		return fieldKey("target")
+	case "secrets", "configs":
+		return func(item any) (string, bool) {
+			if source, ok := item.(string); ok {
+				return source, true
+			}
+			return fieldKey("source")(item)
+		}
+	case "ports":
+		return func(item any) (string, bool) {
+			port, ok := item.(map[string]any)
+			if !ok {
+				return "", false
+			}
+			return fmt.Sprint(port["host_ip"], port["published"], port["target"], port["protocol"]), true
+		}
+	default:
+		return nil
+	}
+}
+
+// fieldKey returns the function that returns the value of the given field of
+// the items of a sequence as their key.
+func fieldKey(field string) func(any) (string, bool) {
+	return func(item any) (string, bool) {
+		mapping, ok := item.(map[string]any)
+		if !ok {
+			return "", false
+		}
+
+		value, ok := mapping[field]
+		return fmt.Sprint(value), ok
+	}
+}
+
+// mergeSequence merges the override items into the base items. Items with the
+// same key are replaced, while other items are appended unless they are equal
+// to an item of the base sequence.
+func mergeSequence(base, override []any, key func(any) (string, bool)) []any {
+	merged := slices.Clone(base)
+	for _, item := range override {
+		replaced := false
+		if key != nil {
+			if itemKey, ok := key(item); ok {
+				for i, existing := range merged {
+					if existingKey, ok := key(existing); ok && existingKey == itemKey {
+						merged[i] = item
+						replaced = true
+						break
+					}
+				}
+			}
+		}
+		if replaced {
+			continue
+		}
+
+		if !slices.ContainsFunc(merged, func(existing any) bool { return reflect.DeepEqual(existing, item) }) {
+			merged = append(merged, item)
+		}
+	}
+
+	return merged
+}
+
+// serviceField returns the field of a service the path is at.
+func serviceField(path []string) (string, bool) {
+	if len(path) != 3 || path[0] != "services" {
+		return "", false
+	}
+
+	return path[2], true
+}
@@ -0,0 +1,251 @@
+package compose
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// normalizeService normalizes the fields of a service that can be written in
+// more than one syntax to a single syntax, so that policies do not need to
+// handle every syntax, and so that the fields can be merged.
+func normalizeService(service map[string]any) (map[string]any, error) {
+	if ports, ok := service["ports"].([]any); ok {
+		normalized, err := normalizePorts(ports)
+		if err != nil {
+			return nil, fmt.Errorf("ports: %w", err)
+		}
+		service["ports"] = normalized
+	}
+
+	if volumes, ok := service["volumes"].([]any); ok {
+		normalized, err := normalizeVolumes(volumes)
+		if err != nil {
+			return nil, fmt.Errorf("volumes: %w", err)
+		}
+		service["volumes"] = normalized
+	}
+
+	for _, field := range []string{"environment", "labels", "annotations"} {
+		if list, ok := service[field].([]any); ok {
+			service[field] = normalizeMapping(list)
+		}
+	}
+
+	if extends, ok := service["extends"].(string); ok {
+		service["extends"] = map[string]any{"service": extends}
+	}
+
+	return service, nil
+}
+
+// normalizePorts normalizes the ports of a service to their long syntax, e.g.
+// "127.0.0.1:8080:80/udp" to a port with a host_ip, a published port, a target
+// port and a protocol. A short syntax port with a range of target ports is
+// normalized to a port for each target port.
+func normalizePorts(ports []any) ([]any, error) {
+	var normalized []any
+	for _, port := range ports {
+		switch port := port.(type) {
+		case map[string]any:
+			long := make(map[string]any, len(port)+2)
+			for key, value := range port {
+				long[key] = value
+			}
+			if published, ok := long["published"]; ok {
+				long["published"] = fmt.Sprint(published)
+			}
+			setDefault(long, "protocol", "tcp")
+			setDefault(long, "mode", "ingress")
+			normalized = append(normalized, long)
+		case string, float64, int, int64:
+			long, err := parsePort(fmt.Sprint(port))
+			if err != nil {
+				return nil, err
+			}
+			normalized = append(normalized, long...)
+		default:
+			return nil, fmt.Errorf("invalid port %v", port)
+		}
+	}
+
+	return normalized, nil
+}
+
+// parsePort parses a port written in the short syntax, which is
+// [[host_ip:]published:]target[/protocol].
+func parsePort(port string) ([]any, error) {
+	spec, protocol, found := strings.Cut(port, "/")
+	if !found {
+		protocol = "tcp"
+	}
+
+	var hostIP, published, target string
+	if strings.HasPrefix(spec, "[") {
+		end := strings.Index(spec, "]")
+		if end < 0 {
+			return nil, fmt.Errorf("invalid port %q", port)
+		}
+		hostIP, spec = spec[1:end], strings.TrimPrefix(spec[end+1:], ":")
+	}
+
+	parts := strings.Split(spec, ":")
+	switch len(parts) {
+	case 1:
+		target = parts[0]
+	case 2:
+		published, target = parts[0], parts[1]
+	case 3:
+		if hostIP != "" {
+			return nil, fmt.Errorf("invalid port %q", port)
+		}
+		hostIP, published, target = parts[0], parts[1], parts[2]
+	default:
+		return nil, fmt.Errorf("invalid port %q", port)
+	}
+
+	targets, err := portRange(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q: %w", port, err)
+	}
+
+	// A range of published ports is mapped to the range of target ports one
+	// to one when they have the same length, and is kept as a range otherwise.
+	publishedPorts := []string{published}
+	if published != "" && len(targets) > 1 {
+		if ports, err := portRange(published); err == nil && len(ports) == len(targets) {
+			publishedPorts = nil
+			for _, p := range ports {
+				publishedPorts = append(publishedPorts, strconv.Itoa(p))
+			}
+		}
+	}
+
+	var long []any
+	for i, target := range targets {
+		p := map[string]any{
+			"target":   target,
+			"protocol": protocol,
+			"mode":     "ingress",
+		}
+		if hostIP != "" {
+			p["host_ip"] = hostIP
+		}
+		if len(publishedPorts) == len(targets) {
+			published = publishedPorts[i]
+		}
+		if published != "" {
+			p["published"] = published
+		}
+		long = append(long, p)
+	}
+
+	return long, nil
+}
+
+// portRange returns the ports of a port or a range of ports, e.g. 8080-8082.
+func portRange(ports string) ([]int, error) {
+	first, last, isRange := strings.Cut(ports, "-")
+	start, err := strconv.Atoi(first)
+	if err != nil {
+		return nil, fmt.Errorf("parse port %q: %w", first, err)
+	}
+	if !isRange {
+		return []int{start}, nil
+	}
+
+	end, err := strconv.Atoi(last)
+	if err != nil {
+		return nil, fmt.Errorf("parse port %q: %w", last, err)
+	}
+	if end < start {
+		return nil, fmt.Errorf("invalid port range %q", ports)
+	}
+
+	var result []int
+	for port := start; port <= end; port++ {
+		result = append(result, port)
+	}
+
+	return result, nil
+}
+
+// normalizeVolumes normalizes the volumes of a service to their long syntax,
+// e.g. "./data:/var/lib/data:ro" to a read only bind mount of ./data at
+// /var/lib/data.
+func normalizeVolumes(volumes []any) ([]any, error) {
+	normalized := make([]any, 0, len(volumes))
+	for _, volume := range volumes {
+		switch volume := volume.(type) {
+		case map[string]any:
+			normalized = append(normalized, volume)
+		case string:
+			normalized = append(normalized, parseVolume(volume))
+		default:
+			return nil, fmt.Errorf("invalid volume %v", volume)
+		}
+	}
+
+	return normalized, nil
+}
+
+// parseVolume parses a volume written in the short syntax, which is
+// [source:]target[:mode]. The source of a bind mount is a path, while the
+// source of a volume is its name, and a volume without a source is anonymous.
+func parseVolume(volume string) map[string]any {
+	parts := strings.Split(volume, ":")
+
+	long := map[string]any{"type": "volume"}
+	switch len(parts) {
+	case 1:
+		long["target"] = parts[0]
+		return long
+	case 2:
+		long["source"], long["target"] = parts[0], parts[1]
+	default:
+		long["source"], long["target"] = parts[0], parts[1]
+		for _, mode := range strings.Split(strings.Join(parts[2:], ":"), ",") {
+			switch mode {
+			case "ro":
+				long["read_only"] = true
+			case "rw":
+			case "z", "Z":
+				long["bind"] = map[string]any{"selinux": mode}
+			case "nocopy":
+				long["volume"] = map[string]any{"nocopy": true}
+			default:
+				long["consistency"] = mode
+			}
+		}
+	}
+
+	source := long["source"].(string)
+	if strings.HasPrefix(source, ".") || strings.HasPrefix(source, "/") || strings.HasPrefix(source, "~") {
+		long["type"] = "bind"
+	}
+
+	return long
+}
+
+// normalizeMapping normalizes a list of NAME=value items, such as the
+// environment variables of a service, to a mapping. An item without a value is
+// mapped to null.
+func normalizeMapping(list []any) map[string]any {
+	mapping := make(map[string]any, len(list))
+	for _, item := range list {
+		name, value, found := strings.Cut(fmt.Sprint(item), "=")
+		if !found {
+			mapping[name] = nil
+			continue
+		}
+		mapping[name] = value
+	}
+
+	return mapping
+}
+
+func setDefault(mapping map[string]any, key string, value any) {
+	if _, ok := mapping[key]; !ok {
+		mapping[key] = value
+	}
+}
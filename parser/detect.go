@@ -0,0 +1,210 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	encjson "encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/viper"
+	yamlv3 "go.yaml.in/yaml/v3"
+)
+
+// ErrUndetected is returned when the format of contents cannot be detected.
+var ErrUndetected = errors.New("format of the contents could not be detected")
+
+// detectLimit is the number of bytes at the start of contents that their
+// format is detected from.
+const detectLimit = 8 * 1024
+
+// Detect returns the parser for the given contents, detected from the formats
+// that can be told apart by their contents alone. Only the first few kilobytes
+// of the contents are examined, up to the last line that they end. The formats
+// are tried from the most to the least distinctive:
+//
+//   - XML, for contents that start with an XML declaration
+//   - JSON, for a valid JSON object or array
+//   - Dockerfile, for contents whose first instruction is FROM
+//   - TOML, for valid TOML that defines at least one key or table
+//   - INI, for contents that start with a [section]
+//   - YAML, for documents whose first document is a mapping or a sequence
+//
+// Binary contents, and plain text that is a scalar YAML document, are not
+// detected.
+func Detect(contents []byte) (string, error) {
+	complete := len(contents) <= detectLimit
+	if !complete {
+		contents = contents[:detectLimit]
+		if bytes.IndexByte(contents, 0) >= 0 {
+			return "", ErrUndetected
+		}
+		if end := bytes.LastIndexByte(contents, '\n'); end >= 0 {
+			contents = contents[:end+1]
+		}
+	}
+
+	contents = bytes.TrimPrefix(contents, []byte("\xef\xbb\xbf"))
+	trimmed := bytes.TrimSpace(contents)
+	if len(trimmed) == 0 || bytes.IndexByte(contents, 0) >= 0 || !utf8.Valid(contents) {
+		return "", ErrUndetected
+	}
+
+	if bytes.HasPrefix(trimmed, []byte("<?xml")) {
+		return XML, nil
+	}
+
+	if trimmed[0] == '{' || trimmed[0] == '[' {
+		if complete && encjson.Valid(trimmed) || !complete && validJSONPrefix(trimmed) {
+			return JSON, nil
+		}
+	}
+
+	if isDockerfile(contents) {
+		return Dockerfile, nil
+	}
+
+	if detected(TOML, contents) {
+		return TOML, nil
+	}
+
+	if section := firstLine(contents, "#;"); strings.HasPrefix(section, "[") && strings.HasSuffix(section, "]") && detected(INI, contents) {
+		return INI, nil
+	}
+
+	var document yamlv3.Node
+	if err := yamlv3.NewDecoder(bytes.NewReader(contents)).Decode(&document); err == nil && len(document.Content) > 0 {
+		kind := document.Content[0].Kind
+		if (kind == yamlv3.MappingNode || kind == yamlv3.SequenceNode) && detected(YAML, contents) {
+			return YAML, nil
+		}
+	}
+
+	return "", ErrUndetected
+}
+
+// NewFromContents returns a parser for the file at the given path in the same
+// way as NewFromPath. When the path does not tell which parser to use and the
+// detect-parser option is set, the parser is detected from the contents of the
+// file, and its name is returned as well.
+func NewFromContents(path string, contents []byte) (Parser, string, error) {
+	parser, err := NewFromPath(path)
+	if err == nil || !viper.GetBool("detect-parser") || path == "-" {
+		return parser, "", err
+	}
+
+	name, err := Detect(contents)
+	if err != nil {
+		return nil, "", fmt.Errorf("detect parser: %w", err)
+	}
+
+	parser, err = New(name)
+	if err != nil {
+		return nil, "", fmt.Errorf("new: %w", err)
+	}
+
+	return parser, name, nil
+}
+
+// detectable returns true if the parser of the file at the given path can be
+// detected from its contents, for files whose path does not tell which parser
+// to use. Only the start of the file that Detect examines is read.
+func detectable(path string) bool {
+	if !viper.GetBool("detect-parser") {
+		return false
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	// One more byte than is examined tells whether the contents are complete.
+	contents, err := io.ReadAll(io.LimitReader(file, detectLimit+1))
+	if err != nil {
+		return false
+	}
+
+	_, err = Detect(contents)
+	return err == nil
+}
+
+// detected returns true if the contents are valid for the given parser and
+// hold at least one value.
+func detected(name string, contents []byte) bool {
+	parser, err := New(name)
+	if err != nil {
+		return false
+	}
+
+	var parsed any
+	if err := parser.Unmarshal(contents, &parsed); err != nil {
+		return false
+	}
+
+	switch value := parsed.(type) {
+	case map[string]any:
+		return len(value) > 0
+	case []any:
+		return len(value) > 0
+	default:
+		return false
+	}
+}
+
+// validJSONPrefix returns true if the contents are the start of valid JSON.
+func validJSONPrefix(contents []byte) bool {
+	decoder := encjson.NewDecoder(bytes.NewReader(contents))
+	for {
+		_, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return true
+		}
+		if err != nil {
+			return errors.Is(err, io.ErrUnexpectedEOF)
+		}
+	}
+}
+
+// isDockerfile returns true if the first instruction of the contents is FROM,
+// or an ARG that is followed by a FROM, as only ARG may precede the FROM.
+func isDockerfile(contents []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		switch strings.ToUpper(fields[0]) {
+		case "FROM":
+			return len(fields) > 1
+		case "ARG":
+			continue
+		default:
+			return false
+		}
+	}
+
+	return false
+}
+
+// firstLine returns the first line of the contents that is neither empty nor
+// a comment starting with one of the given comment characters.
+func firstLine(contents []byte, comments string) string {
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.ContainsRune(comments, rune(line[0])) {
+			return line
+		}
+	}
+
+	return ""
+}
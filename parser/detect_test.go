@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/open-policy-agent/conftest/parser/ini"
+	"github.com/spf13/viper"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name     string
+		contents string
+		expected string
+	}{
+		{"xml", "<?xml version=\"1.0\"?>\n<config/>\n", XML},
+		{"json object", "\xef\xbb\xbf{\"a\": 1}\n", JSON},
+		{"json array", "[1, 2]", JSON},
+		{"dockerfile", "# syntax=docker/dockerfile:1\nFROM alpine:3\nRUN true\n", Dockerfile},
+		{"dockerfile with args", "ARG VERSION=3\nfrom alpine:${VERSION}\n", Dockerfile},
+		{"toml", "title = \"example\"\n\n[owner]\nname = \"Tom\"\n", TOML},
+		{"ini", "; settings\n[server]\nhost = example.com\n", INI},
+		{"yaml mapping", "a: 1\nb:\n  - c\n", YAML},
+		{"yaml sequence", "---\n- a\n- b\n", YAML},
+		{"yaml scalar", "Just some notes.\n", ""},
+		{"invalid json", "{\"a\": 1\n", ""},
+		{"run before from", "RUN true\nFROM alpine:3\n", ""},
+		{"empty", "\n\n", ""},
+		{"binary", "a: \x00\n", ""},
+		{"large json", "[" + strings.Repeat("{\"key\": \"value\"},\n", 1000) + "{}]", JSON},
+		{"large yaml", strings.Repeat("- key: value\n", 1000), YAML},
+		{"large binary", "\x00" + strings.Repeat("a: 1\n", 3000), ""},
+		{"large invalid json", "{\"a\": ]" + strings.Repeat(" ", detectLimit), ""},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual, err := Detect([]byte(testCase.contents))
+			if testCase.expected == "" {
+				if err == nil {
+					t.Errorf("expected an error, detected %q", actual)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("detect: %v", err)
+			}
+			if actual != testCase.expected {
+				t.Errorf("detected %q, want %q", actual, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestNewFromContents(t *testing.T) {
+	contents := []byte("[server]\nhost = example.com\n")
+
+	if _, _, err := NewFromContents("app.conf", contents); err == nil {
+		t.Error("expected an error without the detect-parser option")
+	}
+
+	viper.Set("detect-parser", true)
+	t.Cleanup(func() { viper.Set("detect-parser", false) })
+
+	actual, detected, err := NewFromContents("app.conf", contents)
+	if err != nil {
+		t.Fatalf("new from contents: %v", err)
+	}
+	if _, ok := actual.(*ini.Parser); !ok || detected != INI {
+		t.Errorf("unexpected parser %T detected as %q", actual, detected)
+	}
+
+	// The parsers of known paths are not detected.
+	if _, detected, err := NewFromContents("app.json", []byte("a: 1\n")); err != nil || detected != "" {
+		t.Errorf("unexpected detection %q: %v", detected, err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/moby/buildkit/frontend/dockerfile/instructions"
 	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/open-policy-agent/conftest/parser/suppression"
 )
 
 // Parser is a Dockerfile parser.
@@ -33,6 +34,27 @@ type Command struct {
 
 	// Stage indicates which stage the command is found in a multistage docker build
 	Stage int
+
+	// The name of the stage the command is found in, if it is named with `AS`
+	StageName string
+
+	// Whether the command is found in the final stage of the build
+	Final bool
+
+	// The contents of the command with the ARG and ENV variables in scope
+	// substituted (ex: `golang:1.24` for `golang:${GO_VERSION}`)
+	Expanded []string
+
+	// For FROM, the index of the stage it builds on, and for COPY, the index
+	// of the stage it copies from with `--from`. Null when it is an image.
+	FromStage *int
+
+	// The user the command runs as, set by the last USER of the stage or of
+	// the stages it builds on. Empty when no USER is set.
+	User string
+
+	// For RUN only this holds the simple commands of the command line
+	Run []ShellCommand
 }
 
 // Unmarshal unmarshals Dockerfiles
@@ -45,6 +67,7 @@ func (dp *Parser) Unmarshal(p []byte, v any) error {
 
 	var commands []Command
 	var stages []*instructions.Stage
+	builder := newBuilder(res.EscapeToken)
 
 	for _, child := range res.AST.Children {
 		instr, err := instructions.ParseInstruction(child)
@@ -90,9 +113,17 @@ func (dp *Parser) Unmarshal(p []byte, v any) error {
 			cmd.Value = append(cmd.Value, n.Value)
 		}
 
+		if err := builder.process(&cmd, instr); err != nil {
+			return fmt.Errorf("process dockerfile instructions: %w", err)
+		}
+
 		commands = append(commands, cmd)
 	}
 
+	for i := range commands {
+		commands[i].Final = commands[i].Stage == currentStage(stages)
+	}
+
 	var dockerFile [][]Command
 	dockerFile = append(dockerFile, commands)
 
@@ -117,3 +148,14 @@ func currentStage(stages []*instructions.Stage) int {
 
 	return len(stages) - 1
 }
+
+// Suppressions returns the inline suppression directives in the comments of
+// the Dockerfile.
+func (dp *Parser) Suppressions(p []byte) ([][]suppression.Directive, error) {
+	directives, err := suppression.Scan(p, 0, "#")
+	if err != nil {
+		return nil, fmt.Errorf("scan suppressions: %w", err)
+	}
+
+	return [][]suppression.Directive{directives}, nil
+}
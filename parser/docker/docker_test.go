@@ -1,6 +1,8 @@
 package docker
 
 import (
+	"encoding/json"
+	"reflect"
 	"testing"
 )
 
@@ -68,3 +70,118 @@ RUN go build -o conftest`
 		t.Errorf("expected command to be in stage 1, not stage: %v", stage)
 	}
 }
+
+func TestParser_Unmarshal_Stages(t *testing.T) {
+	parser := Parser{}
+
+	sample := `ARG GO_VERSION=1.24
+FROM golang:${GO_VERSION} AS build
+ARG GO_VERSION
+ENV APP=conftest
+WORKDIR /src/$APP
+USER builder
+RUN go build -o /out/$APP . && echo $GO_VERSION $HOME
+
+FROM build AS test
+RUN go test ./...
+
+FROM alpine:${GO_VERSION}
+COPY --from=build /out/conftest /usr/local/bin/
+COPY --from=1 /src /src
+COPY --from=busybox /bin/sh /bin/sh`
+
+	var commands []Command
+	var input any
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+	j, err := json.Marshal(input.([]any)[0])
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if err := json.Unmarshal(j, &commands); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	stage := func(i int) *int { return &i }
+	expected := []struct {
+		cmd       string
+		stageName string
+		final     bool
+		expanded  []string
+		fromStage *int
+		user      string
+	}{
+		{cmd: "arg", expanded: []string{"GO_VERSION=1.24"}},
+		{cmd: "from", stageName: "build", expanded: []string{"golang:1.24", "AS", "build"}},
+		{cmd: "arg", stageName: "build", expanded: []string{"GO_VERSION"}},
+		{cmd: "env", stageName: "build", expanded: []string{"APP", "conftest", "="}},
+		{cmd: "workdir", stageName: "build", expanded: []string{"/src/conftest"}},
+		{cmd: "user", stageName: "build", expanded: []string{"builder"}, user: "builder"},
+		{cmd: "run", stageName: "build", expanded: []string{"go build -o /out/conftest . && echo 1.24 $HOME"}, user: "builder"},
+		{cmd: "from", stageName: "test", expanded: []string{"build", "AS", "test"}, fromStage: stage(0), user: "builder"},
+		{cmd: "run", stageName: "test", expanded: []string{"go test ./..."}, user: "builder"},
+		{cmd: "from", final: true, expanded: []string{"alpine:1.24"}},
+		{cmd: "copy", final: true, expanded: []string{"/out/conftest", "/usr/local/bin/"}, fromStage: stage(0)},
+		{cmd: "copy", final: true, expanded: []string{"/src", "/src"}, fromStage: stage(1)},
+		{cmd: "copy", final: true, expanded: []string{"/bin/sh", "/bin/sh"}},
+	}
+
+	if len(commands) != len(expected) {
+		t.Fatalf("expected %d commands, got %d", len(expected), len(commands))
+	}
+	for i, want := range expected {
+		got := commands[i]
+		if got.Cmd != want.cmd || got.StageName != want.stageName || got.Final != want.final || got.User != want.user {
+			t.Errorf("command %d: expected %s in stage %q (final %v) as %q, got %s in stage %q (final %v) as %q",
+				i, want.cmd, want.stageName, want.final, want.user, got.Cmd, got.StageName, got.Final, got.User)
+		}
+		if !reflect.DeepEqual(got.Expanded, want.expanded) {
+			t.Errorf("command %d: expected expanded %q, got %q", i, want.expanded, got.Expanded)
+		}
+		if !reflect.DeepEqual(got.FromStage, want.fromStage) {
+			t.Errorf("command %d: expected from stage %v, got %v", i, want.fromStage, got.FromStage)
+		}
+	}
+
+	expectedRun := []ShellCommand{
+		{Args: []string{"go", "build", "-o", "/out/conftest", "."}},
+		{Args: []string{"echo", "1.24", "$HOME"}},
+	}
+	if !reflect.DeepEqual(commands[6].Run, expectedRun) {
+		t.Errorf("expected run %v, got %v", expectedRun, commands[6].Run)
+	}
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected []segment
+	}{
+		{
+			line:     "apt-get update && apt-get install -y curl",
+			expected: []segment{{line: "apt-get update "}, {line: " apt-get install -y curl"}},
+		},
+		{
+			line:     "curl -fsSL https://example.com/install.sh | sh",
+			expected: []segment{{line: "curl -fsSL https://example.com/install.sh ", pipe: true}, {line: " sh"}},
+		},
+		{
+			line:     `echo "a | b; c" 'd && e' || true; make >out 2>&1 &`,
+			expected: []segment{{line: `echo "a | b; c" 'd && e' `}, {line: " true"}, {line: " make >out 2>&1 "}, {line: ""}},
+		},
+		{
+			line:     `echo a\;b`,
+			expected: []segment{{line: `echo a\;b`}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			actual := splitCommandLine(test.line)
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package docker
+
+import (
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+)
+
+// ShellCommand is a simple command of the command line of a RUN command.
+type ShellCommand struct {
+
+	// The words of the command, with their variables expanded and their
+	// quotes removed (ex: `["apt-get", "install", "-y", "curl"]`)
+	Args []string
+
+	// Whether the output of the command is piped to the next command
+	Pipe bool
+}
+
+// run splits the command line of a RUN command into its simple commands. A RUN
+// command written in JSON form is a single command, and one that only runs a
+// heredoc runs the commands of the heredoc. The words the builder cannot
+// expand, such as command substitutions, are split on spaces.
+func (b *builder) run(cmd *Command, instr *instructions.RunCommand, env environment) []ShellCommand {
+	if cmd.JSON {
+		return []ShellCommand{{Args: cmd.Expanded}}
+	}
+
+	lines := cmd.Value
+	if len(instr.Files) > 0 && len(lines) == 1 && strings.HasPrefix(strings.TrimSpace(lines[0]), "<<") {
+		lines = nil
+		for _, file := range instr.Files {
+			lines = append(lines, file.Data)
+		}
+	}
+
+	b.lex.SkipUnsetEnv = true
+	defer func() { b.lex.SkipUnsetEnv = false }()
+
+	var commands []ShellCommand
+	for _, line := range lines {
+		for _, segment := range splitCommandLine(line) {
+			args, err := b.lex.ProcessWords(segment.line, env)
+			if err != nil {
+				args = strings.Fields(segment.line)
+			}
+			if len(args) == 0 {
+				continue
+			}
+
+			commands = append(commands, ShellCommand{Args: args, Pipe: segment.pipe})
+		}
+	}
+
+	return commands
+}
+
+type segment struct {
+	line string
+	pipe bool
+}
+
+// splitCommandLine splits a shell command line on the operators that separate
+// simple commands: &&, ||, ;, &, | and newlines, outside of quotes.
+func splitCommandLine(line string) []segment {
+	var segments []segment
+	var current strings.Builder
+	var quote rune
+
+	end := func(pipe bool) {
+		segments = append(segments, segment{line: current.String(), pipe: pipe})
+		current.Reset()
+	}
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case quote != 0:
+			if r == '\\' && quote == '"' && i+1 < len(runes) {
+				current.WriteRune(r)
+				i++
+				r = runes[i]
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '\\' && i+1 < len(runes):
+			current.WriteRune(r)
+			i++
+			r = runes[i]
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '|' || r == '&':
+			if i+1 < len(runes) && runes[i+1] == r {
+				i++
+				end(false)
+				continue
+			}
+			if r == '&' && i > 0 && (runes[i-1] == '>' || runes[i-1] == '<') {
+				break
+			}
+			if r == '&' && i+1 < len(runes) && runes[i+1] == '>' {
+				break
+			}
+			end(r == '|')
+			continue
+		case r == ';' || r == '\n':
+			end(false)
+			continue
+		}
+
+		current.WriteRune(r)
+	}
+	end(false)
+
+	return segments
+}
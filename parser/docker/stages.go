@@ -0,0 +1,261 @@
+package docker
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/shell"
+)
+
+// shellInstructions are the instructions whose arguments are not expanded by
+// the builder, but by the shell the command is run with.
+var shellInstructions = []string{"cmd", "entrypoint", "healthcheck", "onbuild", "run", "shell"}
+
+// environment holds the values of the build arguments and environment
+// variables in scope. It implements shell.EnvGetter.
+type environment map[string]string
+
+// Get returns the value of the variable with the given name.
+func (e environment) Get(key string) (string, bool) {
+	value, ok := e[key]
+	return value, ok
+}
+
+// Keys returns the names of the variables.
+func (e environment) Keys() []string {
+	keys := make([]string, 0, len(e))
+	for key := range e {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (e environment) clone() environment {
+	cloned := make(environment, len(e))
+	for key, value := range e {
+		cloned[key] = value
+	}
+
+	return cloned
+}
+
+// stage is the state of a build stage, as of the command being processed.
+type stage struct {
+	name string
+	env  environment
+	user string
+}
+
+// builder follows the stages of a Dockerfile, to expand the variables of its
+// commands and to resolve the stages they depend on.
+type builder struct {
+	lex *shell.Lex
+
+	// globalArgs are the build arguments declared before the first FROM,
+	// which are in scope in FROM commands.
+	globalArgs environment
+	stages     []*stage
+}
+
+func newBuilder(escapeToken rune) *builder {
+	return &builder{
+		lex:        shell.NewLex(escapeToken),
+		globalArgs: environment{},
+	}
+}
+
+// process adds the expanded values of the command, and the stage it depends
+// on, to the command. It then applies the effects of the instruction on the
+// variables and user of the current stage.
+func (b *builder) process(cmd *Command, instr any) error {
+	if st, ok := instr.(*instructions.Stage); ok {
+		return b.from(cmd, st)
+	}
+
+	env := b.globalArgs
+	if current := b.current(); current != nil {
+		env = current.env
+	}
+
+	expanded, err := b.expand(cmd, env)
+	if err != nil {
+		return fmt.Errorf("expand %s: %w", cmd.Cmd, err)
+	}
+	cmd.Expanded = expanded
+
+	switch instr := instr.(type) {
+	case *instructions.ArgCommand:
+		for _, arg := range instr.Args {
+			value, ok, err := b.argValue(arg, env)
+			if err != nil {
+				return fmt.Errorf("expand arg %s: %w", arg.Key, err)
+			}
+			if ok {
+				env[arg.Key] = value
+			}
+		}
+	case *instructions.EnvCommand:
+		// All of the values of an ENV command are expanded before any of the
+		// variables it sets are in scope.
+		values := make([]string, len(instr.Env))
+		for i, pair := range instr.Env {
+			value, _, err := b.lex.ProcessWord(pair.Value, env)
+			if err != nil {
+				return fmt.Errorf("expand env %s: %w", pair.Key, err)
+			}
+			values[i] = value
+		}
+		for i, pair := range instr.Env {
+			env[pair.Key] = values[i]
+		}
+	case *instructions.UserCommand:
+		user, _, err := b.lex.ProcessWord(instr.User, env)
+		if err != nil {
+			return fmt.Errorf("expand user: %w", err)
+		}
+		if current := b.current(); current != nil {
+			current.user = user
+		}
+	case *instructions.CopyCommand:
+		if instr.From != "" {
+			from, _, err := b.lex.ProcessWord(instr.From, env)
+			if err != nil {
+				return fmt.Errorf("expand copy --from: %w", err)
+			}
+			cmd.FromStage = stageIndex(b.previous(), from, true)
+		}
+	case *instructions.RunCommand:
+		cmd.Run = b.run(cmd, instr, env)
+	}
+
+	if current := b.current(); current != nil {
+		cmd.StageName = current.name
+		cmd.User = current.user
+	}
+
+	return nil
+}
+
+// from starts a new stage. A stage that builds on a previous stage starts with
+// its variables and user.
+func (b *builder) from(cmd *Command, st *instructions.Stage) error {
+	expanded, err := b.expand(cmd, b.globalArgs)
+	if err != nil {
+		return fmt.Errorf("expand from: %w", err)
+	}
+	cmd.Expanded = expanded
+
+	base, _, err := b.lex.ProcessWord(st.BaseName, b.globalArgs)
+	if err != nil {
+		return fmt.Errorf("expand from: %w", err)
+	}
+
+	next := &stage{name: st.Name, env: environment{}}
+	if index := stageIndex(b.stages, base, false); index != nil {
+		cmd.FromStage = index
+		next.env = b.stages[*index].env.clone()
+		next.user = b.stages[*index].user
+	}
+	b.stages = append(b.stages, next)
+
+	cmd.StageName = next.name
+	cmd.User = next.user
+
+	return nil
+}
+
+// expand substitutes the variables in the values of a command. The arguments
+// of commands run by a shell keep their quotes, and the variables that are
+// not in scope, as the shell expands them when the command is run. Commands
+// written in JSON form are not run by a shell, and are not expanded at all,
+// and neither are the shell commands the builder cannot expand.
+func (b *builder) expand(cmd *Command, env environment) ([]string, error) {
+	if cmd.Cmd == "comment" {
+		return nil, nil
+	}
+
+	runByShell := slices.Contains(shellInstructions, cmd.Cmd)
+	if runByShell && cmd.JSON {
+		return slices.Clone(cmd.Value), nil
+	}
+	b.lex.RawQuotes = runByShell
+	b.lex.RawEscapes = runByShell
+	b.lex.SkipUnsetEnv = runByShell
+	defer func() {
+		b.lex.RawQuotes = false
+		b.lex.RawEscapes = false
+		b.lex.SkipUnsetEnv = false
+	}()
+
+	expanded := make([]string, 0, len(cmd.Value))
+	for _, value := range cmd.Value {
+		word, _, err := b.lex.ProcessWord(value, env)
+		if err != nil && runByShell {
+			return slices.Clone(cmd.Value), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, word)
+	}
+
+	return expanded, nil
+}
+
+// argValue returns the value of a build argument declared with ARG. An ARG in
+// a stage without a default value takes the default value of the global
+// build argument with the same name, and is unset otherwise.
+func (b *builder) argValue(arg instructions.KeyValuePairOptional, env environment) (string, bool, error) {
+	if arg.Value == nil {
+		if b.current() == nil {
+			return "", false, nil
+		}
+		value, ok := b.globalArgs[arg.Key]
+		return value, ok, nil
+	}
+
+	value, _, err := b.lex.ProcessWord(*arg.Value, env)
+	return value, true, err
+}
+
+func (b *builder) current() *stage {
+	if len(b.stages) == 0 {
+		return nil
+	}
+
+	return b.stages[len(b.stages)-1]
+}
+
+// previous returns the stages before the current stage.
+func (b *builder) previous() []*stage {
+	if len(b.stages) == 0 {
+		return nil
+	}
+
+	return b.stages[:len(b.stages)-1]
+}
+
+// stageIndex returns the index of the stage with the given name, or nil when
+// the name refers to an image instead. Stages can also be referred to by
+// their index when copying files from them.
+func stageIndex(stages []*stage, name string, byIndex bool) *int {
+	for i, st := range stages {
+		if st.name != "" && strings.EqualFold(st.name, name) {
+			return &i
+		}
+	}
+
+	if byIndex {
+		if i, err := strconv.Atoi(name); err == nil && i >= 0 && i < len(stages) {
+			return &i
+		}
+	}
+
+	return nil
+}
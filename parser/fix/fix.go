@@ -0,0 +1,193 @@
+// Package fix updates the YAML nodes of parsed documents to hold new values,
+// replacing only the nodes whose values changed so that the comments, anchors
+// and styles of the others are kept when the documents are written back.
+package fix
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	yamlv3 "go.yaml.in/yaml/v3"
+	"sigs.k8s.io/yaml"
+)
+
+// mergeKey is the key of the YAML 1.1 merge key, whose aliased mappings are
+// merged into the mapping that declares it.
+const mergeKey = "<<"
+
+// Decode returns the value of the node as it is read by the parsers, which use
+// YAML 1.1 semantics and represent values as their JSON equivalents.
+func Decode(node *yamlv3.Node) (any, error) {
+	data, err := yamlv3.Marshal(resolve(node))
+	if err != nil {
+		return nil, fmt.Errorf("marshal node: %w", err)
+	}
+
+	var value any
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("unmarshal node: %w", err)
+	}
+
+	return value, nil
+}
+
+// Equal reports whether two values are equal once represented as JSON.
+func Equal(a, b any) bool {
+	x, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	y, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(x, y)
+}
+
+// Update updates the node to hold the given value, and returns the node that
+// holds it, which is the node itself unless it had to be replaced. Mappings
+// and sequences are updated in place, keeping the order of their entries and
+// adding new keys at the end, in alphabetical order. The comments of replaced
+// nodes are moved to their replacements.
+func Update(node *yamlv3.Node, value any) (*yamlv3.Node, error) {
+	current, err := Decode(node)
+	if err != nil {
+		return nil, err
+	}
+	if Equal(current, value) {
+		return node, nil
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		if node.Kind == yamlv3.MappingNode {
+			return node, updateMapping(node, current, value)
+		}
+	case []any:
+		if node.Kind == yamlv3.SequenceNode {
+			return node, updateSequence(node, value)
+		}
+	}
+
+	replacement, err := NewNode(value)
+	if err != nil {
+		return nil, err
+	}
+	replacement.HeadComment = node.HeadComment
+	replacement.LineComment = node.LineComment
+	replacement.FootComment = node.FootComment
+
+	return replacement, nil
+}
+
+func updateMapping(node *yamlv3.Node, current any, value map[string]any) error {
+	merged, _ := current.(map[string]any)
+
+	var content []*yamlv3.Node
+	declared := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if key.Value == mergeKey && key.Tag == "!!merge" {
+			content = append(content, key, node.Content[i+1])
+			continue
+		}
+
+		entry, ok := value[key.Value]
+		if !ok {
+			continue
+		}
+
+		updated, err := Update(node.Content[i+1], entry)
+		if err != nil {
+			return fmt.Errorf("update %q: %w", key.Value, err)
+		}
+		content = append(content, key, updated)
+		declared[key.Value] = true
+	}
+
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		// Keys merged from an aliased mapping only need to be declared when
+		// their value changed.
+		if mergedValue, ok := merged[key]; declared[key] || ok && Equal(mergedValue, value[key]) {
+			continue
+		}
+
+		keyNode, err := NewNode(key)
+		if err != nil {
+			return err
+		}
+		valueNode, err := NewNode(value[key])
+		if err != nil {
+			return err
+		}
+		content = append(content, keyNode, valueNode)
+	}
+
+	node.Content = content
+	return nil
+}
+
+func updateSequence(node *yamlv3.Node, value []any) error {
+	content := slices.Clone(node.Content)
+	if len(content) > len(value) {
+		content = content[:len(value)]
+	}
+
+	for i, item := range value {
+		if i < len(content) {
+			updated, err := Update(content[i], item)
+			if err != nil {
+				return fmt.Errorf("update item %d: %w", i, err)
+			}
+			content[i] = updated
+			continue
+		}
+
+		itemNode, err := NewNode(item)
+		if err != nil {
+			return err
+		}
+		content = append(content, itemNode)
+	}
+
+	node.Content = content
+	return nil
+}
+
+// NewNode returns a node holding the value. Mappings and sequences that are
+// added are written in block style, whatever the style of their parent.
+func NewNode(value any) (*yamlv3.Node, error) {
+	var node yamlv3.Node
+	if err := node.Encode(value); err != nil {
+		return nil, fmt.Errorf("encode value: %w", err)
+	}
+
+	return &node, nil
+}
+
+// resolve returns a copy of the node in which the aliases are replaced by the
+// nodes they refer to, so that the node can be encoded on its own.
+func resolve(node *yamlv3.Node) *yamlv3.Node {
+	if node.Kind == yamlv3.AliasNode && node.Alias != nil {
+		return resolve(node.Alias)
+	}
+
+	resolved := *node
+	resolved.Anchor = ""
+	resolved.Content = make([]*yamlv3.Node, 0, len(node.Content))
+	for _, child := range node.Content {
+		resolved.Content = append(resolved.Content, resolve(child))
+	}
+
+	return &resolved
+}
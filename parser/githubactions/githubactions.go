@@ -0,0 +1,172 @@
+// Package githubactions parses GitHub Actions workflows, normalizing their jobs
+// and the actions and reusable workflows they use.
+package githubactions
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/parser/location"
+	"github.com/open-policy-agent/conftest/parser/suppression"
+	"github.com/open-policy-agent/conftest/parser/yaml"
+)
+
+// Parser is a GitHub Actions workflow parser. Workflows are parsed in the same
+// way as any other YAML file, with the following additions, which leave the
+// values of the workflow as they are:
+//
+//   - The on key, which is read as the boolean true, is also added as on.
+//   - The needs and runs-on of every job are added as lists, under needs-list
+//     and runs-on-list respectively.
+//   - Every step and job that uses an action or a reusable workflow has its
+//     reference split into its parts, under action or workflow respectively.
+type Parser struct {
+	yaml yaml.Parser
+}
+
+// Reference is a reference to an action or a reusable workflow, as given to
+// uses, e.g. actions/checkout@v4 or docker://alpine:3.20.
+type Reference struct {
+	// Kind is repository for an action or workflow in a repository, local for
+	// one in the repository of the workflow, and docker for a container image.
+	Kind string `json:"kind"`
+
+	// The owner, repository, path within the repository and ref of an action or
+	// workflow in a repository.
+	Owner string `json:"owner,omitempty"`
+	Repo  string `json:"repo,omitempty"`
+	Path  string `json:"path,omitempty"`
+	Ref   string `json:"ref,omitempty"`
+
+	// Image is the container image of a docker action.
+	Image string `json:"image,omitempty"`
+
+	// Pinned is true when the reference cannot change: a repository reference
+	// pinned to a full commit SHA, or an image pinned to a digest. Local
+	// references are always pinned, as they are part of the same commit.
+	Pinned bool `json:"pinned"`
+}
+
+var commitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// ParseReference parses a reference to an action or a reusable workflow.
+func ParseReference(uses string) (Reference, error) {
+	if strings.HasPrefix(uses, "./") {
+		return Reference{Kind: "local", Path: uses, Pinned: true}, nil
+	}
+
+	if image, ok := strings.CutPrefix(uses, "docker://"); ok {
+		return Reference{Kind: "docker", Image: image, Pinned: strings.Contains(image, "@sha256:")}, nil
+	}
+
+	name, ref, found := strings.Cut(uses, "@")
+	parts := strings.SplitN(name, "/", 3)
+	if !found || ref == "" || len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return Reference{}, fmt.Errorf("invalid reference %q, expected owner/repo[/path]@ref", uses)
+	}
+
+	reference := Reference{
+		Kind:   "repository",
+		Owner:  parts[0],
+		Repo:   parts[1],
+		Ref:    ref,
+		Pinned: commitSHA.MatchString(ref),
+	}
+	if len(parts) == 3 {
+		reference.Path = parts[2]
+	}
+
+	return reference, nil
+}
+
+// Unmarshal unmarshals GitHub Actions workflows.
+func (p *Parser) Unmarshal(data []byte, v any) error {
+	var workflow any
+	if err := p.yaml.Unmarshal(data, &workflow); err != nil {
+		return err
+	}
+
+	if workflow, ok := workflow.(map[string]any); ok {
+		if err := normalize(workflow); err != nil {
+			return err
+		}
+	}
+
+	j, err := json.Marshal(workflow)
+	if err != nil {
+		return fmt.Errorf("marshal workflow to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal workflow json: %w", err)
+	}
+
+	return nil
+}
+
+// Locate returns the position of every value in the workflow.
+func (p *Parser) Locate(data []byte) ([]location.Map, error) {
+	return p.yaml.Locate(data)
+}
+
+// Suppressions returns the inline suppression directives in the comments of
+// the workflow.
+func (p *Parser) Suppressions(data []byte) ([][]suppression.Directive, error) {
+	return p.yaml.Suppressions(data)
+}
+
+// normalize adds the normalized forms of the values of the workflow to it, in
+// place.
+func normalize(workflow map[string]any) error {
+	// YAML 1.1 reads an unquoted on key as the boolean true.
+	if on, ok := workflow["true"]; ok {
+		if _, exists := workflow["on"]; !exists {
+			workflow["on"] = on
+		}
+	}
+
+	jobs, _ := workflow["jobs"].(map[string]any)
+	for id, job := range jobs {
+		job, ok := job.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		for _, field := range []string{"needs", "runs-on"} {
+			switch value := job[field].(type) {
+			case string:
+				job[field+"-list"] = []any{value}
+			case []any:
+				job[field+"-list"] = value
+			}
+		}
+
+		if uses, ok := job["uses"].(string); ok {
+			reference, err := ParseReference(uses)
+			if err != nil {
+				return fmt.Errorf("job %s: %w", id, err)
+			}
+			job["workflow"] = reference
+		}
+
+		steps, _ := job["steps"].([]any)
+		for i, step := range steps {
+			step, ok := step.(map[string]any)
+			if !ok {
+				continue
+			}
+
+			if uses, ok := step["uses"].(string); ok {
+				reference, err := ParseReference(uses)
+				if err != nil {
+					return fmt.Errorf("job %s step %d: %w", id, i, err)
+				}
+				step["action"] = reference
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package githubactions
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		uses    string
+		want    Reference
+		wantErr bool
+	}{
+		{
+			uses: "actions/checkout@v4",
+			want: Reference{Kind: "repository", Owner: "actions", Repo: "checkout", Ref: "v4"},
+		},
+		{
+			uses: "actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683",
+			want: Reference{Kind: "repository", Owner: "actions", Repo: "checkout", Ref: "11bd71901bbe5b1630ceea73d27597364c9af683", Pinned: true},
+		},
+		{
+			uses: "octo-org/example-repo/.github/workflows/reusable.yml@main",
+			want: Reference{Kind: "repository", Owner: "octo-org", Repo: "example-repo", Path: ".github/workflows/reusable.yml", Ref: "main"},
+		},
+		{
+			uses: "./.github/actions/setup",
+			want: Reference{Kind: "local", Path: "./.github/actions/setup", Pinned: true},
+		},
+		{
+			uses: "docker://alpine:3.20",
+			want: Reference{Kind: "docker", Image: "alpine:3.20"},
+		},
+		{
+			uses: "docker://alpine@sha256:beefdbd8a1da6d2915566fde36db9db0b524eb737fc57cd1367effd16dc0d06d",
+			want: Reference{Kind: "docker", Image: "alpine@sha256:beefdbd8a1da6d2915566fde36db9db0b524eb737fc57cd1367effd16dc0d06d", Pinned: true},
+		},
+		{
+			uses:    "actions/checkout",
+			wantErr: true,
+		},
+		{
+			uses:    "checkout@v4",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uses, func(t *testing.T) {
+			got, err := ParseReference(tt.uses)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected reference (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	workflow := `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - run: make
+  deploy:
+    needs: build
+    uses: octo-org/workflows/.github/workflows/deploy.yml@v1
+`
+
+	var got map[string]any
+	if err := (&Parser{}).Unmarshal([]byte(workflow), &got); err != nil {
+		t.Fatalf("Unmarshal: unexpected error %v", err)
+	}
+
+	want := map[string]any{
+		"true": "push",
+		"on":   "push",
+		"jobs": map[string]any{
+			"build": map[string]any{
+				"runs-on":      "ubuntu-latest",
+				"runs-on-list": []any{"ubuntu-latest"},
+				"steps": []any{
+					map[string]any{
+						"uses":   "actions/checkout@v4",
+						"action": map[string]any{"kind": "repository", "owner": "actions", "repo": "checkout", "ref": "v4", "pinned": false},
+					},
+					map[string]any{"run": "make"},
+				},
+			},
+			"deploy": map[string]any{
+				"needs":      "build",
+				"needs-list": []any{"build"},
+				"uses":       "octo-org/workflows/.github/workflows/deploy.yml@v1",
+				"workflow":   map[string]any{"kind": "repository", "owner": "octo-org", "repo": "workflows", "path": ".github/workflows/deploy.yml", "ref": "v1", "pinned": false},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected workflow (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,338 @@
+// Package gitlabci parses GitLab CI/CD pipeline configurations, expanding their
+// local includes and references, and normalizing their jobs.
+package gitlabci
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/parser/location"
+	"github.com/open-policy-agent/conftest/parser/suppression"
+	"github.com/open-policy-agent/conftest/parser/yaml"
+	yamlv3 "go.yaml.in/yaml/v3"
+)
+
+// Parser is a GitLab CI/CD configuration parser. The configuration is parsed in
+// the same way as any other YAML file, and the effective configuration of its
+// jobs is added under jobs, unless the configuration has a job of that name. To
+// find the jobs:
+//
+//   - The files included with include: local are merged into the
+//     configuration, with the values of the including file taking precedence.
+//   - Every !reference tag is replaced by the value it refers to.
+//   - Every job is merged with the jobs it extends and with the default
+//     keywords, and has its scripts flattened to lists of commands.
+type Parser struct {
+	path string
+	yaml yaml.Parser
+}
+
+// SetPath sets the path of the configuration being parsed. Local includes are
+// relative to its directory, which is the root of the repository for the
+// .gitlab-ci.yml file of a repository.
+func (p *Parser) SetPath(path string) {
+	p.path = path
+}
+
+// Unmarshal unmarshals GitLab CI/CD configurations.
+func (p *Parser) Unmarshal(data []byte, v any) error {
+	var original map[string]any
+	if err := p.yaml.Unmarshal(data, &original); err != nil {
+		return err
+	}
+	if original == nil {
+		original = map[string]any{}
+	}
+
+	config, err := load(data)
+	if err != nil {
+		return err
+	}
+
+	root := "."
+	if p.path != "" && p.path != "-" {
+		root = filepath.Dir(p.path)
+	}
+
+	config, err = expandIncludes(config, root, []string{p.path})
+	if err != nil {
+		return err
+	}
+
+	resolved, err := resolveReferences(config, config, 0)
+	if err != nil {
+		return err
+	}
+	config = resolved.(map[string]any)
+
+	jobs, err := normalizeJobs(config)
+	if err != nil {
+		return err
+	}
+	if _, exists := original["jobs"]; !exists {
+		original["jobs"] = jobs
+	}
+
+	j, err := json.Marshal(original)
+	if err != nil {
+		return fmt.Errorf("marshal configuration to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal configuration json: %w", err)
+	}
+
+	return nil
+}
+
+// Locate returns the position of the values in the configuration, which are
+// those of the file itself rather than of the files it includes.
+func (p *Parser) Locate(data []byte) ([]location.Map, error) {
+	return p.yaml.Locate(data)
+}
+
+// Suppressions returns the inline suppression directives in the comments of the
+// configuration.
+func (p *Parser) Suppressions(data []byte) ([][]suppression.Directive, error) {
+	return p.yaml.Suppressions(data)
+}
+
+// referenceKey is the key of the mapping a !reference tag is replaced with
+// while the configuration is decoded, until it is resolved.
+const referenceKey = "!reference"
+
+// maxDepth is the maximum depth of nested references and extended jobs, as
+// enforced by GitLab.
+const maxDepth = 10
+
+// load decodes a configuration, replacing its !reference tags with mappings
+// that hold the path they refer to.
+func load(data []byte) (map[string]any, error) {
+	var node yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("unmarshal yaml: %w", err)
+	}
+	markReferences(&node)
+
+	var config any
+	if err := node.Decode(&config); err != nil {
+		return nil, fmt.Errorf("decode yaml: %w", err)
+	}
+	if config == nil {
+		return map[string]any{}, nil
+	}
+
+	mapping, ok := config.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("configuration must be a mapping, found %T", config)
+	}
+
+	return mapping, nil
+}
+
+// markReferences replaces every sequence tagged with !reference with a mapping
+// from referenceKey to the sequence.
+func markReferences(node *yamlv3.Node) {
+	for _, child := range node.Content {
+		markReferences(child)
+	}
+
+	if node.Tag != "!reference" || node.Kind != yamlv3.SequenceNode {
+		return
+	}
+
+	sequence := *node
+	sequence.Tag = "!!seq"
+	*node = yamlv3.Node{
+		Kind: yamlv3.MappingNode,
+		Tag:  "!!map",
+		Content: []*yamlv3.Node{
+			{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: referenceKey},
+			&sequence,
+		},
+	}
+}
+
+// expandIncludes merges the files the configuration includes with include:
+// local into it, along with the files they include in turn. Other includes,
+// such as remote files, templates and components, are kept as they are. The
+// files being included are tracked to report files that include themselves.
+func expandIncludes(config map[string]any, root string, including []string) (map[string]any, error) {
+	var includes []any
+	switch include := config["include"].(type) {
+	case nil:
+		return config, nil
+	case []any:
+		includes = include
+	default:
+		includes = []any{include}
+	}
+
+	included := map[string]any{}
+	var kept []any
+	for _, include := range includes {
+		local, ok := localInclude(include)
+		if !ok {
+			kept = append(kept, include)
+			continue
+		}
+
+		paths, err := filepath.Glob(filepath.Join(root, strings.TrimPrefix(local, "/")))
+		if err != nil {
+			return nil, fmt.Errorf("include %s: %w", local, err)
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("include %s: no such file", local)
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			if slices.Contains(including, path) {
+				return nil, fmt.Errorf("include %s: %s includes itself", local, path)
+			}
+
+			contents, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("include %s: %w", local, err)
+			}
+
+			includedConfig, err := load(contents)
+			if err != nil {
+				return nil, fmt.Errorf("include %s: %w", local, err)
+			}
+
+			includedConfig, err = expandIncludes(includedConfig, root, append(slices.Clone(including), path))
+			if err != nil {
+				return nil, err
+			}
+
+			included = merge(included, includedConfig).(map[string]any)
+		}
+	}
+
+	own := make(map[string]any, len(config))
+	for key, value := range config {
+		if key != "include" {
+			own[key] = value
+		}
+	}
+	if len(kept) > 0 {
+		own["include"] = kept
+	}
+
+	return merge(included, own).(map[string]any), nil
+}
+
+// localInclude returns the path of the file included by an include: local,
+// which can also be written as the path alone.
+func localInclude(include any) (string, bool) {
+	switch include := include.(type) {
+	case string:
+		if u, err := url.Parse(include); err == nil && u.Scheme != "" {
+			return "", false
+		}
+		return include, true
+	case map[string]any:
+		local, ok := include["local"].(string)
+		return local, ok
+	default:
+		return "", false
+	}
+}
+
+// merge deep merges the override value into the base value, in the same way as
+// GitLab merges included files and extended jobs: mappings are merged, and any
+// other value, including a sequence, is replaced.
+func merge(base, override any) any {
+	overrideMapping, ok := override.(map[string]any)
+	if !ok {
+		return override
+	}
+	baseMapping, ok := base.(map[string]any)
+	if !ok {
+		return override
+	}
+
+	merged := make(map[string]any, len(baseMapping)+len(overrideMapping))
+	for key, value := range baseMapping {
+		merged[key] = value
+	}
+	for key, value := range overrideMapping {
+		if existing, ok := merged[key]; ok {
+			merged[key] = merge(existing, value)
+			continue
+		}
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// resolveReferences replaces every reference in the value with the value of
+// the configuration it refers to.
+func resolveReferences(value any, config map[string]any, depth int) (any, error) {
+	switch value := value.(type) {
+	case map[string]any:
+		if path, ok := reference(value); ok {
+			if depth >= maxDepth {
+				return nil, fmt.Errorf("!reference %v: too many nested references", path)
+			}
+
+			var referenced any = config
+			for _, key := range path {
+				mapping, ok := referenced.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("!reference %v: %s not found", path, key)
+				}
+				if referenced, ok = mapping[key]; !ok {
+					return nil, fmt.Errorf("!reference %v: %s not found", path, key)
+				}
+			}
+
+			return resolveReferences(referenced, config, depth+1)
+		}
+
+		resolved := make(map[string]any, len(value))
+		for key, item := range value {
+			resolvedItem, err := resolveReferences(item, config, depth)
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = resolvedItem
+		}
+		return resolved, nil
+	case []any:
+		resolved := make([]any, len(value))
+		for i, item := range value {
+			resolvedItem, err := resolveReferences(item, config, depth)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = resolvedItem
+		}
+		return resolved, nil
+	default:
+		return value, nil
+	}
+}
+
+// reference returns the path the value refers to, when it is a reference.
+func reference(value map[string]any) ([]string, bool) {
+	sequence, ok := value[referenceKey].([]any)
+	if !ok || len(value) != 1 {
+		return nil, false
+	}
+
+	path := make([]string, 0, len(sequence))
+	for _, key := range sequence {
+		path = append(path, fmt.Sprint(key))
+	}
+
+	return path, true
+}
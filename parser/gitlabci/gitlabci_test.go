@@ -0,0 +1,165 @@
+package gitlabci
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUnmarshal(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".gitlab-ci.yml": `include:
+  - local: /ci/*.yml
+  - remote: https://example.com/ci.yml
+
+default:
+  image: golang:1.24
+  before_script:
+    - echo default
+
+.cache: &cache
+  cache:
+    key: go
+
+test:
+  <<: *cache
+  extends: .base
+  script:
+    - !reference [.setup, script]
+    - go test ./...
+
+lint:
+  stage: lint
+  inherit:
+    default: [image]
+  needs: [test]
+  script: golangci-lint run
+`,
+		"ci/base.yml": `.base:
+  variables:
+    CGO_ENABLED: "0"
+  script:
+    - echo base
+`,
+		"ci/setup.yml": `.setup:
+  script:
+    - go mod download
+    - go generate ./...
+`,
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	p := &Parser{}
+	p.SetPath(filepath.Join(dir, ".gitlab-ci.yml"))
+
+	var got map[string]any
+	if err := p.Unmarshal([]byte(files[".gitlab-ci.yml"]), &got); err != nil {
+		t.Fatalf("Unmarshal: unexpected error %v", err)
+	}
+
+	wantInclude := []any{
+		map[string]any{"local": "/ci/*.yml"},
+		map[string]any{"remote": "https://example.com/ci.yml"},
+	}
+	if diff := cmp.Diff(wantInclude, got["include"]); diff != "" {
+		t.Errorf("expected the includes to be kept as they are (-want +got):\n%s", diff)
+	}
+
+	wantScript := []any{[]any{".setup", "script"}, "go test ./..."}
+	if diff := cmp.Diff(wantScript, got["test"].(map[string]any)["script"]); diff != "" {
+		t.Errorf("expected the script of the job to be kept as it is (-want +got):\n%s", diff)
+	}
+
+	wantJobs := map[string]any{
+		"test": map[string]any{
+			"stage":         "test",
+			"image":         map[string]any{"name": "golang:1.24"},
+			"before_script": []any{"echo default"},
+			"cache":         map[string]any{"key": "go"},
+			"variables":     map[string]any{"CGO_ENABLED": "0"},
+			"script":        []any{"go mod download", "go generate ./...", "go test ./..."},
+		},
+		"lint": map[string]any{
+			"stage":   "lint",
+			"image":   map[string]any{"name": "golang:1.24"},
+			"inherit": map[string]any{"default": []any{"image"}},
+			"needs":   []any{map[string]any{"job": "test"}},
+			"script":  []any{"golangci-lint run"},
+		},
+	}
+	if diff := cmp.Diff(wantJobs, got["jobs"]); diff != "" {
+		t.Errorf("unexpected jobs (-want +got):\n%s", diff)
+	}
+}
+
+func TestUnmarshalJobNamedJobs(t *testing.T) {
+	config := `jobs:
+  script: make
+`
+
+	var got map[string]any
+	if err := (&Parser{}).Unmarshal([]byte(config), &got); err != nil {
+		t.Fatalf("Unmarshal: unexpected error %v", err)
+	}
+
+	want := map[string]any{"jobs": map[string]any{"script": "make"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("expected the job to be kept as it is (-want +got):\n%s", diff)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{
+			name: "job extends itself",
+			config: `a:
+  extends: b
+b:
+  extends: a
+`,
+		},
+		{
+			name: "missing extended job",
+			config: `a:
+  extends: .missing
+`,
+		},
+		{
+			name: "missing reference",
+			config: `a:
+  script: !reference [.missing, script]
+`,
+		},
+		{
+			name: "missing local include",
+			config: `include: /missing.yml
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Parser{}
+			p.SetPath(filepath.Join(t.TempDir(), ".gitlab-ci.yml"))
+
+			var got map[string]any
+			if err := p.Unmarshal([]byte(tt.config), &got); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package gitlabci
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// keywords are the top-level keywords of a configuration that are not jobs.
+var keywords = []string{
+	"after_script",
+	"before_script",
+	"cache",
+	"default",
+	"image",
+	"include",
+	"services",
+	"spec",
+	"stages",
+	"types",
+	"variables",
+	"workflow",
+}
+
+// defaultKeywords are the keywords of the default section that jobs inherit.
+// The after_script, before_script, cache, image and services keywords can also
+// be declared at the top level, which is deprecated.
+var defaultKeywords = []string{
+	"after_script",
+	"artifacts",
+	"before_script",
+	"cache",
+	"hooks",
+	"id_tokens",
+	"image",
+	"interruptible",
+	"retry",
+	"services",
+	"tags",
+	"timeout",
+}
+
+// normalizeJobs returns the jobs of the configuration, which are its top-level
+// keys that are neither keywords nor hidden jobs, starting with a dot. Every
+// job is merged with the jobs it extends, inherits the default keywords, and
+// has its scripts flattened to lists of commands.
+func normalizeJobs(config map[string]any) (map[string]any, error) {
+	defaults := map[string]any{}
+	for _, keyword := range defaultKeywords {
+		if value, ok := config[keyword]; ok {
+			defaults[keyword] = value
+		}
+	}
+	if section, ok := config["default"].(map[string]any); ok {
+		defaults = merge(defaults, section).(map[string]any)
+	}
+
+	jobs := make(map[string]any)
+	for name, job := range config {
+		if slices.Contains(keywords, name) || strings.HasPrefix(name, ".") {
+			continue
+		}
+		if _, ok := job.(map[string]any); !ok {
+			continue
+		}
+
+		extended, err := extendedJob(config, name, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		jobs[name] = normalizeJob(inheritDefaults(extended, defaults))
+	}
+
+	return jobs, nil
+}
+
+// extendedJob returns the job with the given name, merged with the jobs it
+// extends, in order. The jobs being extended are tracked to report jobs that
+// extend themselves.
+func extendedJob(config map[string]any, name string, extending []string) (map[string]any, error) {
+	job, ok := config[name].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("extended job %s not found", name)
+	}
+
+	var extends []string
+	switch value := job["extends"].(type) {
+	case nil:
+		return job, nil
+	case string:
+		extends = []string{value}
+	case []any:
+		for _, base := range value {
+			extends = append(extends, fmt.Sprint(base))
+		}
+	default:
+		return nil, fmt.Errorf("job %s: invalid extends %v", name, value)
+	}
+
+	if slices.Contains(extending, name) {
+		return nil, fmt.Errorf("job %s extends itself", name)
+	}
+	if len(extending) > maxDepth {
+		return nil, fmt.Errorf("job %s: too many levels of extends", name)
+	}
+	extending = append(extending, name)
+
+	var extended any = map[string]any{}
+	for _, base := range extends {
+		baseJob, err := extendedJob(config, base, extending)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %w", name, err)
+		}
+		extended = merge(extended, baseJob)
+	}
+
+	own := make(map[string]any, len(job))
+	for key, value := range job {
+		if key != "extends" {
+			own[key] = value
+		}
+	}
+
+	return merge(extended, own).(map[string]any), nil
+}
+
+// inheritDefaults returns the job with the default keywords it does not
+// declare itself, unless it disables their inheritance with inherit: default.
+func inheritDefaults(job map[string]any, defaults map[string]any) map[string]any {
+	inherited := func(keyword string) bool {
+		inherit, _ := job["inherit"].(map[string]any)
+		switch value := inherit["default"].(type) {
+		case bool:
+			return value
+		case []any:
+			return slices.Contains(value, any(keyword))
+		default:
+			return true
+		}
+	}
+
+	result := make(map[string]any, len(job)+len(defaults))
+	for key, value := range job {
+		result[key] = value
+	}
+	for keyword, value := range defaults {
+		if _, ok := result[keyword]; !ok && inherited(keyword) {
+			result[keyword] = value
+		}
+	}
+
+	return result
+}
+
+// normalizeJob normalizes the keywords of the job that can be written in more
+// than one syntax to a single syntax.
+func normalizeJob(job map[string]any) map[string]any {
+	for _, keyword := range []string{"script", "before_script", "after_script"} {
+		switch script := job[keyword].(type) {
+		case string:
+			job[keyword] = []any{script}
+		case []any:
+			job[keyword] = flatten(script)
+		}
+	}
+
+	if image, ok := job["image"].(string); ok {
+		job["image"] = map[string]any{"name": image}
+	}
+
+	if needs, ok := job["needs"].([]any); ok {
+		normalized := make([]any, 0, len(needs))
+		for _, need := range needs {
+			if name, ok := need.(string); ok {
+				need = map[string]any{"job": name}
+			}
+			normalized = append(normalized, need)
+		}
+		job["needs"] = normalized
+	}
+
+	if _, ok := job["stage"]; !ok {
+		job["stage"] = "test"
+	}
+
+	return job
+}
+
+// flatten flattens the nested lists of commands of a script, such as those
+// added by a !reference to another script.
+func flatten(script []any) []any {
+	var flattened []any
+	for _, command := range script {
+		if nested, ok := command.([]any); ok {
+			flattened = append(flattened, flatten(nested)...)
+			continue
+		}
+		flattened = append(flattened, command)
+	}
+
+	return flattened
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/hashicorp/hcl"
+	"github.com/open-policy-agent/conftest/parser/suppression"
 )
 
 // Parser is an HCL parser.
@@ -18,3 +19,14 @@ func (s *Parser) Unmarshal(p []byte, v any) error {
 
 	return nil
 }
+
+// Suppressions returns the inline suppression directives in the comments of
+// the HCL file, which start with either # or //.
+func (s *Parser) Suppressions(p []byte) ([][]suppression.Directive, error) {
+	directives, err := suppression.Scan(p, 0, "#", "//")
+	if err != nil {
+		return nil, fmt.Errorf("scan suppressions: %w", err)
+	}
+
+	return [][]suppression.Directive{directives}, nil
+}
@@ -0,0 +1,434 @@
+package hcl2
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/open-policy-agent/conftest/parser/location"
+	"github.com/zclconf/go-cty/cty"
+	ctyconvert "github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// functions are the functions that can be called by evaluated expressions, a
+// subset of the functions built into Terraform that do not depend on the
+// filesystem or on the state of any resources.
+var functions = map[string]function.Function{
+	// numeric
+	"abs":      stdlib.AbsoluteFunc,
+	"ceil":     stdlib.CeilFunc,
+	"floor":    stdlib.FloorFunc,
+	"log":      stdlib.LogFunc,
+	"max":      stdlib.MaxFunc,
+	"min":      stdlib.MinFunc,
+	"parseint": stdlib.ParseIntFunc,
+	"pow":      stdlib.PowFunc,
+	"signum":   stdlib.SignumFunc,
+
+	// string
+	"chomp":        stdlib.ChompFunc,
+	"format":       stdlib.FormatFunc,
+	"formatlist":   stdlib.FormatListFunc,
+	"indent":       stdlib.IndentFunc,
+	"join":         stdlib.JoinFunc,
+	"lower":        stdlib.LowerFunc,
+	"regex":        stdlib.RegexFunc,
+	"regexall":     stdlib.RegexAllFunc,
+	"replace":      stdlib.ReplaceFunc,
+	"split":        stdlib.SplitFunc,
+	"strrev":       stdlib.ReverseFunc,
+	"substr":       stdlib.SubstrFunc,
+	"title":        stdlib.TitleFunc,
+	"trim":         stdlib.TrimFunc,
+	"trimprefix":   stdlib.TrimPrefixFunc,
+	"trimsuffix":   stdlib.TrimSuffixFunc,
+	"trimspace":    stdlib.TrimSpaceFunc,
+	"upper":        stdlib.UpperFunc,
+	"coalesce":     stdlib.CoalesceFunc,
+	"coalescelist": stdlib.CoalesceListFunc,
+
+	// collections
+	"chunklist": stdlib.ChunklistFunc,
+	"compact":   stdlib.CompactFunc,
+	"concat":    stdlib.ConcatFunc,
+	"contains":  stdlib.ContainsFunc,
+	"distinct":  stdlib.DistinctFunc,
+	"element":   stdlib.ElementFunc,
+	"flatten":   stdlib.FlattenFunc,
+	"keys":      stdlib.KeysFunc,
+	"length":    stdlib.LengthFunc,
+	"lookup":    stdlib.LookupFunc,
+	"merge":     stdlib.MergeFunc,
+	"range":     stdlib.RangeFunc,
+	"reverse":   stdlib.ReverseListFunc,
+	"setunion":  stdlib.SetUnionFunc,
+	"slice":     stdlib.SliceFunc,
+	"sort":      stdlib.SortFunc,
+	"values":    stdlib.ValuesFunc,
+	"zipmap":    stdlib.ZipmapFunc,
+
+	// type conversion
+	"tobool":   stdlib.MakeToFunc(cty.Bool),
+	"tolist":   stdlib.MakeToFunc(cty.List(cty.DynamicPseudoType)),
+	"tomap":    stdlib.MakeToFunc(cty.Map(cty.DynamicPseudoType)),
+	"tonumber": stdlib.MakeToFunc(cty.Number),
+	"toset":    stdlib.MakeToFunc(cty.Set(cty.DynamicPseudoType)),
+	"tostring": stdlib.MakeToFunc(cty.String),
+
+	// encoding
+	"csvdecode":  stdlib.CSVDecodeFunc,
+	"jsondecode": stdlib.JSONDecodeFunc,
+	"jsonencode": stdlib.JSONEncodeFunc,
+
+	// time
+	"formatdate": stdlib.FormatDateFunc,
+	"timeadd":    stdlib.TimeAddFunc,
+}
+
+// module holds the declarations of the Terraform module a file belongs to,
+// which are spread across all of the .tf files in its directory.
+type module struct {
+	variables map[string]*hclsyntax.Block
+	locals    map[string]hclsyntax.Expression
+}
+
+// evalContext returns the context that the expressions of the given file are
+// evaluated in. The variables are assigned their default values, which are
+// overridden by the values in the variable files, and the locals are
+// evaluated in turn. Values that can not be determined are unknown.
+func (p Parser) evalContext(body *hclsyntax.Body) (*hcl.EvalContext, error) {
+	declarations := module{
+		variables: make(map[string]*hclsyntax.Block),
+		locals:    make(map[string]hclsyntax.Expression),
+	}
+	for _, moduleBody := range append(p.moduleBodies(), body) {
+		declarations.add(moduleBody)
+	}
+
+	ctx := &hcl.EvalContext{
+		Functions: functions,
+		Variables: make(map[string]cty.Value),
+	}
+
+	variables := make(map[string]cty.Value, len(declarations.variables))
+	for name, block := range declarations.variables {
+		variables[name] = cty.DynamicVal
+		if attribute, ok := block.Body.Attributes["default"]; ok {
+			if value, diags := attribute.Expr.Value(ctx); !diags.HasErrors() {
+				variables[name] = value
+			}
+		}
+	}
+
+	for _, path := range p.varFiles() {
+		values, err := varFileValues(path, ctx)
+		if err != nil {
+			return nil, fmt.Errorf("variable file %s: %w", path, err)
+		}
+		maps.Copy(variables, values)
+	}
+
+	for name, block := range declarations.variables {
+		variables[name] = convertToType(variables[name], block)
+	}
+	ctx.Variables["var"] = cty.ObjectVal(variables)
+
+	ctx.Variables["local"] = evaluateLocals(declarations.locals, ctx)
+
+	return ctx, nil
+}
+
+// add adds the variables and locals declared in the given body.
+func (m module) add(body *hclsyntax.Body) {
+	for _, block := range body.Blocks {
+		switch {
+		case block.Type == "variable" && len(block.Labels) == 1:
+			m.variables[block.Labels[0]] = block
+		case block.Type == "locals":
+			for name, attribute := range block.Body.Attributes {
+				m.locals[name] = attribute.Expr
+			}
+		}
+	}
+}
+
+// moduleBodies returns the bodies of the other .tf files in the directory of
+// the file being parsed. Files that can not be read or parsed are skipped, as
+// their declarations are only used on a best effort basis.
+func (p Parser) moduleBodies() []*hclsyntax.Body {
+	if p.path == "" || p.path == "-" {
+		return nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(filepath.Dir(p.path), "*.tf"))
+	if err != nil {
+		return nil
+	}
+
+	var bodies []*hclsyntax.Body
+	for _, path := range paths {
+		if filepath.Clean(path) == filepath.Clean(p.path) {
+			continue
+		}
+
+		if body, err := parseFile(path); err == nil {
+			bodies = append(bodies, body)
+		}
+	}
+
+	return bodies
+}
+
+// varFiles returns the variable files whose values are assigned to the
+// variables, in increasing order of precedence. As with Terraform, these are
+// terraform.tfvars, when it exists, and the *.auto.tfvars files in the
+// directory of the file being parsed, followed by the .tfvars files that are parsed along with it
+// from the same directory, and finally the variable files that were given
+// explicitly.
+func (p Parser) varFiles() []string {
+	var paths []string
+	if p.path != "" && p.path != "-" {
+		dir := filepath.Dir(p.path)
+		if _, err := os.Stat(filepath.Join(dir, "terraform.tfvars")); err == nil {
+			paths = append(paths, filepath.Join(dir, "terraform.tfvars"))
+		}
+
+		auto, _ := filepath.Glob(filepath.Join(dir, "*.auto.tfvars"))
+		slices.Sort(auto)
+		paths = append(paths, auto...)
+
+		for _, sibling := range p.siblings {
+			if filepath.Ext(sibling) == ".tfvars" && filepath.Dir(sibling) == dir {
+				paths = append(paths, sibling)
+			}
+		}
+	}
+	paths = append(paths, p.VarFiles...)
+
+	var varFiles []string
+	for _, path := range paths {
+		if !slices.Contains(varFiles, filepath.Clean(path)) {
+			varFiles = append(varFiles, filepath.Clean(path))
+		}
+	}
+
+	return varFiles
+}
+
+// varFileValues returns the values of the variables assigned in the variable
+// file at the given path.
+func varFileValues(path string, ctx *hcl.EvalContext) (map[string]cty.Value, error) {
+	body, err := parseFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]cty.Value, len(body.Attributes))
+	for name, attribute := range body.Attributes {
+		if value, diags := attribute.Expr.Value(ctx); !diags.HasErrors() {
+			values[name] = value
+		}
+	}
+
+	return values, nil
+}
+
+func parseFile(path string) (*hclsyntax.Body, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	file, diags := hclsyntax.ParseConfig(contents, path, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("parse config: %v", diags.Errs())
+	}
+
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("convert file body to body type")
+	}
+
+	return body, nil
+}
+
+// convertToType converts the value of a variable to the type it declares, so
+// that for example a number assigned to a string variable is a string.
+func convertToType(value cty.Value, block *hclsyntax.Block) cty.Value {
+	attribute, ok := block.Body.Attributes["type"]
+	if !ok || !value.IsWhollyKnown() {
+		return value
+	}
+
+	ty, diags := typeexpr.TypeConstraint(attribute.Expr)
+	if diags.HasErrors() {
+		return value
+	}
+
+	converted, err := ctyconvert.Convert(value, ty)
+	if err != nil {
+		return value
+	}
+
+	return converted
+}
+
+// evaluateLocals evaluates the locals, which may refer to each other in any
+// order. Locals are evaluated repeatedly until no more of them can be
+// determined, and the locals that remain, such as those that refer to
+// resources, are unknown.
+func evaluateLocals(locals map[string]hclsyntax.Expression, ctx *hcl.EvalContext) cty.Value {
+	values := make(map[string]cty.Value, len(locals))
+	for name := range locals {
+		values[name] = cty.DynamicVal
+	}
+
+	for progress := true; progress; {
+		progress = false
+		ctx.Variables["local"] = cty.ObjectVal(values)
+		for name, expr := range locals {
+			if values[name].IsWhollyKnown() {
+				continue
+			}
+
+			value, diags := expr.Value(ctx)
+			if diags.HasErrors() || !value.IsWhollyKnown() {
+				continue
+			}
+
+			values[name] = value
+			progress = true
+		}
+	}
+
+	// Locals that are only partially known, such as an object where a single
+	// attribute refers to a resource, still provide the attributes that are.
+	ctx.Variables["local"] = cty.ObjectVal(values)
+	for name, expr := range locals {
+		if values[name].IsWhollyKnown() {
+			continue
+		}
+
+		if value, diags := expr.Value(ctx); !diags.HasErrors() {
+			values[name] = value
+		}
+	}
+
+	return cty.ObjectVal(values)
+}
+
+// evaluateBody replaces the converted values of the attributes in the body
+// with their evaluated values. The path of the body in the converted file is
+// the same as the one reported by Locate. The declarations of variables are
+// left as they are written.
+func evaluateBody(converted any, body *hclsyntax.Body, path []string, ctx *hcl.EvalContext) {
+	blockCounts := make(map[string]int)
+	for _, block := range body.Blocks {
+		blockPath := slices.Concat(path, []string{block.Type}, block.Labels)
+
+		key := location.Pointer(blockPath)
+		blockPath = append(blockPath, strconv.Itoa(blockCounts[key]))
+		blockCounts[key]++
+
+		if block.Type == "variable" {
+			continue
+		}
+
+		evaluateBody(converted, block.Body, blockPath, ctx)
+	}
+
+	object, ok := lookup(converted, path).(map[string]any)
+	if !ok {
+		return
+	}
+
+	for name, attribute := range body.Attributes {
+		object[name] = evaluateExpression(attribute.Expr, object[name], ctx)
+	}
+}
+
+// evaluateExpression returns the value of the expression when it is known,
+// and the converted value otherwise. The items of lists and objects are
+// evaluated separately, so that only the items that are unknown fall back to
+// their converted value.
+func evaluateExpression(expr hclsyntax.Expression, converted any, ctx *hcl.EvalContext) any {
+	value, diags := expr.Value(ctx)
+	if !diags.HasErrors() && value.IsWhollyKnown() {
+		if evaluated, ok := toJSON(value); ok {
+			return evaluated
+		}
+	}
+
+	switch e := expr.(type) {
+	case *hclsyntax.TemplateWrapExpr:
+		return evaluateExpression(e.Wrapped, converted, ctx)
+	case *hclsyntax.TupleConsExpr:
+		list, ok := converted.([]any)
+		if !ok || len(list) != len(e.Exprs) {
+			return converted
+		}
+		for i, item := range e.Exprs {
+			list[i] = evaluateExpression(item, list[i], ctx)
+		}
+	case *hclsyntax.ObjectConsExpr:
+		object, ok := converted.(map[string]any)
+		if !ok {
+			return converted
+		}
+		for _, item := range e.Items {
+			key, ok := objectKey(item.KeyExpr)
+			if _, exists := object[key]; !ok || !exists {
+				continue
+			}
+			object[key] = evaluateExpression(item.ValueExpr, object[key], ctx)
+		}
+	}
+
+	return converted
+}
+
+// toJSON converts the value to its plain JSON representation.
+func toJSON(value cty.Value) (any, bool) {
+	data, err := json.Marshal(ctyjson.SimpleJSONValue{Value: value})
+	if err != nil {
+		return nil, false
+	}
+
+	var converted any
+	if err := json.Unmarshal(data, &converted); err != nil {
+		return nil, false
+	}
+
+	return converted, true
+}
+
+// lookup returns the value at the given path of the converted file, where the
+// segments of the path are either object keys or list indexes.
+func lookup(converted any, path []string) any {
+	for _, segment := range path {
+		switch node := converted.(type) {
+		case map[string]any:
+			converted = node[segment]
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil
+			}
+			converted = node[index]
+		default:
+			return nil
+		}
+	}
+
+	return converted
+}
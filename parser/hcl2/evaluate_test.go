@@ -0,0 +1,158 @@
+package hcl2
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUnmarshalEvaluate(t *testing.T) {
+	module := map[string]string{
+		"variables.tf": `
+variable "environment" {
+  type    = string
+  default = "dev"
+}
+
+variable "replicas" {
+  type = number
+}
+
+variable "zones" {
+  default = ["a", "b"]
+}
+`,
+		"terraform.tfvars":  `replicas = "2"`,
+		"prod.tfvars":       `environment = "prod"`,
+		"zones.auto.tfvars": `zones = ["c"]`,
+	}
+
+	input := `
+locals {
+  name   = "${var.environment}-logs"
+  prefix = upper(local.short)
+  short  = substr(var.environment, 0, 3)
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket   = local.name
+  prefix   = local.prefix
+  replicas = var.replicas
+  zones    = var.zones
+  acl      = var.environment == "prod" ? "private" : "public-read"
+  tags = {
+    env   = var.environment
+    owner = data.external.owner.result
+  }
+  ids = [length(var.zones), aws_s3_bucket.other.id]
+}
+
+variable "unused" {
+  default = var.environment
+}
+`
+
+	tests := []struct {
+		desc     string
+		siblings []string
+		varFiles []string
+		want     map[string]any
+	}{
+		{
+			desc: "defaults and automatic variable files",
+			want: map[string]any{
+				"bucket":   "dev-logs",
+				"prefix":   "DEV",
+				"replicas": float64(2),
+				"zones":    []any{"c"},
+				"acl":      "public-read",
+				"tags": map[string]any{
+					"env":   "dev",
+					"owner": "${data.external.owner.result}",
+				},
+				"ids": []any{float64(1), "${aws_s3_bucket.other.id}"},
+			},
+		},
+		{
+			desc:     "variable file parsed along with the file",
+			siblings: []string{"main.tf", "prod.tfvars"},
+			want: map[string]any{
+				"bucket":   "prod-logs",
+				"prefix":   "PRO",
+				"replicas": float64(2),
+				"zones":    []any{"c"},
+				"acl":      "private",
+				"tags": map[string]any{
+					"env":   "prod",
+					"owner": "${data.external.owner.result}",
+				},
+				"ids": []any{float64(1), "${aws_s3_bucket.other.id}"},
+			},
+		},
+		{
+			desc:     "explicit variable file",
+			varFiles: []string{"prod.tfvars"},
+			want: map[string]any{
+				"bucket":   "prod-logs",
+				"prefix":   "PRO",
+				"replicas": float64(2),
+				"zones":    []any{"c"},
+				"acl":      "private",
+				"tags": map[string]any{
+					"env":   "prod",
+					"owner": "${data.external.owner.result}",
+				},
+				"ids": []any{float64(1), "${aws_s3_bucket.other.id}"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, contents := range module {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o600); err != nil {
+					t.Fatalf("write %s: %v", name, err)
+				}
+			}
+
+			p := &Parser{Evaluate: true}
+			p.SetPath(filepath.Join(dir, "main.tf"))
+
+			var siblings []string
+			for _, sibling := range tc.siblings {
+				siblings = append(siblings, filepath.Join(dir, sibling))
+			}
+			p.SetSiblings(siblings)
+			for _, varFile := range tc.varFiles {
+				p.VarFiles = append(p.VarFiles, filepath.Join(dir, varFile))
+			}
+
+			var got map[string]any
+			if err := p.Unmarshal([]byte(input), &got); err != nil {
+				t.Fatalf("Unmarshal: unexpected error %v", err)
+			}
+
+			bucket := got["resource"].(map[string]any)["aws_s3_bucket"].(map[string]any)["logs"].([]any)[0]
+			if diff := cmp.Diff(tc.want, bucket); diff != "" {
+				t.Errorf("unexpected resource (-want +got):\n%s", diff)
+			}
+
+			variable := got["variable"].(map[string]any)["unused"].([]any)[0]
+			if diff := cmp.Diff(map[string]any{"default": "${var.environment}"}, variable); diff != "" {
+				t.Errorf("variable declarations should not be evaluated (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnmarshalEvaluateMissingVarFile(t *testing.T) {
+	p := &Parser{Evaluate: true, VarFiles: []string{filepath.Join(t.TempDir(), "missing.tfvars")}}
+
+	var got map[string]any
+	if err := p.Unmarshal([]byte(`name = "logs"`), &got); err == nil {
+		t.Error("expected an error for a missing variable file")
+	}
+}
@@ -0,0 +1,248 @@
+package hcl2
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/open-policy-agent/conftest/parser/fix"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// Fix replaces the HCL2 file with the value returned by the function, which is
+// given the value of the file as written, without evaluating it. Only the
+// attributes that changed are written again, and blocks are added and removed
+// as a whole, so that the comments and formatting of the rest of the file are
+// kept. Strings holding a single interpolation, such as "${var.name}", are
+// written as the expression they hold.
+func (p *Parser) Fix(data []byte, document int, fn func(value any) (any, error)) ([]byte, error) {
+	if document != 0 {
+		return nil, fmt.Errorf("document %d not found, an HCL2 file has a single document", document)
+	}
+
+	// The function is given its own copy of the document, which it may change.
+	var current, input map[string]any
+	if err := (&Parser{}).Unmarshal(data, &current); err != nil {
+		return nil, err
+	}
+	if err := (&Parser{}).Unmarshal(data, &input); err != nil {
+		return nil, err
+	}
+
+	value, err := fn(input)
+	if err != nil {
+		return nil, err
+	}
+	if fix.Equal(current, value) {
+		return data, nil
+	}
+
+	target, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("an HCL2 file can only hold an object, not %T", value)
+	}
+
+	file, diags := hclwrite.ParseConfig(data, p.path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("parse hcl2: %w", diags)
+	}
+	if err := fixBody(file.Body(), current, target); err != nil {
+		return nil, err
+	}
+	fixed := file.Bytes()
+
+	var written any
+	if err := (&Parser{}).Unmarshal(fixed, &written); err != nil {
+		return nil, fmt.Errorf("parse fixed hcl2: %w", err)
+	}
+	if !fix.Equal(written, target) {
+		return nil, fmt.Errorf("the fixed file cannot be written without changing other values")
+	}
+
+	return fixed, nil
+}
+
+// fixBody updates the attributes and blocks of the body from their current
+// values to the target values. The blocks of a type are represented by their
+// labels, one object per label, and by the list of the bodies of the blocks
+// that share the same labels.
+func fixBody(body *hclwrite.Body, current, target map[string]any) error {
+	for name := range body.Attributes() {
+		value, ok := target[name]
+		if !ok {
+			body.RemoveAttribute(name)
+			continue
+		}
+		if !fix.Equal(current[name], value) {
+			if err := setAttribute(body, name, value); err != nil {
+				return fmt.Errorf("set %q: %w", name, err)
+			}
+		}
+	}
+
+	labelCounts := make(map[string]int)
+	declared := make(map[string]int)
+	for _, block := range body.Blocks() {
+		labelCounts[block.Type()] = len(block.Labels())
+
+		key := strings.Join(append([]string{block.Type()}, block.Labels()...), "\x00")
+		index := declared[key]
+		declared[key]++
+
+		targetBody, ok := blockBody(target, block.Type(), block.Labels(), index)
+		if !ok {
+			body.RemoveBlock(block)
+			continue
+		}
+
+		currentBody, _ := blockBody(current, block.Type(), block.Labels(), index)
+		if err := fixBody(block.Body(), currentBody, targetBody); err != nil {
+			return fmt.Errorf("fix %s block: %w", block.Type(), err)
+		}
+	}
+
+	names := make([]string, 0, len(target))
+	for name := range target {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if body.GetAttribute(name) != nil {
+			continue
+		}
+
+		// New lists of objects are written as blocks without labels, as that
+		// is how such blocks are represented.
+		count, ok := labelCounts[name]
+		if ok || isBodies(target[name]) {
+			if err := appendBlocks(body, name, nil, count, current[name], target[name]); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := setAttribute(body, name, target[name]); err != nil {
+			return fmt.Errorf("set %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// blockBody returns the body of the block of the given type and labels that
+// is declared at the given index among the blocks sharing those labels.
+func blockBody(value map[string]any, blockType string, labels []string, index int) (map[string]any, bool) {
+	v := value[blockType]
+	for _, label := range labels {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		v = m[label]
+	}
+
+	bodies, ok := v.([]any)
+	if !ok || index >= len(bodies) {
+		return nil, false
+	}
+
+	body, ok := bodies[index].(map[string]any)
+	return body, ok
+}
+
+// appendBlocks appends the blocks of the type that are in the target value,
+// but not in the current value.
+func appendBlocks(body *hclwrite.Body, blockType string, labels []string, labelCount int, current, target any) error {
+	if len(labels) < labelCount {
+		targets, ok := target.(map[string]any)
+		if !ok {
+			return fmt.Errorf("the %s blocks must be an object of their labels", blockType)
+		}
+		currents, _ := current.(map[string]any)
+
+		keys := make([]string, 0, len(targets))
+		for key := range targets {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if err := appendBlocks(body, blockType, append(labels, key), labelCount, currents[key], targets[key]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	bodies, ok := target.([]any)
+	if !ok {
+		return fmt.Errorf("the %s blocks must be a list of bodies", blockType)
+	}
+	existing, _ := current.([]any)
+
+	for _, item := range bodies[min(len(existing), len(bodies)):] {
+		attributes, ok := item.(map[string]any)
+		if !ok {
+			return fmt.Errorf("the body of a %s block must be an object", blockType)
+		}
+
+		block := body.AppendNewBlock(blockType, labels)
+		if err := fixBody(block.Body(), nil, attributes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isBodies(value any) bool {
+	items, ok := value.([]any)
+	if !ok || len(items) == 0 {
+		return false
+	}
+
+	for _, item := range items {
+		if _, ok := item.(map[string]any); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// setAttribute sets the attribute to the value, written as the expression it
+// holds when it is an interpolated string.
+func setAttribute(body *hclwrite.Body, name string, value any) error {
+	if s, ok := value.(string); ok && strings.Contains(s, "${") {
+		source := fmt.Sprintf("%s = %q", name, s)
+		if expression, ok := strings.CutPrefix(s, "${"); ok && strings.HasSuffix(expression, "}") && !strings.Contains(expression, "${") {
+			source = fmt.Sprintf("%s = %s", name, strings.TrimSuffix(expression, "}"))
+		}
+
+		file, diags := hclwrite.ParseConfig([]byte(source), "", hcl.InitialPos)
+		if !diags.HasErrors() && file.Body().GetAttribute(name) != nil {
+			body.SetAttributeRaw(name, file.Body().GetAttribute(name).Expr().BuildTokens(nil))
+			return nil
+		}
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("marshal value: %w", err)
+	}
+	impliedType, err := ctyjson.ImpliedType(data)
+	if err != nil {
+		return fmt.Errorf("imply type: %w", err)
+	}
+	ctyValue, err := ctyjson.Unmarshal(data, impliedType)
+	if err != nil {
+		return fmt.Errorf("unmarshal value: %w", err)
+	}
+
+	body.SetAttributeValue(name, ctyValue)
+	return nil
+}
@@ -3,17 +3,52 @@ package hcl2
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strconv"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/open-policy-agent/conftest/parser/location"
+	"github.com/open-policy-agent/conftest/parser/suppression"
 	"github.com/tmccombs/hcl2json/convert"
+	"github.com/zclconf/go-cty/cty"
 )
 
 // Parser is an HCL2 parser.
-type Parser struct{}
+type Parser struct {
+	// Evaluate resolves the references to variables and locals, and the calls
+	// to functions, when their values can be determined. Expressions whose
+	// values are unknown are kept as they are written.
+	Evaluate bool
+
+	// VarFiles are the paths of the variable files whose values are assigned
+	// to the variables when evaluating.
+	VarFiles []string
+
+	path     string
+	siblings []string
+}
+
+// SetPath sets the path of the file being parsed, whose directory holds the
+// other files of the Terraform module it belongs to.
+func (p *Parser) SetPath(path string) {
+	p.path = path
+}
+
+// SetSiblings sets the paths of the files that are parsed along with the file
+// being parsed, which may include variable files.
+func (p *Parser) SetSiblings(paths []string) {
+	p.siblings = paths
+}
 
 // Unmarshal unmarshals HCL files that are written using
 // version 2 of the HCL language.
-func (Parser) Unmarshal(p []byte, v any) error {
-	hclBytes, err := convert.Bytes(p, "", convert.Options{})
+func (p Parser) Unmarshal(data []byte, v any) error {
+	if p.Evaluate {
+		return p.unmarshalEvaluated(data, v)
+	}
+
+	hclBytes, err := convert.Bytes(data, "", convert.Options{})
 	if err != nil {
 		return fmt.Errorf("convert to bytes: %w", err)
 	}
@@ -24,3 +59,148 @@ func (Parser) Unmarshal(p []byte, v any) error {
 
 	return nil
 }
+
+// unmarshalEvaluated unmarshals the HCL file, replacing the expressions whose
+// values can be determined with their values.
+func (p Parser) unmarshalEvaluated(data []byte, v any) error {
+	file, diags := hclsyntax.ParseConfig(data, p.path, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return fmt.Errorf("parse config: %v", diags.Errs())
+	}
+
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return fmt.Errorf("convert file body to body type")
+	}
+
+	hclBytes, err := convert.File(file, convert.Options{})
+	if err != nil {
+		return fmt.Errorf("convert to bytes: %w", err)
+	}
+
+	var converted any
+	if err := json.Unmarshal(hclBytes, &converted); err != nil {
+		return fmt.Errorf("unmarshal hcl2: %w", err)
+	}
+
+	ctx, err := p.evalContext(body)
+	if err != nil {
+		return fmt.Errorf("evaluate: %w", err)
+	}
+	evaluateBody(converted, body, nil, ctx)
+
+	evaluated, err := json.Marshal(converted)
+	if err != nil {
+		return fmt.Errorf("marshal evaluated hcl2: %w", err)
+	}
+
+	if err := json.Unmarshal(evaluated, v); err != nil {
+		return fmt.Errorf("unmarshal hcl2: %w", err)
+	}
+
+	return nil
+}
+
+// Locate returns the position of every block and attribute in the HCL file.
+// The paths mirror the structure produced by Unmarshal, where the labels of a
+// block are nested objects and every block is wrapped in a list, for example
+// "/resource/aws_s3_bucket/logs/0/acl".
+func (Parser) Locate(p []byte) ([]location.Map, error) {
+	file, diags := hclsyntax.ParseConfig(p, "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("parse config: %v", diags.Errs())
+	}
+
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("convert file body to body type")
+	}
+
+	locations := make(location.Map)
+	locations.Set(nil, position(body.SrcRange.Start))
+	locateBody(locations, body, nil)
+
+	return []location.Map{locations}, nil
+}
+
+func locateBody(locations location.Map, body *hclsyntax.Body, path []string) {
+	// Blocks that share the same type and labels are appended to the same
+	// list when converted, so count them to know the index of each block.
+	blockCounts := make(map[string]int)
+	for _, block := range body.Blocks {
+		blockPath := slices.Concat(path, []string{block.Type}, block.Labels)
+		for i := len(path) + 1; i <= len(blockPath); i++ {
+			locations.Set(blockPath[:i], position(block.DefRange().Start))
+		}
+
+		key := location.Pointer(blockPath)
+		blockPath = append(blockPath, strconv.Itoa(blockCounts[key]))
+		blockCounts[key]++
+
+		locations.Set(blockPath, position(block.DefRange().Start))
+		locateBody(locations, block.Body, blockPath)
+	}
+
+	for name, attribute := range body.Attributes {
+		attributePath := append(slices.Clone(path), name)
+		locations.Set(attributePath, position(attribute.NameRange.Start))
+		locateExpression(locations, attribute.Expr, attributePath)
+	}
+}
+
+func locateExpression(locations location.Map, expr hclsyntax.Expression, path []string) {
+	switch e := expr.(type) {
+	case *hclsyntax.TemplateWrapExpr:
+		locateExpression(locations, e.Wrapped, path)
+	case *hclsyntax.TupleConsExpr:
+		for i, item := range e.Exprs {
+			itemPath := append(slices.Clone(path), strconv.Itoa(i))
+			locations.Set(itemPath, position(item.StartRange().Start))
+			locateExpression(locations, item, itemPath)
+		}
+	case *hclsyntax.ObjectConsExpr:
+		for _, item := range e.Items {
+			key, ok := objectKey(item.KeyExpr)
+			if !ok {
+				continue
+			}
+
+			itemPath := append(slices.Clone(path), key)
+			locations.Set(itemPath, position(item.KeyExpr.StartRange().Start))
+			locateExpression(locations, item.ValueExpr, itemPath)
+		}
+	}
+}
+
+// objectKey returns the key of an object item when it can be determined
+// without evaluating any variables.
+func objectKey(expr hclsyntax.Expression) (string, bool) {
+	if keyword := hcl.ExprAsKeyword(expr); keyword != "" {
+		return keyword, true
+	}
+
+	value, diags := expr.Value(nil)
+	if diags.HasErrors() || !value.IsKnown() || value.IsNull() || value.Type() != cty.String {
+		return "", false
+	}
+
+	return value.AsString(), true
+}
+
+func position(pos hcl.Pos) location.Position {
+	return location.Position{
+		Line:   pos.Line,
+		Column: pos.Column,
+	}
+}
+
+// Suppressions returns the inline suppression directives in the comments of
+// the HCL file, which start with either # or //.
+func (Parser) Suppressions(p []byte) ([][]suppression.Directive, error) {
+	directives, err := suppression.Scan(p, 0, "#", "//")
+	if err != nil {
+		return nil, fmt.Errorf("scan suppressions: %w", err)
+	}
+
+	return [][]suppression.Directive{directives}, nil
+}
@@ -0,0 +1,92 @@
+package hcl2
+
+import (
+	"testing"
+)
+
+func TestLocate(t *testing.T) {
+	input := `
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+}
+
+resource "aws_s3_bucket" "logs" {
+  tags = {
+    owner = "platform"
+  }
+}
+
+locals {
+  ports = [80, 443]
+}
+`
+
+	locations, err := Parser{}.Locate([]byte(input))
+	if err != nil {
+		t.Fatalf("locate: %v", err)
+	}
+
+	tests := []struct {
+		path   string
+		line   int
+		column int
+	}{
+		{path: "/resource/aws_s3_bucket/logs", line: 2, column: 1},
+		{path: "/resource/aws_s3_bucket/logs/0/bucket", line: 3, column: 3},
+		{path: "/resource/aws_s3_bucket/logs/1", line: 6, column: 1},
+		{path: "/resource/aws_s3_bucket/logs/1/tags/owner", line: 8, column: 5},
+		{path: "/locals/0/ports/1", line: 13, column: 16},
+	}
+
+	for _, tt := range tests {
+		pos, ok := locations[0][tt.path]
+		if !ok {
+			t.Errorf("path %s not found", tt.path)
+			continue
+		}
+		if pos.Line != tt.line || pos.Column != tt.column {
+			t.Errorf("path %s at %d:%d, want %d:%d", tt.path, pos.Line, pos.Column, tt.line, tt.column)
+		}
+	}
+}
+
+func TestFix(t *testing.T) {
+	input := `# Buckets.
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs-${var.env}"
+  acl    = "public-read" # TODO: review
+}
+
+resource "aws_s3_bucket" "tmp" {
+  bucket = "tmp"
+}
+`
+
+	fixed, err := (&Parser{}).Fix([]byte(input), 0, func(value any) (any, error) {
+		buckets := value.(map[string]any)["resource"].(map[string]any)["aws_s3_bucket"].(map[string]any)
+		logs := buckets["logs"].([]any)[0].(map[string]any)
+		logs["acl"] = "private"
+		logs["versioning"] = []any{map[string]any{"enabled": true}}
+		logs["owner"] = "${local.team}"
+		delete(buckets, "tmp")
+		return value, nil
+	})
+	if err != nil {
+		t.Fatalf("fix: %v", err)
+	}
+
+	expected := `# Buckets.
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs-${var.env}"
+  acl    = "private" # TODO: review
+  owner  = local.team
+  versioning {
+    enabled = true
+  }
+}
+
+`
+	if string(fixed) != expected {
+		t.Errorf("unexpected fixed file:\n%s\nwant:\n%s", fixed, expected)
+	}
+}
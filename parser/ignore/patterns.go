@@ -0,0 +1,32 @@
+package ignore
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	ignore "github.com/shteou/go-ignore"
+)
+
+// Patterns returns the patterns of an ignore file, which match the paths
+// within the directory of the given domain, as components of a path relative
+// to the root of the paths to match. Comments and empty lines are skipped.
+func Patterns(p []byte, domain []string) ([]gitignore.Pattern, error) {
+	ignoreEntries, err := ignore.ParseIgnoreBytes(bytes.ReplaceAll(p, []byte("\r\n"), []byte("\n")))
+	if err != nil {
+		return nil, fmt.Errorf("parse ignore bytes: %w", err)
+	}
+
+	var patterns []gitignore.Pattern
+	for _, entry := range ignoreEntries {
+		if entry.Kind != "Path" && entry.Kind != "NegatedPath" {
+			continue
+		}
+
+		// The original line keeps the escaped characters, which the pattern
+		// matches literally, and the ! of negated paths.
+		patterns = append(patterns, gitignore.ParsePattern(entry.Original, domain))
+	}
+
+	return patterns, nil
+}
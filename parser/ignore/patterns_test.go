@@ -0,0 +1,41 @@
+package ignore
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+func TestPatterns(t *testing.T) {
+	sample := "# Generated files\r\nvendor/\r\n*.gen.yaml\r\n\r\n!keep.gen.yaml\r\n\\#literal.yaml\r\n"
+
+	patterns, err := Patterns([]byte(sample), []string{"charts"})
+	if err != nil {
+		t.Fatalf("patterns: %v", err)
+	}
+	if len(patterns) != 4 {
+		t.Fatalf("expected 4 patterns, got %d", len(patterns))
+	}
+
+	matcher := gitignore.NewMatcher(patterns)
+	tests := []struct {
+		path     []string
+		isDir    bool
+		expected bool
+	}{
+		{path: []string{"charts", "vendor"}, isDir: true, expected: true},
+		{path: []string{"charts", "web", "vendor", "values.yaml"}, expected: true},
+		{path: []string{"charts", "vendor"}, expected: false},
+		{path: []string{"charts", "crds.gen.yaml"}, expected: true},
+		{path: []string{"charts", "keep.gen.yaml"}, expected: false},
+		{path: []string{"charts", "#literal.yaml"}, expected: true},
+		{path: []string{"crds.gen.yaml"}, expected: false},
+		{path: []string{"charts", "values.yaml"}, expected: false},
+	}
+
+	for _, test := range tests {
+		if got := matcher.Match(test.path, test.isDir); got != test.expected {
+			t.Errorf("Match(%v, %v) = %v, want %v", test.path, test.isDir, got, test.expected)
+		}
+	}
+}
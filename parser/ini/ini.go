@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"github.com/go-ini/ini"
+	"github.com/open-policy-agent/conftest/parser/suppression"
 )
 
 // Parser is an INI parser.
@@ -70,3 +71,14 @@ func isBooleanLiteral(b string) bool {
 	_, err := strconv.ParseBool(b)
 	return err == nil
 }
+
+// Suppressions returns the inline suppression directives in the comments of
+// the INI file, which start with either ; or #.
+func (i *Parser) Suppressions(p []byte) ([][]suppression.Directive, error) {
+	directives, err := suppression.Scan(p, 0, ";", "#")
+	if err != nil {
+		return nil, fmt.Errorf("scan suppressions: %w", err)
+	}
+
+	return [][]suppression.Directive{directives}, nil
+}
@@ -0,0 +1,198 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/parser/fix"
+	yamlv3 "go.yaml.in/yaml/v3"
+)
+
+// Fix replaces the JSON document with the value returned by the function. The
+// keys of the objects are written in the order they were declared in, with the
+// indentation the file was written with, and new keys are added after them.
+func (p *Parser) Fix(data []byte, document int, fn func(value any) (any, error)) ([]byte, error) {
+	if document != 0 {
+		return nil, fmt.Errorf("document %d not found, a JSON file has a single document", document)
+	}
+
+	var bom []byte
+	if len(data) > 2 && data[0] == 0xef && data[1] == 0xbb && data[2] == 0xbf {
+		bom, data = data[:3], data[3:]
+	}
+
+	// The function is given its own copy of the document, which it may change.
+	var current, input any
+	if err := json.Unmarshal(data, &current); err != nil {
+		return nil, fmt.Errorf("unmarshal json: %w", err)
+	}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, fmt.Errorf("unmarshal json: %w", err)
+	}
+
+	value, err := fn(input)
+	if err != nil {
+		return nil, err
+	}
+	if fix.Equal(current, value) {
+		return append(bom, data...), nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	root, err := decodeNode(decoder)
+	if err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+
+	root, err = fix.Update(root, value)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.Write(bom)
+	if err := writeNode(&buf, root, detectIndent(data), ""); err != nil {
+		return nil, fmt.Errorf("write json: %w", err)
+	}
+	if bytes.HasSuffix(bytes.TrimRight(data, " \t"), []byte("\n")) {
+		buf.WriteString("\n")
+	}
+
+	if bytes.Contains(data, []byte("\r\n")) {
+		return bytes.ReplaceAll(buf.Bytes(), []byte("\n"), []byte("\r\n")), nil
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeNode decodes the next JSON value as a YAML node, which keeps the order
+// of the keys of objects.
+func decodeNode(decoder *json.Decoder) (*yamlv3.Node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token := token.(type) {
+	case json.Delim:
+		node := &yamlv3.Node{Kind: yamlv3.MappingNode, Tag: "!!map"}
+		if token == '[' {
+			node = &yamlv3.Node{Kind: yamlv3.SequenceNode, Tag: "!!seq"}
+		}
+
+		for decoder.More() {
+			if node.Kind == yamlv3.MappingNode {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: fmt.Sprint(key), Style: yamlv3.DoubleQuotedStyle})
+			}
+
+			child, err := decodeNode(decoder)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+
+		// Consume the closing delimiter.
+		if _, err := decoder.Token(); err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		return node, nil
+	case string:
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: token, Style: yamlv3.DoubleQuotedStyle}, nil
+	case json.Number:
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Value: token.String()}, nil
+	case bool:
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(token)}, nil
+	default:
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+}
+
+// writeNode writes the node as JSON. Objects and arrays are written on
+// multiple lines unless the indent is empty.
+func writeNode(buf *bytes.Buffer, node *yamlv3.Node, indent, prefix string) error {
+	switch node.Kind {
+	case yamlv3.MappingNode, yamlv3.SequenceNode:
+		open, closing, step := "{", "}", 2
+		if node.Kind == yamlv3.SequenceNode {
+			open, closing, step = "[", "]", 1
+		}
+
+		buf.WriteString(open)
+		if len(node.Content) == 0 {
+			buf.WriteString(closing)
+			return nil
+		}
+
+		for i := 0; i < len(node.Content); i += step {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			if indent != "" {
+				buf.WriteString("\n" + prefix + indent)
+			}
+
+			if step == 2 {
+				key, err := json.Marshal(node.Content[i].Value)
+				if err != nil {
+					return err
+				}
+				buf.Write(key)
+				buf.WriteString(":")
+				if indent != "" {
+					buf.WriteString(" ")
+				}
+			}
+
+			if err := writeNode(buf, node.Content[i+step-1], indent, prefix+indent); err != nil {
+				return err
+			}
+		}
+
+		if indent != "" {
+			buf.WriteString("\n" + prefix)
+		}
+		buf.WriteString(closing)
+	case yamlv3.ScalarNode:
+		switch node.Tag {
+		case "!!str":
+			value, err := json.Marshal(node.Value)
+			if err != nil {
+				return err
+			}
+			buf.Write(value)
+		case "!!null":
+			buf.WriteString("null")
+		default:
+			buf.WriteString(node.Value)
+		}
+	default:
+		return fmt.Errorf("unsupported node kind %d", node.Kind)
+	}
+
+	return nil
+}
+
+// detectIndent returns the indentation of the first line that is indented, or
+// an empty string when the JSON is written on a single line.
+func detectIndent(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+
+	if bytes.Contains(bytes.TrimSpace(data), []byte("\n")) {
+		return "  "
+	}
+	return ""
+}
@@ -1,12 +1,22 @@
 package json
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/open-policy-agent/conftest/parser/location"
+	"github.com/open-policy-agent/conftest/parser/strict"
 )
 
 // Parser is a JSON parser.
-type Parser struct{}
+type Parser struct {
+	// Strict reports the duplicate keys of the objects with CheckStrict.
+	Strict bool
+}
 
 // Unmarshal unmarshals JSON files.
 func (p *Parser) Unmarshal(data []byte, v any) error {
@@ -20,3 +30,153 @@ func (p *Parser) Unmarshal(data []byte, v any) error {
 
 	return nil
 }
+
+// Locate returns the position of every value in the JSON file. As a JSON
+// file always contains a single document, a single map is returned.
+func (p *Parser) Locate(data []byte) ([]location.Map, error) {
+	l := newLocator(data)
+	if err := l.value(nil); err != nil {
+		return nil, fmt.Errorf("locate json: %w", err)
+	}
+
+	return []location.Map{l.locations}, nil
+}
+
+// CheckStrict returns the keys declared more than once in the same object,
+// which encoding/json silently replaces with their last value. Nothing is
+// returned unless the parser is strict.
+func (p *Parser) CheckStrict(data []byte) ([][]strict.Finding, error) {
+	if !p.Strict {
+		return nil, nil
+	}
+
+	l := newLocator(data)
+	if err := l.value(nil); err != nil {
+		return nil, fmt.Errorf("check json: %w", err)
+	}
+
+	return [][]strict.Finding{l.duplicates}, nil
+}
+
+type locator struct {
+	data       []byte
+	bomLength  int
+	decoder    *json.Decoder
+	lineStarts []int
+	locations  location.Map
+	duplicates []strict.Finding
+}
+
+func newLocator(data []byte) *locator {
+	var bomLength int
+	if len(data) > 2 && data[0] == 0xef && data[1] == 0xbb && data[2] == 0xbf {
+		bomLength = 3
+	}
+
+	return &locator{
+		data:       data,
+		bomLength:  bomLength,
+		decoder:    json.NewDecoder(bytes.NewReader(data[bomLength:])),
+		lineStarts: lineStarts(data),
+		locations:  make(location.Map),
+		duplicates: []strict.Finding{},
+	}
+}
+
+// token reads the next token along with the position it starts at. The
+// decoder only reports the offset after a token, so the start is found by
+// skipping the whitespace and delimiters that precede it.
+func (l *locator) token() (json.Token, location.Position, error) {
+	offset := l.bomLength + int(l.decoder.InputOffset())
+	for offset < len(l.data) && bytes.IndexByte([]byte(" \t\r\n,:"), l.data[offset]) >= 0 {
+		offset++
+	}
+
+	token, err := l.decoder.Token()
+	if err != nil {
+		return nil, location.Position{}, err
+	}
+
+	return token, l.position(offset), nil
+}
+
+func (l *locator) position(offset int) location.Position {
+	line := sort.Search(len(l.lineStarts), func(i int) bool { return l.lineStarts[i] > offset })
+	return location.Position{
+		Line:   line,
+		Column: offset - l.lineStarts[line-1] + 1,
+	}
+}
+
+func (l *locator) value(path []string) error {
+	token, pos, err := l.token()
+	if err != nil {
+		return err
+	}
+	l.locations.Set(path, pos)
+
+	return l.container(token, path)
+}
+
+// container walks the contents of an object or array that was opened by the
+// given token. Keys of objects are recorded at the position of the key itself.
+func (l *locator) container(token json.Token, path []string) error {
+	switch token {
+	case json.Delim('{'):
+		declared := make(map[string]location.Position)
+		for l.decoder.More() {
+			key, pos, err := l.token()
+			if err != nil {
+				return err
+			}
+
+			keyPath := append(append([]string{}, path...), fmt.Sprint(key))
+			l.locations.Set(keyPath, pos)
+
+			if first, ok := declared[fmt.Sprint(key)]; ok {
+				l.duplicates = append(l.duplicates, strict.Finding{
+					Kind:     strict.DuplicateKey,
+					Path:     keyPath,
+					Position: pos,
+					Message:  fmt.Sprintf("key %q is declared on line %d and again on line %d, which overrides it", key, first.Line, pos.Line),
+				})
+			} else {
+				declared[fmt.Sprint(key)] = pos
+			}
+
+			value, _, err := l.token()
+			if err != nil {
+				return err
+			}
+			if err := l.container(value, keyPath); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		for i := 0; l.decoder.More(); i++ {
+			if err := l.value(append(append([]string{}, path...), strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	default:
+		return nil
+	}
+
+	// Consume the closing delimiter.
+	if _, err := l.decoder.Token(); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+func lineStarts(data []byte) []int {
+	starts := []int{0}
+	for i, b := range data {
+		if b == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+
+	return starts
+}
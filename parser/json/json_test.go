@@ -2,6 +2,9 @@ package json
 
 import (
 	"testing"
+
+	"github.com/open-policy-agent/conftest/parser/location"
+	"github.com/open-policy-agent/conftest/parser/strict"
 )
 
 func TestJSONParser(t *testing.T) {
@@ -82,3 +85,130 @@ func TestJSONParserWithBOM(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONLocate(t *testing.T) {
+	parser := &Parser{}
+	sample := "\xef\xbb\xbf" + `{
+  "name": "conftest-example",
+  "scripts": {
+    "test": "exit 1"
+  },
+  "files": ["a.js", {"b": true}]
+}`
+
+	locations, err := parser.Locate([]byte(sample))
+	if err != nil {
+		t.Fatalf("locate: %v", err)
+	}
+
+	tests := []struct {
+		path   string
+		line   int
+		column int
+	}{
+		{path: "", line: 1, column: 4},
+		{path: "/name", line: 2, column: 3},
+		{path: "/scripts/test", line: 4, column: 5},
+		{path: "/files/1", line: 6, column: 21},
+		{path: "/files/1/b", line: 6, column: 22},
+	}
+
+	for _, tt := range tests {
+		pos, ok := locations[0][tt.path]
+		if !ok {
+			t.Errorf("path %q not found", tt.path)
+			continue
+		}
+		if pos.Line != tt.line || pos.Column != tt.column {
+			t.Errorf("path %q at %d:%d, want %d:%d", tt.path, pos.Line, pos.Column, tt.line, tt.column)
+		}
+	}
+}
+
+func TestJSONCheckStrict(t *testing.T) {
+	sample := `{
+  "name": "conftest-example",
+  "scripts": {
+    "test": "jest",
+    "test": "vitest"
+  },
+  "files": [{"a": 1, "a": 2}, {"a": 3}],
+  "name": "other"
+}`
+
+	findings, err := (&Parser{}).CheckStrict([]byte(sample))
+	if err != nil {
+		t.Fatalf("check strict: %v", err)
+	}
+	if findings != nil {
+		t.Errorf("expected no findings unless strict, got %v", findings)
+	}
+
+	findings, err = (&Parser{Strict: true}).CheckStrict([]byte(sample))
+	if err != nil {
+		t.Fatalf("check strict: %v", err)
+	}
+
+	expected := []struct {
+		path string
+		line int
+	}{
+		{path: "/scripts/test", line: 5},
+		{path: "/files/0/a", line: 7},
+		{path: "/name", line: 8},
+	}
+	if len(findings) != 1 || len(findings[0]) != len(expected) {
+		t.Fatalf("expected %d findings, got %v", len(expected), findings)
+	}
+	for i, tt := range expected {
+		finding := findings[0][i]
+		if finding.Kind != strict.DuplicateKey || location.Pointer(finding.Path) != tt.path || finding.Position.Line != tt.line {
+			t.Errorf("finding %d: got %s at %s on line %d, want a duplicate key at %s on line %d",
+				i, finding.Kind, location.Pointer(finding.Path), finding.Position.Line, tt.path, tt.line)
+		}
+	}
+}
+
+func TestJSONFix(t *testing.T) {
+	tests := []struct {
+		desc     string
+		input    string
+		fn       func(value any) (any, error)
+		expected string
+	}{
+		{
+			desc:  "key order and indentation are kept",
+			input: "{\n    \"name\": \"web\",\n    \"private\": false,\n    \"scripts\": {\"start\": \"node index.js\"},\n    \"version\": 1.0\n}\n",
+			fn: func(value any) (any, error) {
+				m := value.(map[string]any)
+				m["private"] = true
+				m["license"] = "MIT"
+				delete(m, "scripts")
+				return m, nil
+			},
+			expected: "{\n    \"name\": \"web\",\n    \"private\": true,\n    \"version\": 1.0,\n    \"license\": \"MIT\"\n}\n",
+		},
+		{
+			desc:  "single line documents stay on a single line",
+			input: `[{"b":1,"a":[1,2]}]`,
+			fn: func(value any) (any, error) {
+				value.([]any)[0].(map[string]any)["a"] = []any{1, 2, 3}
+				return value, nil
+			},
+			expected: `[{"b":1,"a":[1,2,3]}]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			fixed, err := (&Parser{}).Fix([]byte(test.input), 0, test.fn)
+			if err != nil {
+				t.Fatalf("fix: %v", err)
+			}
+
+			if string(fixed) != test.expected {
+				t.Errorf("unexpected fixed document:\n%s\nwant:\n%s", fixed, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// Package kubernetes parses Kubernetes manifests, identifying the objects they
+// describe and validating them against the schemas of their kinds.
+package kubernetes
+
+import (
+	"io"
+
+	"github.com/open-policy-agent/conftest/parser/location"
+	"github.com/open-policy-agent/conftest/parser/strict"
+	"github.com/open-policy-agent/conftest/parser/suppression"
+	"github.com/open-policy-agent/conftest/parser/yaml"
+)
+
+// Parser is a Kubernetes manifest parser. Manifests are parsed in the same way
+// as any other YAML file, and the objects they describe are identified and,
+// when a schema directory is set, validated against the schemas of their kinds.
+type Parser struct {
+	// SchemaDir is the directory holding the JSON schemas of the kinds, see
+	// schemaPaths. Documents are not validated when it is empty.
+	SchemaDir string
+
+	yaml yaml.Parser
+}
+
+// Object identifies a Kubernetes object.
+type Object struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+// String returns the kind of the object followed by its name, prefixed by its
+// namespace when it has one, e.g. Deployment default/web.
+func (o Object) String() string {
+	name := o.Name
+	if o.Namespace != "" {
+		name = o.Namespace + "/" + name
+	}
+
+	return o.Kind + " " + name
+}
+
+// Unmarshal unmarshals Kubernetes manifests.
+func (p *Parser) Unmarshal(data []byte, v any) error {
+	return p.yaml.Unmarshal(data, v)
+}
+
+// Locate returns the position of every value in the documents of the manifest.
+func (p *Parser) Locate(data []byte) ([]location.Map, error) {
+	return p.yaml.Locate(data)
+}
+
+// Suppressions returns the inline suppression directives in the comments of
+// every document of the manifest.
+func (p *Parser) Suppressions(data []byte) ([][]suppression.Directive, error) {
+	return p.yaml.Suppressions(data)
+}
+
+// Stream decodes the documents of the manifest read from r one at a time, see
+// yaml.Parser.Stream.
+func (p *Parser) Stream(r io.Reader, fn func(document any, locate func() location.Map, suppressions []suppression.Directive, findings []strict.Finding) error) error {
+	return p.yaml.Stream(r, fn)
+}
+
+// Identify returns the object described by every document of the parsed
+// configuration, or nil for the documents that do not describe an object.
+func (p *Parser) Identify(config any) []*Object {
+	docs := documents(config)
+
+	objects := make([]*Object, len(docs))
+	for i, document := range docs {
+		objects[i] = identify(document)
+	}
+
+	return objects
+}
+
+// identify returns the object described by the document, which must declare
+// both its apiVersion and its kind.
+func identify(document any) *Object {
+	manifest, ok := document.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	apiVersion, _ := manifest["apiVersion"].(string)
+	kind, _ := manifest["kind"].(string)
+	if apiVersion == "" || kind == "" {
+		return nil
+	}
+
+	metadata, _ := manifest["metadata"].(map[string]any)
+	name, _ := metadata["name"].(string)
+	namespace, _ := metadata["namespace"].(string)
+
+	return &Object{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Namespace:  namespace,
+		Name:       name,
+	}
+}
+
+// documents returns the documents of a parsed configuration in the order they
+// are checked in: the documents of a multi-document file one by one, or the
+// configuration itself.
+func documents(config any) []any {
+	if docs, ok := config.([]any); ok {
+		return docs
+	}
+
+	return []any{config}
+}
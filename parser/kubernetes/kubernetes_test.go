@@ -0,0 +1,152 @@
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIdentify(t *testing.T) {
+	manifest := []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: default
+---
+name: not-an-object
+`)
+
+	p := &Parser{}
+
+	var config any
+	if err := p.Unmarshal(manifest, &config); err != nil {
+		t.Fatalf("Unmarshal: unexpected error %v", err)
+	}
+
+	want := []*Object{
+		{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "web"},
+		{APIVersion: "v1", Kind: "Namespace", Name: "default"},
+		nil,
+	}
+	if got := p.Identify(config); !reflect.DeepEqual(got, want) {
+		t.Errorf("Identify: got %v, want %v", got, want)
+	}
+
+	if got := want[0].String(); got != "Deployment default/web" {
+		t.Errorf("String: got %q, want %q", got, "Deployment default/web")
+	}
+	if got := want[1].String(); got != "Namespace default" {
+		t.Errorf("String: got %q, want %q", got, "Namespace default")
+	}
+}
+
+func TestSchemaPaths(t *testing.T) {
+	tests := []struct {
+		object Object
+		want   []string
+	}{
+		{
+			object: Object{APIVersion: "v1", Kind: "Service"},
+			want:   []string{"service-v1.json"},
+		},
+		{
+			object: Object{APIVersion: "apps/v1", Kind: "Deployment"},
+			want:   []string{"deployment-apps-v1.json", filepath.Join("apps", "deployment_v1.json")},
+		},
+		{
+			object: Object{APIVersion: "cert-manager.io/v1", Kind: "Certificate"},
+			want: []string{
+				"certificate-cert-manager-v1.json",
+				"certificate-cert-manager.io-v1.json",
+				filepath.Join("cert-manager.io", "certificate_v1.json"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.object.APIVersion, func(t *testing.T) {
+			if got := schemaPaths(tt.object); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	schema := `{
+  "type": "object",
+  "properties": {
+    "spec": {
+      "type": "object",
+      "required": ["selector"],
+      "properties": {
+        "replicas": {"type": "integer"}
+      }
+    }
+  }
+}`
+	if err := os.WriteFile(filepath.Join(dir, "deployment-apps-v1.json"), []byte(schema), 0o600); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+	schemaPath := filepath.Join(dir, "deployment-apps-v1.json")
+
+	config := []any{
+		map[string]any{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"spec":       map[string]any{"selector": map[string]any{}, "replicas": 2},
+		},
+		map[string]any{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"spec":       map[string]any{"replicas": "2"},
+		},
+		map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+		},
+		map[string]any{
+			"name": "not-an-object",
+		},
+	}
+
+	t.Run("without a schema directory", func(t *testing.T) {
+		validations, err := (&Parser{}).Validate(config)
+		if err != nil {
+			t.Fatalf("Validate: unexpected error %v", err)
+		}
+		if validations != nil {
+			t.Errorf("expected no validations, got %v", validations)
+		}
+	})
+
+	t.Run("with a schema directory", func(t *testing.T) {
+		validations, err := (&Parser{SchemaDir: dir}).Validate(config)
+		if err != nil {
+			t.Fatalf("Validate: unexpected error %v", err)
+		}
+
+		want := []*Validation{
+			{Schema: schemaPath},
+			{
+				Schema: schemaPath,
+				Errors: []SchemaError{
+					{Path: []string{"spec"}, Message: "missing property 'selector'"},
+					{Path: []string{"spec", "replicas"}, Message: "got string, want integer"},
+				},
+			},
+			{},
+			nil,
+		}
+		if !reflect.DeepEqual(validations, want) {
+			t.Errorf("got %+v, want %+v", validations, want)
+		}
+	})
+}
@@ -0,0 +1,179 @@
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Validation is the result of validating a document against the schema of the
+// kind of object it describes.
+type Validation struct {
+	// Schema is the path of the schema the document was validated against,
+	// or empty when there is no schema for its kind.
+	Schema string
+
+	Errors []SchemaError
+}
+
+// SchemaError describes a value of a document that does not match its schema.
+type SchemaError struct {
+	// Path is the path of the value within the document.
+	Path    []string
+	Message string
+}
+
+// Validate validates every document of the parsed configuration against the
+// schema of its kind. Nothing is validated when no schema directory is set,
+// and the validation of a document that does not describe an object is nil.
+func (p *Parser) Validate(config any) ([]*Validation, error) {
+	if p.SchemaDir == "" {
+		return nil, nil
+	}
+
+	docs := documents(config)
+
+	validations := make([]*Validation, len(docs))
+	for i, document := range docs {
+		object := identify(document)
+		if object == nil {
+			continue
+		}
+
+		path, ok := p.schemaPath(*object)
+		if !ok {
+			validations[i] = &Validation{}
+			continue
+		}
+
+		schema, err := compile(path)
+		if err != nil {
+			return nil, fmt.Errorf("compile schema %s: %w", path, err)
+		}
+
+		validations[i] = &Validation{
+			Schema: path,
+			Errors: validate(schema, document),
+		}
+	}
+
+	return validations, nil
+}
+
+// schemaPath returns the path of the schema for the kind of the object, which
+// is the first of its schemaPaths that exists.
+func (p *Parser) schemaPath(object Object) (string, bool) {
+	for _, path := range schemaPaths(object) {
+		path = filepath.Join(p.SchemaDir, path)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+
+	return "", false
+}
+
+// schemaPaths returns the paths a schema for the kind of the object can have
+// within the schema directory. These follow the conventions of the schemas
+// generated by openapi2jsonschema, e.g. deployment-apps-v1.json or
+// service-v1.json, and of the CRDs catalog, e.g.
+// cert-manager.io/certificate_v1.json.
+func schemaPaths(object Object) []string {
+	kind := strings.ToLower(object.Kind)
+
+	group, version, found := strings.Cut(object.APIVersion, "/")
+	if !found {
+		return []string{fmt.Sprintf("%s-%s.json", kind, group)}
+	}
+
+	shortGroup, _, _ := strings.Cut(group, ".")
+	paths := []string{
+		fmt.Sprintf("%s-%s-%s.json", kind, shortGroup, version),
+		fmt.Sprintf("%s-%s-%s.json", kind, group, version),
+		filepath.Join(group, fmt.Sprintf("%s_%s.json", kind, version)),
+	}
+
+	return slices.Compact(paths)
+}
+
+// schemas holds the schemas that have been compiled, as every document of a
+// kind is validated against the same schema.
+var schemas = struct {
+	sync.Mutex
+	compiler *jsonschema.Compiler
+	compiled map[string]*jsonschema.Schema
+}{
+	compiler: jsonschema.NewCompiler(),
+	compiled: make(map[string]*jsonschema.Schema),
+}
+
+// compile compiles the schema at the given path, along with the schemas it
+// refers to. The schemas are always read from the filesystem.
+func compile(path string) (*jsonschema.Schema, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("get absolute path: %w", err)
+	}
+
+	schemas.Lock()
+	defer schemas.Unlock()
+
+	if schema, ok := schemas.compiled[path]; ok {
+		return schema, nil
+	}
+
+	schema, err := schemas.compiler.Compile(path)
+	if err != nil {
+		return nil, err
+	}
+	schemas.compiled[path] = schema
+
+	return schema, nil
+}
+
+var printer = message.NewPrinter(language.English)
+
+// validate returns the errors of the document that does not match the schema.
+// Only the most specific errors are reported, rather than every schema that
+// the value did not match along the way.
+func validate(schema *jsonschema.Schema, document any) []SchemaError {
+	err := schema.Validate(document)
+	if err == nil {
+		return nil
+	}
+
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return []SchemaError{{Path: []string{}, Message: err.Error()}}
+	}
+
+	var errs []SchemaError
+	var collect func(e *jsonschema.ValidationError)
+	collect = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			errs = append(errs, SchemaError{
+				Path:    e.InstanceLocation,
+				Message: e.ErrorKind.LocalizedString(printer),
+			})
+			return
+		}
+
+		for _, cause := range e.Causes {
+			collect(cause)
+		}
+	}
+	collect(validationErr)
+
+	slices.SortStableFunc(errs, func(a, b SchemaError) int {
+		return slices.Compare(a.Path, b.Path)
+	})
+
+	return errs
+}
@@ -0,0 +1,131 @@
+// Package location describes where the values of a parsed configuration
+// were declared in the original source file.
+package location
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Position is a line and column within a source file. Both values
+// start at one.
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Map maps the path of a value within a single document to the position it
+// was declared at. Paths are stored as JSON pointers (RFC 6901), for example
+// "/spec/containers/0/image". The root of the document has the empty path.
+type Map map[string]Position
+
+// Set records the position of the value found at the given path segments.
+// Existing entries are not overwritten, so the first declaration wins.
+func (m Map) Set(segments []string, pos Position) {
+	key := Pointer(segments)
+	if _, ok := m[key]; ok {
+		return
+	}
+
+	m[key] = pos
+}
+
+// Lookup returns the position of the value at the given path segments.
+//
+// When the exact path does not exist in the map, for example because a policy
+// reports a key that is missing from the document, the position of the closest
+// existing parent is returned instead.
+func (m Map) Lookup(segments []string) (Position, bool) {
+	for i := len(segments); i >= 0; i-- {
+		if pos, ok := m[Pointer(segments[:i])]; ok {
+			return pos, true
+		}
+	}
+
+	return Position{}, false
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// Pointer joins the given path segments into a JSON pointer.
+func Pointer(segments []string) string {
+	var b strings.Builder
+	for _, segment := range segments {
+		b.WriteString("/")
+		b.WriteString(pointerEscaper.Replace(segment))
+	}
+
+	return b.String()
+}
+
+// ParsePath converts a path as reported by a policy into its segments.
+//
+// The following forms are supported:
+//
+//	["spec", "containers", 0, "image"]   an array of keys and indices
+//	"/spec/containers/0/image"           a JSON pointer
+//	"spec.containers[0].image"           dotted notation
+func ParsePath(path any) ([]string, error) {
+	switch p := path.(type) {
+	case []any:
+		segments := make([]string, 0, len(p))
+		for _, segment := range p {
+			switch s := segment.(type) {
+			case string:
+				segments = append(segments, s)
+			case json.Number:
+				segments = append(segments, s.String())
+			case int:
+				segments = append(segments, strconv.Itoa(s))
+			case float64:
+				segments = append(segments, strconv.FormatFloat(s, 'f', -1, 64))
+			default:
+				return nil, fmt.Errorf("unsupported path segment %v of type %T", segment, segment)
+			}
+		}
+
+		return segments, nil
+	case string:
+		if p == "" {
+			return []string{}, nil
+		}
+
+		if strings.HasPrefix(p, "/") {
+			segments := strings.Split(p[1:], "/")
+			for i := range segments {
+				segments[i] = pointerUnescaper.Replace(segments[i])
+			}
+
+			return segments, nil
+		}
+
+		return parseDotted(p)
+	default:
+		return nil, fmt.Errorf("unsupported path type %T", path)
+	}
+}
+
+func parseDotted(path string) ([]string, error) {
+	var segments []string
+	for _, part := range strings.Split(path, ".") {
+		key, rest, _ := strings.Cut(part, "[")
+		if key != "" {
+			segments = append(segments, key)
+		}
+
+		for rest != "" {
+			index, remainder, ok := strings.Cut(rest, "]")
+			if !ok {
+				return nil, fmt.Errorf("unterminated index in path %q", path)
+			}
+
+			segments = append(segments, strings.Trim(index, `"'`))
+			rest = strings.TrimPrefix(remainder, "[")
+		}
+	}
+
+	return segments, nil
+}
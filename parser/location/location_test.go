@@ -0,0 +1,103 @@
+package location
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    any
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "array of keys and indices",
+			path: []any{"spec", "containers", json.Number("0"), "image"},
+			want: []string{"spec", "containers", "0", "image"},
+		},
+		{
+			name: "json pointer",
+			path: "/metadata/annotations/example.com~1owner",
+			want: []string{"metadata", "annotations", "example.com/owner"},
+		},
+		{
+			name: "dotted notation",
+			path: "spec.containers[0].ports[1].containerPort",
+			want: []string{"spec", "containers", "0", "ports", "1", "containerPort"},
+		},
+		{
+			name: "empty path is the root",
+			path: "",
+			want: []string{},
+		},
+		{
+			name:    "unterminated index",
+			path:    "spec.containers[0",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported type",
+			path:    true,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	locations := make(Map)
+	locations.Set(nil, Position{Line: 1, Column: 1})
+	locations.Set([]string{"spec"}, Position{Line: 2, Column: 1})
+	locations.Set([]string{"spec", "replicas"}, Position{Line: 3, Column: 3})
+
+	// The first declaration of a path is kept.
+	locations.Set([]string{"spec"}, Position{Line: 10, Column: 1})
+
+	tests := []struct {
+		name string
+		path []string
+		want Position
+	}{
+		{
+			name: "exact path",
+			path: []string{"spec", "replicas"},
+			want: Position{Line: 3, Column: 3},
+		},
+		{
+			name: "missing key falls back to its parent",
+			path: []string{"spec", "template", "metadata"},
+			want: Position{Line: 2, Column: 1},
+		},
+		{
+			name: "unknown path falls back to the root",
+			path: []string{"kind"},
+			want: Position{Line: 1, Column: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := locations.Lookup(tt.path)
+			if !ok {
+				t.Fatalf("Lookup() did not find a position")
+			}
+			if got != tt.want {
+				t.Errorf("Lookup() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
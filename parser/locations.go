@@ -0,0 +1,38 @@
+package parser
+
+import (
+	"sync"
+
+	"github.com/open-policy-agent/conftest/parser/location"
+)
+
+// Locations are the locations of the values of the documents of a parsed file.
+// They are only found when they are first needed, as finding them parses the
+// file again, and most files are tested without any result that reports the
+// path of a value. Locations are safe for concurrent use.
+type Locations struct {
+	once   sync.Once
+	locate func() []location.Map
+	maps   []location.Map
+}
+
+// NewLocations returns the locations that are found by the given function
+// when they are first needed.
+func NewLocations(locate func() []location.Map) *Locations {
+	return &Locations{locate: locate}
+}
+
+// Documents returns the locations of every document of the file, or nil when
+// they are unknown.
+func (l *Locations) Documents() []location.Map {
+	if l == nil {
+		return nil
+	}
+
+	l.once.Do(func() {
+		l.maps = l.locate()
+		l.locate = nil
+	})
+
+	return l.maps
+}
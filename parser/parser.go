@@ -13,11 +13,14 @@ import (
 	"github.com/spf13/viper"
 	"golang.org/x/exp/slices"
 
+	"github.com/open-policy-agent/conftest/parser/compose"
 	"github.com/open-policy-agent/conftest/parser/cue"
 	"github.com/open-policy-agent/conftest/parser/cyclonedx"
 	"github.com/open-policy-agent/conftest/parser/docker"
 	dotenv "github.com/open-policy-agent/conftest/parser/dotenv"
 	"github.com/open-policy-agent/conftest/parser/edn"
+	"github.com/open-policy-agent/conftest/parser/githubactions"
+	"github.com/open-policy-agent/conftest/parser/gitlabci"
 	"github.com/open-policy-agent/conftest/parser/groovy"
 	"github.com/open-policy-agent/conftest/parser/hcl1"
 	"github.com/open-policy-agent/conftest/parser/hcl2"
@@ -27,10 +30,15 @@ import (
 	"github.com/open-policy-agent/conftest/parser/json"
 	"github.com/open-policy-agent/conftest/parser/jsonc"
 	"github.com/open-policy-agent/conftest/parser/jsonnet"
+	"github.com/open-policy-agent/conftest/parser/kubernetes"
+	"github.com/open-policy-agent/conftest/parser/location"
 	"github.com/open-policy-agent/conftest/parser/nginx"
 	"github.com/open-policy-agent/conftest/parser/properties"
 	"github.com/open-policy-agent/conftest/parser/spdx"
+	"github.com/open-policy-agent/conftest/parser/strict"
+	"github.com/open-policy-agent/conftest/parser/suppression"
 	"github.com/open-policy-agent/conftest/parser/textproto"
+	"github.com/open-policy-agent/conftest/parser/tfplan"
 	"github.com/open-policy-agent/conftest/parser/toml"
 	"github.com/open-policy-agent/conftest/parser/vcl"
 	"github.com/open-policy-agent/conftest/parser/xml"
@@ -40,28 +48,33 @@ import (
 // The defined parsers are the parsers that are valid for
 // parsing files.
 const (
-	CUE        = "cue"
-	CYCLONEDX  = "cyclonedx"
-	Dockerfile = "dockerfile"
-	EDN        = "edn"
-	GROOVY     = "groovy"
-	HCL1       = "hcl1"
-	HCL2       = "hcl2"
-	HOCON      = "hocon"
-	IGNORE     = "ignore"
-	INI        = "ini"
-	JSON       = "json"
-	JSONC      = "jsonc"
-	JSONNET    = "jsonnet"
-	NGINX      = "nginx"
-	PROPERTIES = "properties"
-	SPDX       = "spdx"
-	TEXTPROTO  = "textproto"
-	TOML       = "toml"
-	VCL        = "vcl"
-	XML        = "xml"
-	YAML       = "yaml"
-	DOTENV     = "dotenv"
+	COMPOSE       = "compose"
+	CUE           = "cue"
+	CYCLONEDX     = "cyclonedx"
+	Dockerfile    = "dockerfile"
+	EDN           = "edn"
+	GITHUBACTIONS = "github-actions"
+	GITLABCI      = "gitlab-ci"
+	GROOVY        = "groovy"
+	HCL1          = "hcl1"
+	HCL2          = "hcl2"
+	HOCON         = "hocon"
+	IGNORE        = "ignore"
+	INI           = "ini"
+	JSON          = "json"
+	JSONC         = "jsonc"
+	JSONNET       = "jsonnet"
+	NGINX         = "nginx"
+	PROPERTIES    = "properties"
+	SPDX          = "spdx"
+	TEXTPROTO     = "textproto"
+	TFPLAN        = "tfplan"
+	KUBERNETES    = "kubernetes"
+	TOML          = "toml"
+	VCL           = "vcl"
+	XML           = "xml"
+	YAML          = "yaml"
+	DOTENV        = "dotenv"
 )
 
 // Parser defines all of the methods that every parser
@@ -77,11 +90,148 @@ type PathAwareParser interface {
 	SetPath(path string)
 }
 
+// SiblingAwareParser is an optional interface that parsers may implement if
+// the values of a file depend on the other files that are parsed along with it.
+type SiblingAwareParser interface {
+	Parser
+	SetSiblings(paths []string)
+}
+
+// Locator is an optional interface that parsers may implement if they are
+// able to report where each value was declared in the source file. One map
+// is returned for every document in the file, in the same order as the
+// documents produced by Unmarshal.
+type Locator interface {
+	Locate(p []byte) ([]location.Map, error)
+}
+
+// Suppressor is an optional interface that parsers may implement if the format
+// supports comments. It returns the inline suppression directives written in
+// the comments of every document in the file, in the same order as the
+// documents produced by Unmarshal.
+type Suppressor interface {
+	Suppressions(p []byte) ([][]suppression.Directive, error)
+}
+
+// Streamer is an optional interface that parsers may implement if they are
+// able to decode the documents of a file one at a time. The function is called
+// with every document, a function that returns the locations of its values, its
+// suppression directives and its strict findings, as soon as it has been
+// decoded. The locations are only found when they are needed, and the findings
+// are nil when the document is not checked.
+type Streamer interface {
+	Stream(r io.Reader, fn func(document any, locate func() location.Map, suppressions []suppression.Directive, findings []strict.Finding) error) error
+}
+
+// StreamFunc is called by StreamConfiguration for every document it decodes,
+// with the details of the document.
+type StreamFunc = func(document any, details DocumentDetails) error
+
+// DocumentDetails describes a single streamed document beyond its contents, in
+// the same way as Details describes the documents of parsed configurations. The
+// objects and validations are those of the document, or of every item when the
+// document is a list, as returned by Identifier and Validator.
+type DocumentDetails struct {
+	Locations    *Locations
+	Suppressions []suppression.Directive
+	Objects      []*kubernetes.Object
+	Validations  []*kubernetes.Validation
+	Findings     []strict.Finding
+}
+
+// Identifier is an optional interface that parsers may implement if the
+// documents they parse describe objects with an identity of their own, such
+// as Kubernetes resources. One object is returned for every document of the
+// parsed configuration, or nil for documents that do not describe an object.
+type Identifier interface {
+	Identify(config any) []*kubernetes.Object
+}
+
+// Validator is an optional interface that parsers may implement if they are
+// able to validate the documents they parse against a schema. One validation
+// is returned for every document of the parsed configuration, or nil for
+// documents that were not validated.
+type Validator interface {
+	Validate(config any) ([]*kubernetes.Validation, error)
+}
+
+// StrictChecker is an optional interface that parsers may implement if they
+// are able to find the values of a file that are valid, but that are likely
+// not to be read as intended, such as keys declared twice. One list of
+// findings is returned for every document in the file, in the same order as
+// the documents produced by Unmarshal, or nil when the file is not checked.
+type StrictChecker interface {
+	CheckStrict(p []byte) ([][]strict.Finding, error)
+}
+
+// Fixer is an optional interface that parsers may implement if they are able
+// to write changed values back to a file, keeping the comments and formatting
+// of the values that did not change. The function is called with the value
+// of the document at the given index, in the same order as the documents
+// produced by Unmarshal, and returns the value to write in its place.
+type Fixer interface {
+	Fix(p []byte, document int, fn func(value any) (any, error)) ([]byte, error)
+}
+
+// Details describes the parsed configurations beyond their contents. Every
+// field is keyed by the path of the file, and holds one entry for every
+// document in the file.
+type Details struct {
+	Locations    map[string]*Locations
+	Suppressions map[string][][]suppression.Directive
+	Objects      map[string][]*kubernetes.Object
+	Validations  map[string][]*kubernetes.Validation
+	Findings     map[string][][]strict.Finding
+
+	// The parsers detected from the contents of the files whose paths do not
+	// tell which parser to use, see NewFromContents. Unlike the other fields,
+	// it holds one entry for every file.
+	Parsers map[string]string
+}
+
+// NewDetails returns details that hold all of the details of the parsed
+// configurations.
+func NewDetails() Details {
+	return Details{
+		Locations:    make(map[string]*Locations),
+		Suppressions: make(map[string][][]suppression.Directive),
+		Objects:      make(map[string][]*kubernetes.Object),
+		Validations:  make(map[string][]*kubernetes.Validation),
+		Findings:     make(map[string][][]strict.Finding),
+		Parsers:      make(map[string]string),
+	}
+}
+
+// Copy sets the details of the file at the given path to the details of the
+// same file in the given details.
+func (d Details) Copy(path string, from Details) {
+	d.Locations[path] = from.Locations[path]
+	d.Suppressions[path] = from.Suppressions[path]
+	d.Objects[path] = from.Objects[path]
+	d.Validations[path] = from.Validations[path]
+	d.Findings[path] = from.Findings[path]
+	if name, ok := from.Parsers[path]; ok {
+		d.Parsers[path] = name
+	}
+}
+
+// Delete deletes the details of the file at the given path.
+func (d Details) Delete(path string) {
+	delete(d.Locations, path)
+	delete(d.Suppressions, path)
+	delete(d.Objects, path)
+	delete(d.Validations, path)
+	delete(d.Findings, path)
+	delete(d.Parsers, path)
+}
+
 // New returns a new Parser.
 func New(parser string) (Parser, error) {
 	switch parser {
 	case TOML:
 		return &toml.Parser{}, nil
+	case COMPOSE:
+		return &compose.Parser{}, nil
 	case CUE:
 		return &cue.Parser{}, nil
 	case INI:
@@ -91,13 +241,21 @@ func New(parser string) (Parser, error) {
 	case HCL1:
 		return &hcl1.Parser{}, nil
 	case HCL2:
-		return &hcl2.Parser{}, nil
+		return &hcl2.Parser{
+			Evaluate: viper.GetBool("hcl2-evaluate"),
+			VarFiles: viper.GetStringSlice("hcl2-var-file"),
+		}, nil
 	case Dockerfile:
 		return &docker.Parser{}, nil
 	case YAML:
-		return &yaml.Parser{}, nil
+		return &yaml.Parser{
+			Metadata: viper.GetBool("yaml-metadata"),
+			Strict:   viper.GetBool("strict-parse"),
+		}, nil
 	case JSON:
-		return &json.Parser{}, nil
+		return &json.Parser{
+			Strict: viper.GetBool("strict-parse"),
+		}, nil
 	case JSONC:
 		return &jsonc.Parser{}, nil
 	case JSONNET:
@@ -106,6 +264,10 @@ func New(parser string) (Parser, error) {
 		return &nginx.Parser{}, nil
 	case EDN:
 		return &edn.Parser{}, nil
+	case GITHUBACTIONS:
+		return &githubactions.Parser{}, nil
+	case GITLABCI:
+		return &gitlabci.Parser{}, nil
 	case GROOVY:
 		return &groovy.Parser{}, nil
 	case VCL:
@@ -122,6 +284,12 @@ func New(parser string) (Parser, error) {
 		return &cyclonedx.Parser{}, nil
 	case DOTENV:
 		return &dotenv.Parser{}, nil
+	case TFPLAN:
+		return &tfplan.Parser{}, nil
+	case KUBERNETES:
+		return &kubernetes.Parser{
+			SchemaDir: viper.GetString("kubernetes-schema-dir"),
+		}, nil
 	case TEXTPROTO:
 		parser := &textproto.Parser{}
 		if dirs := viper.GetStringSlice("proto-file-dirs"); len(dirs) > 0 {
@@ -187,7 +355,17 @@ func NewFromPath(path string) (Parser, error) {
 		return New(Dockerfile)
 	}
 
+	// GitHub Actions workflows are the YAML files in the .github/workflows
+	// directory of a repository, and the GitLab CI/CD configuration of a
+	// repository is its .gitlab-ci.yml file.
 	if fileExtension == "yml" || fileExtension == "yaml" {
+		if filepath.Base(filepath.Dir(path)) == "workflows" && filepath.Base(filepath.Dir(filepath.Dir(path))) == ".github" {
+			return New(GITHUBACTIONS)
+		}
+		if fileName == ".gitlab-ci.yml" || fileName == ".gitlab-ci.yaml" {
+			return New(GITLABCI)
+		}
+
 		return New(YAML)
 	}
 
@@ -221,6 +399,13 @@ func NewFromPath(path string) (Parser, error) {
 		return New(TEXTPROTO)
 	}
 
+	// The parsers that are selected by the names of files are not selected by
+	// an extension of the same name. For example, a .tfplan file is a binary
+	// Terraform plan rather than its JSON representation.
+	if slices.Contains(namedParsers, fileExtension) {
+		return nil, fmt.Errorf("new: unknown parser: %v", fileExtension)
+	}
+
 	parser, err := New(fileExtension)
 	if err != nil {
 		return nil, fmt.Errorf("new: %w", err)
@@ -229,13 +414,20 @@ func NewFromPath(path string) (Parser, error) {
 	return parser, nil
 }
 
+// namedParsers are the parsers that are only selected by the names of files,
+// or explicitly with the parser flag.
+var namedParsers = []string{COMPOSE, GITHUBACTIONS, GITLABCI, KUBERNETES, TFPLAN}
+
 // Parsers returns a list of the supported Parsers.
 func Parsers() []string {
 	parsers := []string{
+		COMPOSE,
 		CUE,
 		CYCLONEDX,
 		Dockerfile,
 		EDN,
+		GITHUBACTIONS,
+		GITLABCI,
 		GROOVY,
 		HCL1,
 		HCL2,
@@ -244,10 +436,12 @@ func Parsers() []string {
 		INI,
 		JSON,
 		JSONNET,
+		KUBERNETES,
 		NGINX,
 		PROPERTIES,
 		SPDX,
 		TEXTPROTO,
+		TFPLAN,
 		TOML,
 		VCL,
 		XML,
@@ -262,14 +456,14 @@ func Parsers() []string {
 // a file that can be parsed.
 func FileSupported(path string) bool {
 	_, err := NewFromPath(path)
-	return err == nil
+	return err == nil || detectable(path)
 }
 
 // ParseConfigurations parses and returns the configurations from the given
 // list of files. The result will be a map where the key is the file name of
 // the configuration.
 func ParseConfigurations(files []string) (map[string]any, error) {
-	configurations, err := parseConfigurations(files, "")
+	configurations, err := parseConfigurations(files, "", Details{})
 	if err != nil {
 		return nil, err
 	}
@@ -281,7 +475,7 @@ func ParseConfigurations(files []string) (map[string]any, error) {
 // configurations given in the file list. The result will be a map where the key
 // is the file name of the configuration.
 func ParseConfigurationsAs(files []string, parser string) (map[string]any, error) {
-	configurations, err := parseConfigurations(files, parser)
+	configurations, err := parseConfigurations(files, parser, Details{})
 	if err != nil {
 		return nil, err
 	}
@@ -289,23 +483,108 @@ func ParseConfigurationsAs(files []string, parser string) (map[string]any, error
 	return configurations, nil
 }
 
+// ParseConfigurationsWithLocations parses the configurations in the same way as
+// ParseConfigurationsAs, and additionally returns the source locations of the
+// values in every file whose parser implements Locator, which are found when
+// they are first needed. When parser is empty, the parser is inferred from the
+// path of each file.
+func ParseConfigurationsWithLocations(files []string, parser string) (map[string]any, map[string]*Locations, error) {
+	details := Details{
+		Locations: make(map[string]*Locations),
+	}
+	configurations, err := parseConfigurations(files, parser, details)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return configurations, details.Locations, nil
+}
+
+// ParseConfigurationsWithParsers parses the configurations in the same way as
+// ParseConfigurationsAs, and additionally returns the parsers that were
+// detected from the contents of the files, keyed by their paths.
+func ParseConfigurationsWithParsers(files []string, parser string) (map[string]any, map[string]string, error) {
+	details := Details{
+		Parsers: make(map[string]string),
+	}
+	configurations, err := parseConfigurations(files, parser, details)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return configurations, details.Parsers, nil
+}
+
+// ParseConfigurationsWithDetails parses the configurations in the same way as
+// ParseConfigurationsAs, and additionally returns the source locations of the
+// values in every file whose parser implements Locator, the inline suppression
+// directives of every file whose parser implements Suppressor, and the objects
+// and their validations of every file whose parser implements Identifier and
+// Validator respectively.
+func ParseConfigurationsWithDetails(files []string, parser string) (map[string]any, Details, error) {
+	details := NewDetails()
+	configurations, err := parseConfigurations(files, parser, details)
+	if err != nil {
+		return nil, Details{}, err
+	}
+
+	return configurations, details, nil
+}
+
+// ParseContentsWithDetails parses the given contents in the same way as
+// ParseConfigurationsWithDetails, for configurations that are not read from
+// the files they are named after, such as rendered templates. The contents are
+// keyed by the name of their configuration.
+func ParseContentsWithDetails(contents map[string][]byte, parser string) (map[string]any, Details, error) {
+	paths := make([]string, 0, len(contents))
+	for path := range contents {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	details := NewDetails()
+	configurations := make(map[string]any, len(contents))
+	for _, path := range paths {
+		parsed, err := parseConfiguration(path, contents[path], paths, parser, details)
+		if err != nil {
+			return nil, Details{}, err
+		}
+
+		configurations[path] = parsed
+	}
+
+	return configurations, details, nil
+}
+
 // CombineConfigurations takes the given configurations and combines them into a single
 // configuration. The result will be a map that contains a single key with a value of
 // Combined.
 func CombineConfigurations(configs map[string]any) map[string]any {
+	return CombineConfigurationsWithObjects(configs, nil)
+}
+
+// CombineConfigurationsWithObjects combines the configurations in the same way as
+// CombineConfigurations, and additionally includes the object described by every
+// document that has one, as returned by ParseConfigurationsWithDetails, so that the
+// documents can be referred to by their identity.
+func CombineConfigurationsWithObjects(configs map[string]any, objects map[string][]*kubernetes.Object) map[string]any {
 	type configuration struct {
-		Path     string `json:"path"`
-		Contents any    `json:"contents"`
+		Path     string             `json:"path"`
+		Contents any                `json:"contents"`
+		Object   *kubernetes.Object `json:"object,omitempty"`
 	}
 
 	var allConfigurations []configuration
 	for path, config := range configs {
 		if subconfigs, exist := config.([]any); exist {
-			for _, subconfig := range subconfigs {
+			for i, subconfig := range subconfigs {
 				configuration := configuration{
 					Path:     path,
 					Contents: subconfig,
 				}
+				if i < len(objects[path]) {
+					configuration.Object = objects[path][i]
+				}
 
 				allConfigurations = append(allConfigurations, configuration)
 			}
@@ -316,6 +595,9 @@ func CombineConfigurations(configs map[string]any) map[string]any {
 			Path:     path,
 			Contents: config,
 		}
+		if len(objects[path]) > 0 {
+			configuration.Object = objects[path][0]
+		}
 
 		allConfigurations = append(allConfigurations, configuration)
 	}
@@ -332,36 +614,92 @@ func CombineConfigurations(configs map[string]any) map[string]any {
 	return combinedConfigurations
 }
 
-func parseConfigurations(paths []string, parser string) (map[string]any, error) {
-	parsedConfigurations := make(map[string]any)
-	errWithPathInfo := func(err error, msg, path string) error {
-		return fmt.Errorf("%s: %w, path: %s", msg, err, path)
+// Streamable returns true if the file at the given path can be decoded one
+// document at a time with StreamConfiguration. When parser is empty, the parser
+// is inferred from the path of the file.
+func Streamable(path string, parser string) bool {
+	fileParser, err := newParser(path, parser)
+	if err != nil {
+		return false
 	}
-	for _, path := range paths {
-		var fileParser Parser
-		var err error
-		if parser == "" {
-			fileParser, err = NewFromPath(path)
-		} else {
-			fileParser, err = New(parser)
-		}
+
+	_, ok := fileParser.(Streamer)
+	return ok
+}
+
+// StreamConfiguration decodes the configuration at the given path one document
+// at a time, calling fn with every document as soon as it has been decoded. An
+// error is returned if the parser of the file does not implement Streamer.
+func StreamConfiguration(path string, parser string, fn StreamFunc) error {
+	fileParser, err := newParser(path, parser)
+	if err != nil {
+		return fmt.Errorf("new parser: %w, path: %s", err, path)
+	}
+
+	streamer, ok := fileParser.(Streamer)
+	if !ok {
+		return fmt.Errorf("parser does not support streaming, path: %s", path)
+	}
+
+	var reader io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
 		if err != nil {
-			return nil, errWithPathInfo(err, "new parser", path)
+			return fmt.Errorf("open file: %w, path: %s", err, path)
+		}
+		defer file.Close()
+
+		reader = file
+	}
+
+	decoded := func(document any, locate func() location.Map, suppressions []suppression.Directive, findings []strict.Finding) error {
+		details := DocumentDetails{
+			Locations: NewLocations(func() []location.Map {
+				return []location.Map{locate()}
+			}),
+			Suppressions: suppressions,
+			Findings:     findings,
+		}
+		if i, ok := fileParser.(Identifier); ok {
+			details.Objects = i.Identify(document)
+		}
+		if v, ok := fileParser.(Validator); ok {
+			validations, err := v.Validate(document)
+			if err != nil {
+				return fmt.Errorf("validate: %w", err)
+			}
+			details.Validations = validations
 		}
 
+		return fn(document, details)
+	}
+
+	if err := streamer.Stream(reader, decoded); err != nil {
+		return fmt.Errorf("parser stream: %w, path: %s", err, path)
+	}
+
+	return nil
+}
+
+func newParser(path string, parser string) (Parser, error) {
+	if parser == "" {
+		return NewFromPath(path)
+	}
+
+	return New(parser)
+}
+
+func parseConfigurations(paths []string, parser string, details Details) (map[string]any, error) {
+	parsedConfigurations := make(map[string]any)
+	for _, path := range paths {
 		contents, err := getConfigurationContent(path)
 		if err != nil {
 			return nil, errWithPathInfo(err, "get configuration content", path)
 		}
 
-		// If our parser needs the path, give it the path
-		if p, ok := fileParser.(PathAwareParser); ok {
-			p.SetPath(path)
-		}
-
-		var parsed any
-		if err := fileParser.Unmarshal(contents, &parsed); err != nil {
-			return nil, errWithPathInfo(err, "parser unmarshal", path)
+		parsed, err := parseConfiguration(path, contents, paths, parser, details)
+		if err != nil {
+			return nil, err
 		}
 
 		parsedConfigurations[path] = parsed
@@ -370,6 +708,90 @@ func parseConfigurations(paths []string, parser string) (map[string]any, error)
 	return parsedConfigurations, nil
 }
 
+// parseConfiguration parses the contents of the file at the given path, which
+// is parsed along with the given paths, and adds its details.
+func parseConfiguration(path string, contents []byte, paths []string, parser string, details Details) (any, error) {
+	var fileParser Parser
+	var err error
+	if parser == "" {
+		var detected string
+		fileParser, detected, err = NewFromContents(path, contents)
+		if detected != "" && details.Parsers != nil {
+			details.Parsers[path] = detected
+		}
+	} else {
+		fileParser, err = New(parser)
+	}
+	if err != nil {
+		return nil, errWithPathInfo(err, "new parser", path)
+	}
+
+	// If our parser needs the path, give it the path
+	if p, ok := fileParser.(PathAwareParser); ok {
+		p.SetPath(path)
+	}
+	if p, ok := fileParser.(SiblingAwareParser); ok {
+		p.SetSiblings(paths)
+	}
+
+	var parsed any
+	if err := fileParser.Unmarshal(contents, &parsed); err != nil {
+		return nil, errWithPathInfo(err, "parser unmarshal", path)
+	}
+
+	// Locations are a best effort addition to the parsed configuration, so a
+	// file that parsed successfully is never rejected because of them. They are
+	// only found when a result reports the path of a value, see Locations.
+	if l, ok := fileParser.(Locator); ok && details.Locations != nil {
+		details.Locations[path] = NewLocations(func() []location.Map {
+			fileLocations, err := l.Locate(contents)
+			if err != nil {
+				return nil
+			}
+			return fileLocations
+		})
+	}
+
+	// Unlike locations, a malformed suppression directive is an error, as
+	// silently ignoring it would report the findings it was meant to suppress.
+	// The comments are only scanned when the file mentions a directive.
+	if s, ok := fileParser.(Suppressor); ok && details.Suppressions != nil && suppression.Mentioned(contents) {
+		fileSuppressions, err := s.Suppressions(contents)
+		if err != nil {
+			return nil, errWithPathInfo(err, "parse suppressions", path)
+		}
+		details.Suppressions[path] = fileSuppressions
+	}
+
+	if i, ok := fileParser.(Identifier); ok && details.Objects != nil {
+		details.Objects[path] = i.Identify(parsed)
+	}
+
+	if v, ok := fileParser.(Validator); ok && details.Validations != nil {
+		fileValidations, err := v.Validate(parsed)
+		if err != nil {
+			return nil, errWithPathInfo(err, "validate", path)
+		}
+		details.Validations[path] = fileValidations
+	}
+
+	if c, ok := fileParser.(StrictChecker); ok && details.Findings != nil {
+		fileFindings, err := c.CheckStrict(contents)
+		if err != nil {
+			return nil, errWithPathInfo(err, "check strict", path)
+		}
+		if fileFindings != nil {
+			details.Findings[path] = fileFindings
+		}
+	}
+
+	return parsed, nil
+}
+
+func errWithPathInfo(err error, msg, path string) error {
+	return fmt.Errorf("%s: %w, path: %s", msg, err, path)
+}
+
 func getConfigurationContent(path string) ([]byte, error) {
 	if path == "-" {
 		contents, err := io.ReadAll(bufio.NewReader(os.Stdin))
@@ -7,6 +7,8 @@ import (
 
 	"github.com/open-policy-agent/conftest/parser/docker"
 	dotenv "github.com/open-policy-agent/conftest/parser/dotenv"
+	"github.com/open-policy-agent/conftest/parser/githubactions"
+	"github.com/open-policy-agent/conftest/parser/gitlabci"
 	"github.com/open-policy-agent/conftest/parser/groovy"
 	"github.com/open-policy-agent/conftest/parser/hcl2"
 	"github.com/open-policy-agent/conftest/parser/ignore"
@@ -152,6 +154,51 @@ func TestNewFromPath(t *testing.T) {
 			&textproto.Parser{},
 			false,
 		},
+		{
+			"tfplan.json",
+			&json.Parser{},
+			false,
+		},
+		{
+			"prod.tfplan.json",
+			&json.Parser{},
+			false,
+		},
+		{
+			"plan.json",
+			&json.Parser{},
+			false,
+		},
+		{
+			"gke-plan.tfplan",
+			nil,
+			true,
+		},
+		{
+			"manifest.kubernetes",
+			nil,
+			true,
+		},
+		{
+			".github/workflows/ci.yml",
+			&githubactions.Parser{},
+			false,
+		},
+		{
+			"repo/.github/workflows/release.yaml",
+			&githubactions.Parser{},
+			false,
+		},
+		{
+			"workflows/ci.yml",
+			&yaml.Parser{},
+			false,
+		},
+		{
+			".gitlab-ci.yml",
+			&gitlabci.Parser{},
+			false,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -0,0 +1,39 @@
+// Package strict describes the values of parsed configurations that are valid,
+// but that are likely not to be read as their authors intended, such as keys
+// declared twice or YAML 1.1 booleans.
+package strict
+
+import (
+	"github.com/open-policy-agent/conftest/parser/location"
+)
+
+// Kind is the kind of a finding.
+type Kind string
+
+const (
+	// DuplicateKey is a key declared more than once in the same mapping, of
+	// which only the last value is kept.
+	DuplicateKey Kind = "duplicate-key"
+
+	// Boolean is an unquoted YAML 1.1 boolean other than true and false, such
+	// as no, on or y, which is read as a boolean rather than a string.
+	Boolean Kind = "yaml11-boolean"
+
+	// Octal is an unquoted number with a leading zero, such as 0755, which is
+	// read as an octal number, or loses its leading zeros.
+	Octal Kind = "octal-number"
+)
+
+// Finding is a value of a document that is likely not to be read as intended.
+type Finding struct {
+	Kind Kind
+
+	// Path is the path of the value within the document.
+	Path []string
+
+	// Position is the position the value is declared at.
+	Position location.Position
+
+	// Message describes how the value is read.
+	Message string
+}
@@ -0,0 +1,204 @@
+// Package suppression parses the inline suppression directives that can be
+// written in the comments of configuration files, for example:
+//
+//	# conftest:ignore deny_privileged reason="legacy workload" expires=2025-12-31
+//
+// A directive names one or more rules, separated by commas, whose failures
+// and warnings are reported as exceptions instead.
+package suppression
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// keyword marks the start of a directive within a comment.
+const keyword = "conftest:ignore"
+
+// Directive is an inline suppression of one or more rules.
+type Directive struct {
+	// Rules are the names or IDs of the suppressed rules.
+	Rules []string
+
+	// Reason is the justification recorded for the suppression.
+	Reason string
+
+	// Expires is the last day the suppression applies, or the zero time
+	// when it does not expire.
+	Expires time.Time
+
+	// Line is the line of the file the directive was written at.
+	Line int
+}
+
+// Requirements are the attributes that directives must declare in order to
+// be applied.
+type Requirements struct {
+	Reason bool
+	Expiry bool
+}
+
+// Mentioned returns true if the contents mention the keyword of a directive,
+// which they must for Scan to find any.
+func Mentioned(contents []byte) bool {
+	return bytes.Contains(contents, []byte(keyword))
+}
+
+// Scan returns the directives found in the comments of the given contents.
+// Comments start with any of the given markers, e.g. "#" or "//", and the
+// directive must immediately follow the marker. Line numbers start at one
+// and are offset by the given number of lines.
+func Scan(contents []byte, lineOffset int, markers ...string) ([]Directive, error) {
+	var directives []Directive
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	scanner.Buffer(nil, len(contents)+1)
+	for line := lineOffset + 1; scanner.Scan(); line++ {
+		text, ok := directiveText(scanner.Text(), markers)
+		if !ok {
+			continue
+		}
+
+		directive, err := Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		directive.Line = line
+
+		directives = append(directives, directive)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+
+	return directives, nil
+}
+
+// directiveText returns the text following the keyword when the line has a
+// comment that contains a directive.
+func directiveText(line string, markers []string) (string, bool) {
+	if !strings.Contains(line, keyword) {
+		return "", false
+	}
+
+	for _, marker := range markers {
+		for rest := line; ; {
+			i := strings.Index(rest, marker)
+			if i < 0 {
+				break
+			}
+			rest = rest[i+len(marker):]
+
+			text, ok := strings.CutPrefix(strings.TrimLeft(rest, " \t"), keyword)
+			if ok && (text == "" || text[0] == ' ' || text[0] == '\t') {
+				return text, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// Parse parses the text following the keyword of a directive: a comma
+// separated list of rules, optionally followed by the reason and expires
+// attributes. Values that contain spaces must be double quoted.
+func Parse(text string) (Directive, error) {
+	fields := strings.TrimSpace(text)
+	rules, attributes, _ := strings.Cut(fields, " ")
+
+	var directive Directive
+	for rule := range strings.SplitSeq(rules, ",") {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			directive.Rules = append(directive.Rules, rule)
+		}
+	}
+	if len(directive.Rules) == 0 {
+		return Directive{}, fmt.Errorf("%s requires the name of a rule", keyword)
+	}
+
+	for rest := strings.TrimSpace(attributes); rest != ""; rest = strings.TrimSpace(rest) {
+		key, raw, ok := strings.Cut(rest, "=")
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return Directive{}, fmt.Errorf("invalid attribute %q, attributes must be written as key=value", rest)
+		}
+
+		var value string
+		var err error
+		value, rest, err = attributeValue(raw)
+		if err != nil {
+			return Directive{}, fmt.Errorf("attribute %s: %w", key, err)
+		}
+
+		switch key {
+		case "reason":
+			directive.Reason = value
+		case "expires":
+			expires, err := time.Parse(time.DateOnly, value)
+			if err != nil {
+				return Directive{}, fmt.Errorf("attribute expires must be a date formatted as YYYY-MM-DD: %q", value)
+			}
+			directive.Expires = expires
+		default:
+			return Directive{}, fmt.Errorf("unknown attribute %q", key)
+		}
+	}
+
+	return directive, nil
+}
+
+// attributeValue returns the value at the start of the given text, which is
+// either double quoted or ends at the next whitespace, and the text after it.
+func attributeValue(text string) (value string, rest string, err error) {
+	if !strings.HasPrefix(text, `"`) {
+		value, rest, _ = strings.Cut(text, " ")
+		return value, rest, nil
+	}
+
+	quoted, err := strconv.QuotedPrefix(text)
+	if err != nil {
+		return "", "", fmt.Errorf("unterminated quoted value")
+	}
+
+	value, err = strconv.Unquote(quoted)
+	if err != nil {
+		return "", "", fmt.Errorf("unquote value: %w", err)
+	}
+
+	return value, text[len(quoted):], nil
+}
+
+// Matches returns true when the directive suppresses a rule known by any of
+// the given names.
+func (d Directive) Matches(names ...string) bool {
+	for _, name := range names {
+		if name != "" && slices.Contains(d.Rules, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate returns an error when the directive does not declare the required
+// attributes, or when it has expired at the given time. A directive applies
+// until the end of the day it expires on.
+func (d Directive) Validate(requirements Requirements, now time.Time) error {
+	if requirements.Reason && d.Reason == "" {
+		return fmt.Errorf("%s at line %d has no reason", keyword, d.Line)
+	}
+
+	if requirements.Expiry && d.Expires.IsZero() {
+		return fmt.Errorf("%s at line %d has no expiry date", keyword, d.Line)
+	}
+
+	if !d.Expires.IsZero() && !now.Before(d.Expires.AddDate(0, 0, 1)) {
+		return fmt.Errorf("%s at line %d expired on %s", keyword, d.Line, d.Expires.Format(time.DateOnly))
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package suppression
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    Directive
+		wantErr bool
+	}{
+		{
+			name: "single rule",
+			text: " deny_privileged",
+			want: Directive{Rules: []string{"deny_privileged"}},
+		},
+		{
+			name: "multiple rules",
+			text: " deny_privileged,K8S-001",
+			want: Directive{Rules: []string{"deny_privileged", "K8S-001"}},
+		},
+		{
+			name: "quoted reason and expiry",
+			text: ` deny_privileged reason="legacy \"app\"" expires=2025-12-31`,
+			want: Directive{
+				Rules:   []string{"deny_privileged"},
+				Reason:  `legacy "app"`,
+				Expires: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "unquoted reason",
+			text: " deny_privileged reason=legacy",
+			want: Directive{Rules: []string{"deny_privileged"}, Reason: "legacy"},
+		},
+		{
+			name:    "no rule",
+			text:    "",
+			wantErr: true,
+		},
+		{
+			name:    "unknown attribute",
+			text:    " deny_privileged owner=platform",
+			wantErr: true,
+		},
+		{
+			name:    "invalid expiry",
+			text:    " deny_privileged expires=31/12/2025",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated reason",
+			text:    ` deny_privileged reason="legacy`,
+			wantErr: true,
+		},
+		{
+			name:    "attribute without value",
+			text:    " deny_privileged legacy",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScan(t *testing.T) {
+	contents := []byte(`resource "aws_s3_bucket" "logs" {
+  # conftest:ignore deny_public_acl reason="public website"
+  acl = "public-read" // conftest:ignore warn_acl
+  website = "https://example.com/#conftest:ignore"
+  #conftest:ignored_rule
+}
+`)
+
+	got, err := Scan(contents, 10, "#", "//")
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+
+	want := []Directive{
+		{Rules: []string{"deny_public_acl"}, Reason: "public website", Line: 12},
+		{Rules: []string{"warn_acl"}, Line: 13},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := Scan([]byte("# conftest:ignore deny_x when=now\n"), 0, "#"); err == nil {
+		t.Error("expected an error for a malformed directive")
+	}
+
+	if !Mentioned(contents) {
+		t.Error("expected the directives to be mentioned")
+	}
+	if Mentioned([]byte("# conftest ignore deny_x\n")) {
+		t.Error("expected no directive to be mentioned")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		directive    Directive
+		requirements Requirements
+		wantErr      bool
+	}{
+		{
+			name:      "no requirements",
+			directive: Directive{Rules: []string{"deny_x"}},
+		},
+		{
+			name:         "missing reason",
+			directive:    Directive{Rules: []string{"deny_x"}},
+			requirements: Requirements{Reason: true},
+			wantErr:      true,
+		},
+		{
+			name:         "missing expiry",
+			directive:    Directive{Rules: []string{"deny_x"}, Reason: "legacy"},
+			requirements: Requirements{Reason: true, Expiry: true},
+			wantErr:      true,
+		},
+		{
+			name:      "expires today",
+			directive: Directive{Rules: []string{"deny_x"}, Expires: time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:      "expired",
+			directive: Directive{Rules: []string{"deny_x"}, Expires: time.Date(2025, 6, 14, 0, 0, 0, 0, time.UTC)},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.directive.Validate(tt.requirements, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+// Package tfplan parses the JSON representation of Terraform plans, as output
+// by `terraform show -json`, into a normalized view of the planned changes.
+package tfplan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	jsonparser "github.com/open-policy-agent/conftest/parser/json"
+	"github.com/open-policy-agent/conftest/parser/location"
+)
+
+// Parser is a Terraform plan parser.
+type Parser struct{}
+
+// rawPlan is the subset of the Terraform plan representation that is normalized,
+// see https://developer.hashicorp.com/terraform/internals/json-format.
+type rawPlan struct {
+	FormatVersion    string                 `json:"format_version"`
+	TerraformVersion string                 `json:"terraform_version"`
+	Variables        map[string]rawVariable `json:"variables"`
+	PlannedValues    json.RawMessage        `json:"planned_values"`
+	ResourceChanges  []rawResourceChange    `json:"resource_changes"`
+}
+
+type rawVariable struct {
+	Value any `json:"value"`
+}
+
+type rawResourceChange struct {
+	Address       string    `json:"address"`
+	ModuleAddress string    `json:"module_address"`
+	Mode          string    `json:"mode"`
+	Type          string    `json:"type"`
+	Name          string    `json:"name"`
+	Index         any       `json:"index"`
+	ProviderName  string    `json:"provider_name"`
+	Change        rawChange `json:"change"`
+}
+
+type rawChange struct {
+	Actions      []string `json:"actions"`
+	Before       any      `json:"before"`
+	After        any      `json:"after"`
+	AfterUnknown any      `json:"after_unknown"`
+}
+
+// plan is the normalized view of a Terraform plan that policies are evaluated
+// against. The original plan is available in full under the plan key.
+type plan struct {
+	FormatVersion    string          `json:"format_version"`
+	TerraformVersion string          `json:"terraform_version"`
+	Resources        []resource      `json:"resources"`
+	Variables        map[string]any  `json:"variables"`
+	Plan             json.RawMessage `json:"plan"`
+}
+
+// resource is a resource instance that is part of the plan, along with the
+// change that is planned for it.
+type resource struct {
+	// Address is the absolute address of the resource instance, including
+	// its module and index, e.g. module.vpc.aws_subnet.private[0].
+	Address string `json:"address"`
+
+	// Module is the address of the module the resource is declared in, or
+	// the empty string for the root module.
+	Module string `json:"module"`
+
+	// ModulePath holds the names of the module calls, from the root module
+	// down to the module the resource is declared in.
+	ModulePath []string `json:"module_path"`
+
+	Mode     string `json:"mode"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Index    any    `json:"index,omitempty"`
+	Provider string `json:"provider"`
+
+	// Action summarizes the actions planned for the resource: one of create,
+	// read, update, delete, replace or no-op.
+	Action  string   `json:"action"`
+	Actions []string `json:"actions"`
+
+	Before       any `json:"before"`
+	After        any `json:"after"`
+	AfterUnknown any `json:"after_unknown,omitempty"`
+}
+
+// Unmarshal unmarshals Terraform plans into their normalized view. An error
+// is returned when the JSON document is not a Terraform plan.
+func (p *Parser) Unmarshal(data []byte, v any) error {
+	normalized, err := normalize(data)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.Marshal(normalized)
+	if err != nil {
+		return fmt.Errorf("marshal plan: %w", err)
+	}
+
+	if err := json.Unmarshal(out, v); err != nil {
+		return fmt.Errorf("unmarshal plan: %w", err)
+	}
+
+	return nil
+}
+
+// normalize returns the normalized view of the given Terraform plan.
+func normalize(data []byte) (*plan, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var raw rawPlan
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("unmarshal plan json: %w", err)
+	}
+
+	// State files share the format of plans, but have neither planned values
+	// nor resource changes.
+	if raw.FormatVersion == "" || (raw.PlannedValues == nil && raw.ResourceChanges == nil) {
+		return nil, fmt.Errorf("not a Terraform plan, expected the output of `terraform show -json` for a plan file")
+	}
+
+	normalized := plan{
+		FormatVersion:    raw.FormatVersion,
+		TerraformVersion: raw.TerraformVersion,
+		Resources:        make([]resource, 0, len(raw.ResourceChanges)),
+		Variables:        make(map[string]any, len(raw.Variables)),
+		Plan:             json.RawMessage(data),
+	}
+
+	for name, variable := range raw.Variables {
+		normalized.Variables[name] = variable.Value
+	}
+
+	for _, resourceChange := range raw.ResourceChanges {
+		normalized.Resources = append(normalized.Resources, resource{
+			Address:      resourceChange.Address,
+			Module:       resourceChange.ModuleAddress,
+			ModulePath:   modulePath(resourceChange.ModuleAddress),
+			Mode:         resourceChange.Mode,
+			Type:         resourceChange.Type,
+			Name:         resourceChange.Name,
+			Index:        resourceChange.Index,
+			Provider:     resourceChange.ProviderName,
+			Action:       action(resourceChange.Change.Actions),
+			Actions:      resourceChange.Change.Actions,
+			Before:       resourceChange.Change.Before,
+			After:        resourceChange.Change.After,
+			AfterUnknown: resourceChange.Change.AfterUnknown,
+		})
+	}
+
+	return &normalized, nil
+}
+
+// action summarizes the actions of a change. Terraform represents replacing a
+// resource as both a delete and a create action, in either order.
+func action(actions []string) string {
+	switch {
+	case len(actions) == 0:
+		return ""
+	case slices.Contains(actions, "delete") && slices.Contains(actions, "create"):
+		return "replace"
+	default:
+		return actions[0]
+	}
+}
+
+// modulePath returns the names of the module calls in a module address, e.g.
+// module.network.module.subnets["private"] has the path network and
+// subnets["private"]. The keys of a module instance may contain dots, so the
+// address is only split outside of brackets.
+func modulePath(address string) []string {
+	var segments []string
+	var depth, start int
+	var quoted bool
+	for i := 0; i < len(address); i++ {
+		switch c := address[i]; {
+		case quoted && c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == '.' && depth == 0:
+			segments = append(segments, address[start:i])
+			start = i + 1
+		}
+	}
+	if address != "" {
+		segments = append(segments, address[start:])
+	}
+
+	path := []string{}
+	for i := 0; i+1 < len(segments); i += 2 {
+		if segments[i] == "module" {
+			path = append(path, segments[i+1])
+		}
+	}
+
+	return path
+}
+
+// Locate returns the position of the values of the normalized plan, which are
+// the positions of the values they were taken from in the original plan.
+func (p *Parser) Locate(data []byte) ([]location.Map, error) {
+	documents, err := (&jsonparser.Parser{}).Locate(data)
+	if err != nil {
+		return nil, fmt.Errorf("locate plan: %w", err)
+	}
+
+	locations := make(location.Map)
+	for pointer, pos := range documents[0] {
+		segments, err := location.ParsePath(pointer)
+		if err != nil {
+			continue
+		}
+
+		locations.Set(append([]string{"plan"}, segments...), pos)
+		if normalized, ok := normalizedPath(segments); ok {
+			locations.Set(normalized, pos)
+		}
+	}
+
+	return []location.Map{locations}, nil
+}
+
+// normalizedPath returns the path in the normalized plan of the value at the
+// given path of the original plan, if it is part of the normalized plan.
+func normalizedPath(segments []string) ([]string, bool) {
+	if len(segments) == 0 {
+		return segments, true
+	}
+
+	switch segments[0] {
+	case "format_version", "terraform_version":
+		return segments, true
+	case "variables":
+		// The value of each variable is nested in an object of its own, which
+		// is located by the value itself.
+		if len(segments) == 1 {
+			return segments, true
+		}
+		if len(segments) < 3 || segments[2] != "value" {
+			return nil, false
+		}
+
+		return slices.Concat(segments[:2], segments[3:]), true
+	case "resource_changes":
+		path := []string{"resources"}
+		if len(segments) < 3 {
+			return append(path, segments[1:]...), true
+		}
+
+		field := segments[2]
+		rest := segments[3:]
+		switch field {
+		case "module_address":
+			field = "module"
+		case "provider_name":
+			field = "provider"
+		case "change":
+			if len(rest) == 0 {
+				return nil, false
+			}
+			field, rest = rest[0], rest[1:]
+			if !slices.Contains([]string{"actions", "before", "after", "after_unknown"}, field) {
+				return nil, false
+			}
+		}
+
+		return slices.Concat(path, []string{segments[1], field}, rest), true
+	default:
+		return nil, false
+	}
+}
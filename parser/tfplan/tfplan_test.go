@@ -0,0 +1,131 @@
+package tfplan
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshal(t *testing.T) {
+	plan, err := os.ReadFile("../../examples/tfplan/prod.tfplan.json")
+	if err != nil {
+		t.Fatalf("read plan: %v", err)
+	}
+
+	var input map[string]any
+	if err := (&Parser{}).Unmarshal(plan, &input); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if input["terraform_version"] != "1.9.5" {
+		t.Errorf("unexpected terraform version %v", input["terraform_version"])
+	}
+	if variables := input["variables"].(map[string]any); variables["environment"] != "prod" {
+		t.Errorf("unexpected variables %v", variables)
+	}
+	if _, ok := input["plan"].(map[string]any)["resource_changes"]; !ok {
+		t.Error("expected the original plan to be included")
+	}
+
+	resources := input["resources"].([]any)
+	if len(resources) != 3 {
+		t.Fatalf("expected 3 resources, got %d", len(resources))
+	}
+
+	want := map[string]any{
+		"address":     "module.network.aws_subnet.private[0]",
+		"module":      "module.network",
+		"module_path": []any{"network"},
+		"mode":        "managed",
+		"type":        "aws_subnet",
+		"name":        "private",
+		"index":       float64(0),
+		"provider":    "registry.terraform.io/hashicorp/aws",
+		"action":      "replace",
+		"actions":     []any{"delete", "create"},
+		"before":      map[string]any{"cidr_block": "10.0.1.0/24"},
+		"after":       map[string]any{"cidr_block": "10.0.2.0/24"},
+		"after_unknown": map[string]any{
+			"id": true,
+		},
+	}
+	if !reflect.DeepEqual(resources[1], want) {
+		t.Errorf("unexpected resource.\ngot:  %v\nwant: %v", resources[1], want)
+	}
+}
+
+func TestUnmarshalNotAPlan(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "json document", data: `{"kind": "Deployment"}`},
+		{name: "state", data: `{"format_version": "1.0", "values": {"root_module": {}}}`},
+		{name: "invalid json", data: `{`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var input any
+			if err := (&Parser{}).Unmarshal([]byte(tt.data), &input); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestModulePath(t *testing.T) {
+	tests := []struct {
+		address string
+		want    []string
+	}{
+		{address: "", want: []string{}},
+		{address: "module.network", want: []string{"network"}},
+		{address: "module.network[0].module.subnets", want: []string{"network[0]", "subnets"}},
+		{address: `module.dns["eu.example.com"].module.zone`, want: []string{`dns["eu.example.com"]`, "zone"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			if got := modulePath(tt.address); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocate(t *testing.T) {
+	plan, err := os.ReadFile("../../examples/tfplan/prod.tfplan.json")
+	if err != nil {
+		t.Fatalf("read plan: %v", err)
+	}
+
+	locations, err := (&Parser{}).Locate(plan)
+	if err != nil {
+		t.Fatalf("locate: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		line int
+	}{
+		{path: "/variables/environment", line: 6},
+		{path: "/resources/0", line: 13},
+		{path: "/resources/0/address", line: 14},
+		{path: "/resources/0/provider", line: 18},
+		{path: "/resources/0/after/acl", line: 28},
+		{path: "/resources/1/module", line: 36},
+		{path: "/plan/resource_changes/0/change/after/acl", line: 28},
+	}
+
+	for _, tt := range tests {
+		pos, ok := locations[0][tt.path]
+		if !ok {
+			t.Errorf("path %s not found", tt.path)
+			continue
+		}
+		if pos.Line != tt.line {
+			t.Errorf("path %s at line %d, want %d", tt.path, pos.Line, tt.line)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package yaml
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/parser/fix"
+	"github.com/pmezard/go-difflib/difflib"
+	yamlv3 "go.yaml.in/yaml/v3"
+	"sigs.k8s.io/yaml"
+)
+
+// Fix replaces the document at the given index of the YAML file with the value
+// returned by the function. Only the document is written again, with the
+// indentation it was written with, and only the values that changed are
+// replaced, so that the comments, anchors and blank lines of the others are
+// kept. Documents are separated using the same rules as Unmarshal.
+func (yp *Parser) Fix(p []byte, document int, fn func(value any) (any, error)) ([]byte, error) {
+	linebreak := lf
+	if bytes.Contains(p, crlf) {
+		linebreak = crlf
+	}
+
+	subDocuments := separateSubDocuments(p)
+	if document < 0 || document >= len(subDocuments) {
+		return nil, fmt.Errorf("document %d not found, the file has %d documents", document, len(subDocuments))
+	}
+
+	fixed, err := fixDocument(subDocuments[document], fn)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(linebreak, crlf) {
+		fixed = bytes.ReplaceAll(bytes.ReplaceAll(fixed, crlf, lf), lf, crlf)
+	}
+
+	subDocuments = slices.Clone(subDocuments)
+	subDocuments[document] = fixed
+
+	return bytes.Join(subDocuments, slices.Concat(linebreak, sep, linebreak)), nil
+}
+
+func fixDocument(subDocument []byte, fn func(value any) (any, error)) ([]byte, error) {
+	// The function is given its own copy of the document, which it may change.
+	var current, input any
+	if err := yaml.Unmarshal(subDocument, &current); err != nil {
+		return nil, fmt.Errorf("unmarshal yaml: %w", err)
+	}
+	if err := yaml.Unmarshal(subDocument, &input); err != nil {
+		return nil, fmt.Errorf("unmarshal yaml: %w", err)
+	}
+
+	value, err := fn(input)
+	if err != nil {
+		return nil, err
+	}
+	if fix.Equal(current, value) {
+		return subDocument, nil
+	}
+
+	var node yamlv3.Node
+	if err := yamlv3.Unmarshal(subDocument, &node); err != nil {
+		return nil, fmt.Errorf("unmarshal yaml node: %w", err)
+	}
+
+	if len(node.Content) == 0 {
+		root, err := fix.NewNode(value)
+		if err != nil {
+			return nil, err
+		}
+		node = yamlv3.Node{Kind: yamlv3.DocumentNode, Content: []*yamlv3.Node{root}}
+	} else {
+		root, err := fix.Update(node.Content[0], value)
+		if err != nil {
+			return nil, err
+		}
+		node.Content[0] = root
+	}
+
+	indent, compact := indentation(node.Content[0])
+	untagMergeKeys(&node)
+
+	var buf bytes.Buffer
+	encoder := yamlv3.NewEncoder(&buf)
+	encoder.SetIndent(indent)
+	if compact {
+		encoder.CompactSeqIndent()
+	}
+	if err := encoder.Encode(&node); err != nil {
+		return nil, fmt.Errorf("encode yaml: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("encode yaml: %w", err)
+	}
+
+	var fixed any
+	if err := yaml.Unmarshal(buf.Bytes(), &fixed); err != nil {
+		return nil, fmt.Errorf("unmarshal fixed yaml: %w", err)
+	}
+	if !fix.Equal(fixed, value) {
+		return nil, fmt.Errorf("the fixed document cannot be written without changing other values")
+	}
+
+	text := restoreBlankLines(string(subDocument), buf.String())
+	if trimmed := bytes.TrimLeft(subDocument, "\r\n"); bytes.HasPrefix(trimmed, sep) && !strings.HasPrefix(text, string(sep)) {
+		text = string(subDocument[:len(subDocument)-len(trimmed)]) + string(sep) + "\n" + text
+	}
+	if !bytes.HasSuffix(subDocument, lf) {
+		text = strings.TrimSuffix(text, "\n")
+	}
+
+	return []byte(text), nil
+}
+
+// indentation returns the number of spaces the mappings of the document are
+// indented with, and whether the sequences of its mappings are not indented,
+// as found at the first nested mapping and sequence written in block style.
+func indentation(root *yamlv3.Node) (int, bool) {
+	indent, compact := 0, false
+	foundIndent, foundCompact := false, false
+
+	var walk func(node *yamlv3.Node)
+	walk = func(node *yamlv3.Node) {
+		if foundIndent && foundCompact || node.Style&yamlv3.FlowStyle != 0 {
+			return
+		}
+
+		if node.Kind == yamlv3.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				if value.Style&yamlv3.FlowStyle != 0 || len(value.Content) == 0 || value.Line == key.Line {
+					continue
+				}
+
+				switch value.Kind {
+				case yamlv3.MappingNode:
+					if !foundIndent && value.Content[0].Column > key.Column {
+						indent, foundIndent = value.Content[0].Column-key.Column, true
+					}
+				case yamlv3.SequenceNode:
+					if !foundCompact {
+						compact, foundCompact = value.Column == key.Column, true
+					}
+					if !foundIndent && value.Column > key.Column {
+						indent, foundIndent = value.Column-key.Column, true
+					}
+				}
+			}
+		}
+
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	if !foundIndent {
+		indent = 2
+	}
+
+	return indent, compact
+}
+
+// untagMergeKeys removes the tag of the merge keys, which the encoder would
+// otherwise write explicitly.
+func untagMergeKeys(node *yamlv3.Node) {
+	if node.Kind == yamlv3.ScalarNode && node.Tag == "!!merge" {
+		node.Tag = ""
+	}
+
+	for _, child := range node.Content {
+		untagMergeKeys(child)
+	}
+}
+
+// restoreBlankLines adds the blank lines of the original document, which are
+// not kept by the encoder, back into the fixed document. Blank lines follow the
+// line they followed in the original document when it is unchanged, and come
+// before the line they came before otherwise.
+func restoreBlankLines(original, fixed string) string {
+	originalLines := strings.SplitAfter(original, "\n")
+	fixedLines := strings.SplitAfter(fixed, "\n")
+
+	matches := make(map[int]int)
+	matcher := difflib.NewMatcher(originalLines, fixedLines)
+	for _, block := range matcher.GetMatchingBlocks() {
+		for k := 0; k < block.Size; k++ {
+			matches[block.A+k] = block.B + k
+		}
+	}
+
+	blank := func(line string) bool { return strings.TrimSpace(line) == "" }
+
+	// The number of blank lines to add before every line of the fixed document.
+	restored := make(map[int]int)
+	for i := 0; i < len(originalLines); i++ {
+		if !blank(originalLines[i]) || i == 0 || i == len(originalLines)-1 {
+			continue
+		}
+
+		start := i
+		for i < len(originalLines) && blank(originalLines[i]) {
+			i++
+		}
+		if i == len(originalLines) {
+			break
+		}
+
+		if previous, ok := matches[start-1]; ok && previous+1 < len(fixedLines) {
+			restored[previous+1] = i - start
+		} else if next, ok := matches[i]; ok {
+			restored[next] = i - start
+		}
+	}
+
+	var sb strings.Builder
+	for i, line := range fixedLines {
+		sb.WriteString(strings.Repeat("\n", restored[i]))
+		sb.WriteString(line)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,178 @@
+package yaml
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/parser/location"
+	yamlv3 "go.yaml.in/yaml/v3"
+)
+
+// The keys the metadata of a document are added under, next to its own keys.
+const (
+	commentsKey      = "__comments"
+	anchorsKey       = "__anchors"
+	keysKey          = "__keys"
+	duplicateKeysKey = "__duplicate_keys"
+)
+
+// Comment holds the comments of a value. The comment markers are removed, and
+// comments spanning multiple lines are joined with newlines.
+type Comment struct {
+	// Head is the comment on the lines before the value.
+	Head string `json:"head,omitempty"`
+
+	// Line is the comment at the end of the line of the value.
+	Line string `json:"line,omitempty"`
+
+	// Foot is the comment on the lines after the value.
+	Foot string `json:"foot,omitempty"`
+}
+
+// Anchor is an anchor of a document and the aliases that refer to it.
+type Anchor struct {
+	// Path is the path of the value the anchor is set on.
+	Path string `json:"path"`
+
+	// Aliases are the paths of the aliases of the anchor.
+	Aliases []string `json:"aliases"`
+}
+
+// DuplicateKey is a key declared more than once in the same mapping, of which
+// only the last value is kept.
+type DuplicateKey struct {
+	// Path is the path of the mapping.
+	Path string `json:"path"`
+
+	// Key is the duplicated key.
+	Key string `json:"key"`
+
+	// Lines are the lines the key is declared on, in order.
+	Lines []int `json:"lines"`
+}
+
+// Metadata is the metadata of a document that YAML decoding discards. All of
+// the paths are JSON pointers, as used for the locations of values.
+type Metadata struct {
+	Comments      map[string]Comment
+	Anchors       map[string]*Anchor
+	Keys          map[string][]string
+	DuplicateKeys []DuplicateKey
+}
+
+// addMetadata adds the metadata of the document to the decoded document, when
+// the document is a mapping.
+func addMetadata(decoded any, document *yamlv3.Node, lineOffset int) {
+	mapping, ok := decoded.(map[string]any)
+	if !ok || len(document.Content) == 0 {
+		return
+	}
+
+	metadata := newMetadata(document, lineOffset)
+	mapping[commentsKey] = metadata.Comments
+	mapping[anchorsKey] = metadata.Anchors
+	mapping[keysKey] = metadata.Keys
+	mapping[duplicateKeysKey] = metadata.DuplicateKeys
+}
+
+// newMetadata collects the metadata of a YAML document.
+func newMetadata(document *yamlv3.Node, lineOffset int) Metadata {
+	metadata := Metadata{
+		Comments:      make(map[string]Comment),
+		Anchors:       make(map[string]*Anchor),
+		Keys:          make(map[string][]string),
+		DuplicateKeys: []DuplicateKey{},
+	}
+
+	comment := func(path []string, nodes ...*yamlv3.Node) {
+		var c Comment
+		for _, node := range nodes {
+			c.Head = joinComments(c.Head, node.HeadComment)
+			c.Line = joinComments(c.Line, node.LineComment)
+			c.Foot = joinComments(c.Foot, node.FootComment)
+		}
+		if c != (Comment{}) {
+			metadata.Comments[location.Pointer(path)] = c
+		}
+	}
+
+	var walk func(node *yamlv3.Node, path []string)
+	walk = func(node *yamlv3.Node, path []string) {
+		pointer := location.Pointer(path)
+		if node.Anchor != "" {
+			anchor := metadata.anchor(node.Anchor)
+			anchor.Path = pointer
+		}
+
+		switch node.Kind {
+		case yamlv3.AliasNode:
+			anchor := metadata.anchor(node.Value)
+			anchor.Aliases = append(anchor.Aliases, pointer)
+		case yamlv3.MappingNode:
+			keys := []string{}
+			declared := make(map[string][]int)
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				if !slices.Contains(keys, key.Value) {
+					keys = append(keys, key.Value)
+				}
+				declared[key.Value] = append(declared[key.Value], key.Line+lineOffset)
+
+				keyPath := append(slices.Clone(path), key.Value)
+				comment(keyPath, key, value)
+				walk(value, keyPath)
+			}
+			metadata.Keys[pointer] = keys
+
+			for _, key := range keys {
+				if lines := declared[key]; len(lines) > 1 {
+					metadata.DuplicateKeys = append(metadata.DuplicateKeys, DuplicateKey{Path: pointer, Key: key, Lines: lines})
+				}
+			}
+		case yamlv3.SequenceNode:
+			for i, item := range node.Content {
+				itemPath := append(slices.Clone(path), strconv.Itoa(i))
+				comment(itemPath, item)
+				walk(item, itemPath)
+			}
+		}
+	}
+
+	root := document.Content[0]
+	comment(nil, document, root)
+	walk(root, nil)
+
+	return metadata
+}
+
+func (m Metadata) anchor(name string) *Anchor {
+	anchor, ok := m.Anchors[name]
+	if !ok {
+		anchor = &Anchor{Aliases: []string{}}
+		m.Anchors[name] = anchor
+	}
+
+	return anchor
+}
+
+// joinComments joins a comment to the comments already collected, after
+// removing its comment markers.
+func joinComments(comments, comment string) string {
+	if comment == "" {
+		return comments
+	}
+
+	lines := strings.Split(comment, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "#")
+		lines[i] = strings.TrimPrefix(line, " ")
+	}
+	text := strings.TrimSpace(strings.Join(lines, "\n"))
+
+	if comments == "" {
+		return text
+	}
+	return comments + "\n" + text
+}
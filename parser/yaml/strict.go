@@ -0,0 +1,142 @@
+package yaml
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/open-policy-agent/conftest/parser/strict"
+	yamlv3 "go.yaml.in/yaml/v3"
+)
+
+// booleans are the YAML 1.1 booleans, other than true and false, that are
+// read as booleans rather than as strings.
+var booleans = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true,
+	"on": true, "On": true, "ON": true,
+	"n": false, "N": false, "no": false, "No": false, "NO": false,
+	"off": false, "Off": false, "OFF": false,
+}
+
+var leadingZero = regexp.MustCompile(`^[-+]?0[0-9]+$`)
+
+// CheckStrict returns the duplicate keys, YAML 1.1 booleans and numbers with
+// leading zeros of every document of the YAML file, using the same document
+// separation rules as Unmarshal so that the indexes of the documents match.
+// Nothing is returned unless the parser is strict.
+func (yp *Parser) CheckStrict(p []byte) ([][]strict.Finding, error) {
+	if !yp.Strict {
+		return nil, nil
+	}
+
+	subDocuments, lineOffsets := separateSubDocumentLines(p)
+
+	findings := make([][]strict.Finding, 0, len(subDocuments))
+	for i, subDocument := range subDocuments {
+		var node yamlv3.Node
+		if err := yamlv3.Unmarshal(subDocument, &node); err != nil {
+			return nil, fmt.Errorf("unmarshal yaml node: %w", err)
+		}
+
+		findings = append(findings, checkNode(&node, lineOffsets[i]))
+	}
+
+	return findings, nil
+}
+
+func checkNode(document *yamlv3.Node, lineOffset int) []strict.Finding {
+	findings := []strict.Finding{}
+	if len(document.Content) == 0 {
+		return findings
+	}
+
+	var walk func(node *yamlv3.Node, path []string)
+	walk = func(node *yamlv3.Node, path []string) {
+		switch node.Kind {
+		case yamlv3.ScalarNode:
+			if finding, ok := checkScalar(node, path, lineOffset); ok {
+				findings = append(findings, finding)
+			}
+		case yamlv3.MappingNode:
+			declared := make(map[string]int)
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				keyPath := append(slices.Clone(path), key.Value)
+
+				if line, ok := declared[key.Value]; ok {
+					findings = append(findings, strict.Finding{
+						Kind:     strict.DuplicateKey,
+						Path:     keyPath,
+						Position: position(key, lineOffset),
+						Message:  fmt.Sprintf("key %q is declared on line %d and again on line %d, which overrides it", key.Value, line, key.Line+lineOffset),
+					})
+				} else {
+					declared[key.Value] = key.Line + lineOffset
+				}
+
+				if finding, ok := checkScalar(key, keyPath, lineOffset); ok {
+					finding.Message = "key " + finding.Message
+					findings = append(findings, finding)
+				}
+				walk(value, keyPath)
+			}
+		case yamlv3.SequenceNode:
+			for i, item := range node.Content {
+				walk(item, append(slices.Clone(path), strconv.Itoa(i)))
+			}
+		}
+	}
+	walk(document.Content[0], nil)
+
+	return findings
+}
+
+// checkScalar reports a plain scalar, neither quoted nor tagged, that YAML 1.1
+// reads as a boolean or as a number with a leading zero.
+func checkScalar(node *yamlv3.Node, path []string, lineOffset int) (strict.Finding, bool) {
+	if node.Kind != yamlv3.ScalarNode || node.Style != 0 {
+		return strict.Finding{}, false
+	}
+
+	if value, ok := booleans[node.Value]; ok {
+		return strict.Finding{
+			Kind:     strict.Boolean,
+			Path:     path,
+			Position: position(node, lineOffset),
+			Message:  fmt.Sprintf("%s is read as the boolean %t, quote it to keep it a string", node.Value, value),
+		}, true
+	}
+
+	if leadingZero.MatchString(node.Value) {
+		if value, err := strconv.ParseInt(node.Value, 8, 64); err == nil {
+			return strict.Finding{
+				Kind:     strict.Octal,
+				Path:     path,
+				Position: position(node, lineOffset),
+				Message:  fmt.Sprintf("%s is read as the octal number %d, quote it to keep it a string or write it as %s", node.Value, value, octalLiteral(node.Value)),
+			}, true
+		}
+
+		value, _ := strconv.ParseInt(node.Value, 10, 64)
+		return strict.Finding{
+			Kind:     strict.Octal,
+			Path:     path,
+			Position: position(node, lineOffset),
+			Message:  fmt.Sprintf("%s is read as the number %d without its leading zeros, quote it to keep it a string", node.Value, value),
+		}, true
+	}
+
+	return strict.Finding{}, false
+}
+
+// octalLiteral returns an octal number with a leading zero written with the
+// 0o prefix of YAML 1.2 instead, such as 0o755 for 0755.
+func octalLiteral(value string) string {
+	sign := ""
+	if value[0] == '-' || value[0] == '+' {
+		sign, value = value[:1], value[1:]
+	}
+
+	return sign + "0o" + value[1:]
+}
@@ -1,15 +1,34 @@
 package yaml
 
 import (
+	"bufio"
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"slices"
+	"strconv"
+	"sync"
 
+	"github.com/open-policy-agent/conftest/parser/location"
+	"github.com/open-policy-agent/conftest/parser/strict"
+	"github.com/open-policy-agent/conftest/parser/suppression"
+	yamlv3 "go.yaml.in/yaml/v3"
 	"sigs.k8s.io/yaml"
 )
 
 // Parser is a YAML parser.
-type Parser struct{}
+type Parser struct {
+	// Metadata adds the comments, anchors, key order and duplicate keys of
+	// every document that is a mapping under the __comments, __anchors, __keys
+	// and __duplicate_keys keys of the document.
+	Metadata bool
+
+	// Strict reports the duplicate keys, YAML 1.1 booleans and numbers with
+	// leading zeros of the documents with CheckStrict.
+	Strict bool
+}
 
 var (
 	lf   = []byte{'\n'}
@@ -19,6 +38,10 @@ var (
 
 // Unmarshal unmarshals YAML files.
 func (yp *Parser) Unmarshal(p []byte, v any) error {
+	if yp.Metadata {
+		return unmarshalWithMetadata(p, v)
+	}
+
 	subDocuments := separateSubDocuments(p)
 	if len(subDocuments) > 1 {
 		if err := unmarshalMultipleDocuments(subDocuments, v); err != nil {
@@ -35,6 +58,120 @@ func (yp *Parser) Unmarshal(p []byte, v any) error {
 	return nil
 }
 
+// Stream decodes the YAML documents read from r one at a time. The function is
+// called with every document, a function that returns the position of its
+// values, its suppression directives and its strict findings, as soon as the
+// document has been decoded, so that the documents of a file never have to be
+// held in memory all at once. Documents are separated using the same rules as
+// Unmarshal, and the findings are nil unless the parser is strict, as with
+// CheckStrict.
+//
+// The positions are only found when they are first requested, which may be
+// after the function returns, unless the document has already been parsed
+// into a node for its metadata or strict findings.
+func (yp *Parser) Stream(r io.Reader, fn func(document any, locate func() location.Map, suppressions []suppression.Directive, findings []strict.Finding) error) error {
+	reader := bufio.NewReader(r)
+
+	var document bytes.Buffer
+	var line, documentLine int
+	var directive, directiveSeparated, separated bool
+	decode := func() error {
+		var documentObject any
+		if err := yaml.Unmarshal(document.Bytes(), &documentObject); err != nil {
+			return fmt.Errorf("unmarshal subdocument yaml: %w", err)
+		}
+
+		var node *yamlv3.Node
+		if yp.Metadata || yp.Strict {
+			node = &yamlv3.Node{}
+			if err := yamlv3.Unmarshal(document.Bytes(), node); err != nil {
+				return fmt.Errorf("unmarshal yaml node: %w", err)
+			}
+		}
+		if yp.Metadata {
+			addMetadata(documentObject, node, documentLine)
+		}
+
+		var suppressions []suppression.Directive
+		if suppression.Mentioned(document.Bytes()) {
+			directives, err := suppression.Scan(document.Bytes(), documentLine, "#")
+			if err != nil {
+				return fmt.Errorf("scan suppressions: %w", err)
+			}
+			suppressions = directives
+		}
+
+		var findings []strict.Finding
+		if yp.Strict {
+			findings = checkNode(node, documentLine)
+		}
+
+		if err := fn(documentObject, lazyLocations(node, document.Bytes(), documentLine), suppressions, findings); err != nil {
+			return err
+		}
+
+		document.Reset()
+		return nil
+	}
+
+	for {
+		current, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("read yaml: %w", err)
+		}
+
+		if line == 0 {
+			directive = bytes.HasPrefix(current, []byte("%"))
+		}
+
+		// A separator is only recognized when it is on a line of its own that
+		// is preceded by a line of the document, as is the case when splitting
+		// the contents in Unmarshal.
+		isSeparator := line > 0 && !separated &&
+			(bytes.Equal(current, slices.Concat(sep, lf)) || bytes.Equal(current, slices.Concat(sep, crlf)))
+		separated = isSeparator
+		line++
+
+		switch {
+		case isSeparator && directive && !directiveSeparated:
+			directiveSeparated = true
+			document.Write(current)
+		case isSeparator:
+			if err := decode(); err != nil {
+				return err
+			}
+			documentLine = line
+		default:
+			document.Write(current)
+		}
+
+		if errors.Is(err, io.EOF) {
+			return decode()
+		}
+	}
+}
+
+// lazyLocations returns a function that returns the position of the values of
+// a streamed document, from its node when it has already been parsed, and from
+// a copy of its contents otherwise.
+func lazyLocations(node *yamlv3.Node, contents []byte, lineOffset int) func() location.Map {
+	if node != nil {
+		return sync.OnceValue(func() location.Map {
+			return locateNode(node, lineOffset)
+		})
+	}
+
+	contents = bytes.Clone(contents)
+	return sync.OnceValue(func() location.Map {
+		var node yamlv3.Node
+		if err := yamlv3.Unmarshal(contents, &node); err != nil {
+			return nil
+		}
+
+		return locateNode(&node, lineOffset)
+	})
+}
+
 func separateSubDocuments(data []byte) [][]byte {
 	// Determine line ending style
 	linebreak := lf
@@ -68,6 +205,27 @@ func separateSubDocuments(data []byte) [][]byte {
 	return parts
 }
 
+// separateSubDocumentLines separates the sub documents in the same way as
+// separateSubDocuments, and additionally returns the number of lines of the
+// file that precede every sub document.
+func separateSubDocumentLines(data []byte) ([][]byte, []int) {
+	subDocuments := separateSubDocuments(data)
+
+	separatorLength := len(slices.Concat(lf, sep, lf))
+	if bytes.Contains(data, crlf) {
+		separatorLength = len(slices.Concat(crlf, sep, crlf))
+	}
+
+	var offset int
+	lineOffsets := make([]int, 0, len(subDocuments))
+	for _, subDocument := range subDocuments {
+		lineOffsets = append(lineOffsets, bytes.Count(data[:offset], lf))
+		offset += len(subDocument) + separatorLength
+	}
+
+	return subDocuments, lineOffsets
+}
+
 func unmarshalMultipleDocuments(subDocuments [][]byte, v any) error {
 	var documentStore []any
 	for _, subDocument := range subDocuments {
@@ -90,3 +248,120 @@ func unmarshalMultipleDocuments(subDocuments [][]byte, v any) error {
 
 	return nil
 }
+
+// unmarshalWithMetadata unmarshals YAML files in the same way as Unmarshal,
+// adding the metadata of every document.
+func unmarshalWithMetadata(p []byte, v any) error {
+	subDocuments, lineOffsets := separateSubDocumentLines(p)
+
+	documents := make([]any, 0, len(subDocuments))
+	for i, subDocument := range subDocuments {
+		var document any
+		if err := yaml.Unmarshal(subDocument, &document); err != nil {
+			return fmt.Errorf("unmarshal yaml: %w", err)
+		}
+
+		var node yamlv3.Node
+		if err := yamlv3.Unmarshal(subDocument, &node); err != nil {
+			return fmt.Errorf("unmarshal yaml node: %w", err)
+		}
+		addMetadata(document, &node, lineOffsets[i])
+
+		documents = append(documents, document)
+	}
+
+	var result any = documents
+	if len(documents) == 1 {
+		result = documents[0]
+	}
+
+	j, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshal yaml to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal yaml json: %w", err)
+	}
+
+	return nil
+}
+
+// Locate returns the position of every value in the YAML file. One map is
+// returned for each document, using the same document separation rules as
+// Unmarshal so that the indexes of the documents match.
+func (yp *Parser) Locate(p []byte) ([]location.Map, error) {
+	subDocuments, lineOffsets := separateSubDocumentLines(p)
+
+	locations := make([]location.Map, 0, len(subDocuments))
+	for i, subDocument := range subDocuments {
+		lineOffset := lineOffsets[i]
+
+		var node yamlv3.Node
+		if err := yamlv3.Unmarshal(subDocument, &node); err != nil {
+			return nil, fmt.Errorf("unmarshal yaml node: %w", err)
+		}
+
+		locations = append(locations, locateNode(&node, lineOffset))
+	}
+
+	return locations, nil
+}
+
+// Suppressions returns the inline suppression directives in the comments of
+// every document of the YAML file, using the same document separation rules
+// as Unmarshal so that the indexes of the documents match.
+func (yp *Parser) Suppressions(p []byte) ([][]suppression.Directive, error) {
+	subDocuments, lineOffsets := separateSubDocumentLines(p)
+
+	suppressions := make([][]suppression.Directive, 0, len(subDocuments))
+	for i, subDocument := range subDocuments {
+		directives, err := suppression.Scan(subDocument, lineOffsets[i], "#")
+		if err != nil {
+			return nil, fmt.Errorf("scan suppressions: %w", err)
+		}
+
+		suppressions = append(suppressions, directives)
+	}
+
+	return suppressions, nil
+}
+
+func locateNode(document *yamlv3.Node, lineOffset int) location.Map {
+	locations := make(location.Map)
+	if len(document.Content) == 0 {
+		return locations
+	}
+
+	var walk func(node *yamlv3.Node, path []string)
+	walk = func(node *yamlv3.Node, path []string) {
+		switch node.Kind {
+		case yamlv3.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				keyPath := append(slices.Clone(path), key.Value)
+				locations.Set(keyPath, position(key, lineOffset))
+				walk(value, keyPath)
+			}
+		case yamlv3.SequenceNode:
+			for i, item := range node.Content {
+				itemPath := append(slices.Clone(path), strconv.Itoa(i))
+				locations.Set(itemPath, position(item, lineOffset))
+				walk(item, itemPath)
+			}
+		}
+	}
+
+	root := document.Content[0]
+	locations.Set(nil, position(root, lineOffset))
+	walk(root, nil)
+
+	return locations
+}
+
+func position(node *yamlv3.Node, lineOffset int) location.Position {
+	return location.Position{
+		Line:   node.Line + lineOffset,
+		Column: node.Column,
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/open-policy-agent/conftest/parser/location"
+	"github.com/open-policy-agent/conftest/parser/strict"
+	"github.com/open-policy-agent/conftest/parser/suppression"
 	"github.com/open-policy-agent/conftest/parser/yaml"
 )
 
@@ -138,3 +141,424 @@ third_id: 9012`),
 		}
 	})
 }
+
+func TestYAMLLocate(t *testing.T) {
+	config := []byte(`apiVersion: v1
+kind: Service
+metadata:
+  name: first
+---
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: nginx`)
+
+	parser := &yaml.Parser{}
+	locations, err := parser.Locate(config)
+	if err != nil {
+		t.Fatalf("locate: %v", err)
+	}
+
+	if len(locations) != 2 {
+		t.Fatalf("expected a location map for each of the 2 documents, got %d", len(locations))
+	}
+
+	tests := []struct {
+		document int
+		path     string
+		line     int
+		column   int
+	}{
+		{document: 0, path: "/metadata/name", line: 4, column: 3},
+		{document: 1, path: "/kind", line: 7, column: 1},
+		{document: 1, path: "/spec/template/spec/containers/0", line: 12, column: 9},
+		{document: 1, path: "/spec/template/spec/containers/0/image", line: 13, column: 9},
+	}
+
+	for _, tt := range tests {
+		pos, ok := locations[tt.document][tt.path]
+		if !ok {
+			t.Errorf("document %d: path %s not found", tt.document, tt.path)
+			continue
+		}
+		if pos.Line != tt.line || pos.Column != tt.column {
+			t.Errorf("document %d: path %s at %d:%d, want %d:%d", tt.document, tt.path, pos.Line, pos.Column, tt.line, tt.column)
+		}
+	}
+}
+
+func TestYAMLStream(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{name: "single document", config: "kind: Service\n"},
+		{name: "multiple documents", config: "kind: Service\n---\nkind: Deployment\n---\nkind: Pod"},
+		{name: "crlf separators", config: "kind: Service\r\n---\r\nkind: Deployment\r\n"},
+		{name: "leading separator", config: "---\nkind: Service\n---\nkind: Deployment\n"},
+		{name: "trailing separator", config: "kind: Service\n---\n"},
+		{name: "directive", config: "%YAML 1.1\n---\nkind: Service\n---\nkind: Deployment\n"},
+		{name: "separator in block scalar", config: "data: |\n  ---\nkind: Service\n"},
+		{name: "empty", config: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &yaml.Parser{}
+
+			var want any
+			if err := parser.Unmarshal([]byte(tt.config), &want); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+
+			var got []any
+			err := parser.Stream(strings.NewReader(tt.config), func(document any, _ func() location.Map, _ []suppression.Directive, _ []strict.Finding) error {
+				got = append(got, document)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("stream: %v", err)
+			}
+
+			// Unmarshal only returns a list of documents when there is more than one.
+			if len(got) == 1 {
+				if !reflect.DeepEqual(got[0], want) {
+					t.Errorf("got %v, want %v", got[0], want)
+				}
+				return
+			}
+
+			wantDocuments, ok := want.([]any)
+			if !ok || !reflect.DeepEqual(got, wantDocuments) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+
+	t.Run("locations", func(t *testing.T) {
+		config := "kind: Service\n---\napiVersion: apps/v1\nkind: Deployment\n"
+
+		// The locations are requested once the whole file has been streamed.
+		var locations []func() location.Map
+		err := (&yaml.Parser{}).Stream(strings.NewReader(config), func(_ any, locate func() location.Map, _ []suppression.Directive, _ []strict.Finding) error {
+			locations = append(locations, locate)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("stream: %v", err)
+		}
+
+		if len(locations) != 2 {
+			t.Fatalf("expected 2 documents, got %d", len(locations))
+		}
+		if pos := locations[0]()["/kind"]; pos.Line != 1 || pos.Column != 1 {
+			t.Errorf("path /kind at %d:%d, want 1:1", pos.Line, pos.Column)
+		}
+		if pos := locations[1]()["/kind"]; pos.Line != 4 || pos.Column != 1 {
+			t.Errorf("path /kind at %d:%d, want 4:1", pos.Line, pos.Column)
+		}
+	})
+
+	t.Run("suppressions", func(t *testing.T) {
+		config := "kind: Service\n---\n# conftest:ignore deny_latest\nkind: Deployment\n"
+
+		var suppressions [][]suppression.Directive
+		err := (&yaml.Parser{}).Stream(strings.NewReader(config), func(_ any, _ func() location.Map, s []suppression.Directive, _ []strict.Finding) error {
+			suppressions = append(suppressions, s)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("stream: %v", err)
+		}
+
+		if len(suppressions) != 2 || len(suppressions[0]) != 0 || len(suppressions[1]) != 1 {
+			t.Fatalf("expected a single directive in the second document, got %v", suppressions)
+		}
+		if line := suppressions[1][0].Line; line != 3 {
+			t.Errorf("directive at line %d, want 3", line)
+		}
+	})
+}
+
+func TestYAMLSuppressions(t *testing.T) {
+	config := []byte(`# conftest:ignore deny_service reason="internal only"
+kind: Service
+---
+kind: Deployment
+spec:
+  privileged: true # conftest:ignore deny_privileged,warn_root
+  url: "http://example.com/#conftest:ignore"`)
+
+	parser := &yaml.Parser{}
+	suppressions, err := parser.Suppressions(config)
+	if err != nil {
+		t.Fatalf("suppressions: %v", err)
+	}
+
+	want := [][]suppression.Directive{
+		{{Rules: []string{"deny_service"}, Reason: "internal only", Line: 1}},
+		{{Rules: []string{"deny_privileged", "warn_root"}, Line: 6}},
+	}
+	if !reflect.DeepEqual(suppressions, want) {
+		t.Errorf("got %+v, want %+v", suppressions, want)
+	}
+}
+
+func TestYAMLMetadata(t *testing.T) {
+	testTable := []struct {
+		name     string
+		input    string
+		expected any
+	}{
+		{
+			name: "comments",
+			input: `name: api # the service name
+env:
+  # owner: team-a
+  - secretRef: db-password
+`,
+			expected: map[string]any{
+				"name": "api",
+				"env":  []any{map[string]any{"secretRef": "db-password"}},
+				"__comments": map[string]any{
+					"/name":  map[string]any{"line": "the service name"},
+					"/env/0": map[string]any{"head": "owner: team-a"},
+				},
+				"__anchors":        map[string]any{},
+				"__keys":           map[string]any{"": []any{"name", "env"}, "/env/0": []any{"secretRef"}},
+				"__duplicate_keys": []any{},
+			},
+		},
+		{
+			name: "anchors and duplicate keys",
+			input: `defaults: &defaults
+  timeout: 10
+production:
+  <<: *defaults
+  timeout: 30
+  timeout: 60
+`,
+			expected: map[string]any{
+				"defaults":   map[string]any{"timeout": float64(10)},
+				"production": map[string]any{"timeout": float64(60)},
+				"__comments": map[string]any{},
+				"__anchors": map[string]any{
+					"defaults": map[string]any{"path": "/defaults", "aliases": []any{"/production/<<"}},
+				},
+				"__keys": map[string]any{
+					"":            []any{"defaults", "production"},
+					"/defaults":   []any{"timeout"},
+					"/production": []any{"<<", "timeout"},
+				},
+				"__duplicate_keys": []any{
+					map[string]any{"path": "/production", "key": "timeout", "lines": []any{float64(5), float64(6)}},
+				},
+			},
+		},
+		{
+			name: "lines of the documents of a file",
+			input: `a: 1
+---
+b: 1
+b: 2
+---
+- not a mapping
+`,
+			expected: []any{
+				map[string]any{
+					"a":                float64(1),
+					"__comments":       map[string]any{},
+					"__anchors":        map[string]any{},
+					"__keys":           map[string]any{"": []any{"a"}},
+					"__duplicate_keys": []any{},
+				},
+				map[string]any{
+					"b":          float64(2),
+					"__comments": map[string]any{},
+					"__anchors":  map[string]any{},
+					"__keys":     map[string]any{"": []any{"b"}},
+					"__duplicate_keys": []any{
+						map[string]any{"path": "", "key": "b", "lines": []any{float64(3), float64(4)}},
+					},
+				},
+				[]any{"not a mapping"},
+			},
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			parser := &yaml.Parser{Metadata: true}
+
+			var unmarshalled any
+			if err := parser.Unmarshal([]byte(test.input), &unmarshalled); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(unmarshalled, test.expected) {
+				t.Errorf("expected\n%v\n\nto equal\n%v", unmarshalled, test.expected)
+			}
+		})
+	}
+}
+
+func TestYAMLCheckStrict(t *testing.T) {
+	sample := `name: api
+country: NO
+enabled: "yes"
+mode: 0644
+zip: 08540
+octal: 0o644
+on: push
+tags: [y, !!str n]
+---
+replicas: 2
+replicas: 3
+`
+
+	findings, err := (&yaml.Parser{}).CheckStrict([]byte(sample))
+	if err != nil {
+		t.Fatalf("check strict: %v", err)
+	}
+	if findings != nil {
+		t.Errorf("expected no findings unless strict, got %v", findings)
+	}
+
+	findings, err = (&yaml.Parser{Strict: true}).CheckStrict([]byte(sample))
+	if err != nil {
+		t.Fatalf("check strict: %v", err)
+	}
+
+	expected := [][]struct {
+		kind    strict.Kind
+		path    string
+		line    int
+		message string
+	}{
+		{
+			{kind: strict.Boolean, path: "/country", line: 2, message: "NO is read as the boolean false, quote it to keep it a string"},
+			{kind: strict.Octal, path: "/mode", line: 4, message: "0644 is read as the octal number 420, quote it to keep it a string or write it as 0o644"},
+			{kind: strict.Octal, path: "/zip", line: 5, message: "08540 is read as the number 8540 without its leading zeros, quote it to keep it a string"},
+			{kind: strict.Boolean, path: "/on", line: 7, message: "key on is read as the boolean true, quote it to keep it a string"},
+			{kind: strict.Boolean, path: "/tags/0", line: 8, message: "y is read as the boolean true, quote it to keep it a string"},
+		},
+		{
+			{kind: strict.DuplicateKey, path: "/replicas", line: 11, message: `key "replicas" is declared on line 10 and again on line 11, which overrides it`},
+		},
+	}
+
+	if len(findings) != len(expected) {
+		t.Fatalf("expected findings for %d documents, got %v", len(expected), findings)
+	}
+	for i := range expected {
+		if len(findings[i]) != len(expected[i]) {
+			t.Fatalf("document %d: expected %d findings, got %v", i, len(expected[i]), findings[i])
+		}
+		for j, want := range expected[i] {
+			got := findings[i][j]
+			if got.Kind != want.kind || location.Pointer(got.Path) != want.path || got.Position.Line != want.line || got.Message != want.message {
+				t.Errorf("document %d finding %d: got %s at %s on line %d: %q, want %s at %s on line %d: %q",
+					i, j, got.Kind, location.Pointer(got.Path), got.Position.Line, got.Message, want.kind, want.path, want.line, want.message)
+			}
+		}
+	}
+}
+
+func TestYAMLFix(t *testing.T) {
+	setImage := func(value any) (any, error) {
+		container := value.(map[string]any)["containers"].([]any)[0].(map[string]any)
+		container["image"] = "nginx:1.27"
+		container["securityContext"] = map[string]any{"runAsNonRoot": true}
+		return value, nil
+	}
+
+	tests := []struct {
+		desc     string
+		input    string
+		document int
+		fn       func(value any) (any, error)
+		expected string
+	}{
+		{
+			desc: "comments, blank lines and compact sequences are kept",
+			input: `# The pod.
+containers:
+- name: web # the frontend
+
+  image: nginx:latest
+  enabled: yes
+`,
+			fn: setImage,
+			expected: `# The pod.
+containers:
+- name: web # the frontend
+
+  image: nginx:1.27
+  enabled: yes
+  securityContext:
+    runAsNonRoot: true
+`,
+		},
+		{
+			desc: "only the fixed document is written again",
+			input: `a:     1
+---
+containers:
+    -   name: web
+        image: nginx:latest
+---
+b:  [1, 2]
+`,
+			document: 1,
+			fn:       setImage,
+			expected: `a:     1
+---
+containers:
+    - name: web
+      image: nginx:1.27
+      securityContext:
+        runAsNonRoot: true
+---
+b:  [1, 2]
+`,
+		},
+		{
+			desc:  "anchors and aliases are kept",
+			input: "base: &base\n  cpu: 1\nweb:\n  <<: *base\n  memory: 1Gi\n",
+			fn: func(value any) (any, error) {
+				value.(map[string]any)["web"].(map[string]any)["memory"] = "2Gi"
+				return value, nil
+			},
+			expected: "base: &base\n  cpu: 1\nweb:\n  <<: *base\n  memory: 2Gi\n",
+		},
+		{
+			desc:  "unchanged documents are not written again",
+			input: "a:     1\n",
+			fn: func(value any) (any, error) {
+				return value, nil
+			},
+			expected: "a:     1\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			fixed, err := (&yaml.Parser{}).Fix([]byte(test.input), test.document, test.fn)
+			if err != nil {
+				t.Fatalf("fix: %v", err)
+			}
+
+			if string(fixed) != test.expected {
+				t.Errorf("unexpected fixed document:\n%s\nwant:\n%s", fixed, test.expected)
+			}
+		})
+	}
+
+	if _, err := (&yaml.Parser{}).Fix([]byte("a: 1\n"), 1, setImage); err == nil {
+		t.Error("expected an error when the document does not exist")
+	}
+}
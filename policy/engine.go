@@ -3,14 +3,24 @@ package policy
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/open-policy-agent/conftest/output"
 	"github.com/open-policy-agent/conftest/parser"
+	"github.com/open-policy-agent/conftest/parser/kubernetes"
+	"github.com/open-policy-agent/conftest/parser/location"
+	"github.com/open-policy-agent/conftest/parser/strict"
+	"github.com/open-policy-agent/conftest/parser/suppression"
 
 	"github.com/open-policy-agent/opa/v1/ast"
 	"github.com/open-policy-agent/opa/v1/bundle"
@@ -22,6 +32,7 @@ import (
 	"github.com/open-policy-agent/opa/v1/topdown/cache"
 	"github.com/open-policy-agent/opa/v1/topdown/print"
 	"github.com/open-policy-agent/opa/v1/version"
+	"golang.org/x/sync/errgroup"
 )
 
 // Engine represents the policy engine.
@@ -33,7 +44,23 @@ type Engine struct {
 	store                 storage.Store
 	policies              map[string]string
 	docs                  map[string]string
+	locations             map[string]*parser.Locations
+	suppressions          map[string][][]suppression.Directive
+	requirements          suppression.Requirements
+	objects               map[string][]*kubernetes.Object
+	validations           map[string][]*kubernetes.Validation
+	findings              map[string][][]strict.Finding
+	parallelism           int
 	enableInterQueryCache bool
+
+	// preparedQueries holds the sets of queries that have already been
+	// prepared for evaluation and are not in use, see getPreparedQueries.
+	preparedQueriesMu sync.Mutex
+	preparedQueries   []*preparedQueries
+
+	// exceptionNamespaces holds whether each namespace declares exceptions,
+	// see declaresExceptions.
+	exceptionNamespaces sync.Map
 }
 
 // CompilerOptions defines the options for the Rego compiler.
@@ -43,6 +70,10 @@ type CompilerOptions struct {
 	Capabilities *ast.Capabilities
 }
 
+// pathField is the field of a result object that policies can use to report
+// the path of the offending value within the input, e.g. "spec.replicas".
+const pathField = "path"
+
 var (
 	warningRegex = regexp.MustCompile("^warn(_[a-zA-Z0-9]+)*$")
 	failureRegex = regexp.MustCompile("^(deny|violation)(_[a-zA-Z0-9]+)*$")
@@ -177,52 +208,132 @@ func (e *Engine) EnableInterQueryCache() {
 	e.enableInterQueryCache = true
 }
 
+// SetParallelism sets the maximum number of files that are evaluated at the
+// same time by Check. Values lower than one evaluate the files one by one.
+func (e *Engine) SetParallelism(n int) {
+	e.parallelism = n
+}
+
+// SetLocations sets the source locations of the configurations, as returned by
+// parser.ParseConfigurationsWithLocations. When set, results that report a path
+// but no explicit location are attributed to the line the path was declared at.
+func (e *Engine) SetLocations(locations map[string]*parser.Locations) {
+	e.locations = locations
+}
+
+// SetSuppressions sets the inline suppression directives of the configurations,
+// as returned by parser.ParseConfigurationsWithDetails. When set, the failures
+// and warnings of the rules named by a directive of the same document are
+// reported as exceptions, provided the directive meets the given requirements
+// and has not expired.
+func (e *Engine) SetSuppressions(suppressions map[string][][]suppression.Directive, requirements suppression.Requirements) {
+	e.suppressions = suppressions
+	e.requirements = requirements
+}
+
 // Check executes all of the loaded policies against the input and returns the results.
+// The configurations are evaluated concurrently, up to the parallelism of the engine.
 func (e *Engine) Check(ctx context.Context, configs map[string]interface{}, namespace string) (output.CheckResults, error) {
-	var checkResults output.CheckResults
-	for path, config := range configs {
-
-		// It is possible for a configuration to have multiple configurations. An example of this
-		// are multi-document yaml files where a single filepath represents multiple configs.
-		//
-		// If the current configuration contains multiple configurations, evaluate each policy
-		// independent from one another and aggregate the results under the same file name.
-		if subconfigs, exist := config.([]any); exist {
-
-			checkResult := output.CheckResult{
-				FileName:  path,
-				Namespace: namespace,
+	paths := make([]string, 0, len(configs))
+	for path := range configs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// Every file writes its result to its own index, so the results are
+	// returned in the same order regardless of when each evaluation finished.
+	checkResults := make(output.CheckResults, len(paths))
+	group, ctx := errgroup.WithContext(ctx)
+	group.SetLimit(max(e.parallelism, 1))
+	for i, path := range paths {
+		group.Go(func() error {
+			checkResult, err := e.checkFile(ctx, path, configs[path], namespace)
+			if err != nil {
+				return err
 			}
-			for _, subconfig := range subconfigs {
-				result, err := e.check(ctx, path, subconfig, namespace)
-				if err != nil {
-					return nil, fmt.Errorf("check: %w", err)
-				}
 
-				checkResult.Successes = checkResult.Successes + result.Successes
-				checkResult.Failures = append(checkResult.Failures, result.Failures...)
-				checkResult.Warnings = append(checkResult.Warnings, result.Warnings...)
-				checkResult.Exceptions = append(checkResult.Exceptions, result.Exceptions...)
-				checkResult.Queries = append(checkResult.Queries, result.Queries...)
-			}
-			checkResults = append(checkResults, checkResult)
-			continue
+			checkResults[i] = checkResult
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	return checkResults, nil
+}
+
+// checkFile executes all of the loaded policies against the configuration of a single file.
+func (e *Engine) checkFile(ctx context.Context, path string, config any, namespace string) (output.CheckResult, error) {
+
+	// It is possible for a configuration to have multiple configurations. An example of this
+	// are multi-document yaml files where a single filepath represents multiple configs.
+	//
+	// If the current configuration contains multiple configurations, evaluate each policy
+	// independent from one another and aggregate the results under the same file name.
+	if subconfigs, exist := config.([]any); exist {
+		checkResult := output.CheckResult{
+			FileName:  path,
+			Namespace: namespace,
 		}
+		for i, subconfig := range subconfigs {
+			locate := func() (location.Map, []string) {
+				return e.documentLocations(path, i)
+			}
+			suppressions := document(e.suppressions[path], i)
+			if documents := e.suppressions[path]; len(documents) == 1 {
+				suppressions = documents[0]
+			}
 
-		checkResult, err := e.check(ctx, path, config, namespace)
-		if err != nil {
-			return nil, fmt.Errorf("check: %w", err)
+			result, err := e.checkDocument(ctx, path, subconfig, namespace, i, locate, suppressions, document(e.objects[path], i))
+			if err != nil {
+				return output.CheckResult{}, err
+			}
+			aggregate(&checkResult, result)
 		}
 
-		checkResults = append(checkResults, checkResult)
+		return checkResult, nil
 	}
 
-	return checkResults, nil
+	locate := func() (location.Map, []string) {
+		return document(e.locations[path].Documents(), 0), nil
+	}
+
+	return e.checkDocument(ctx, path, config, namespace, 0, locate, document(e.suppressions[path], 0), document(e.objects[path], 0))
+}
+
+// checkDocument executes all of the loaded policies against a single document
+// of a file, whether it was parsed or streamed, and attributes the results to
+// the document: their locations, fixes, suppressions and object. The index is
+// that of the document in the file.
+func (e *Engine) checkDocument(ctx context.Context, path string, config any, namespace string, index int, locate locator, suppressions []suppression.Directive, object *kubernetes.Object) (output.CheckResult, error) {
+	result, err := e.check(ctx, path, config, namespace)
+	if err != nil {
+		return output.CheckResult{}, err
+	}
+
+	resolveLocations(result, path, locate)
+	locateFixes(result, index, locate)
+	result = e.suppress(result, suppressions)
+	identify(result, object)
+
+	return result, nil
+}
+
+// aggregate adds the results of a single document to the results of its file.
+func aggregate(checkResult *output.CheckResult, result output.CheckResult) {
+	checkResult.Successes += result.Successes
+	checkResult.Failures = append(checkResult.Failures, result.Failures...)
+	checkResult.Warnings = append(checkResult.Warnings, result.Warnings...)
+	checkResult.Exceptions = append(checkResult.Exceptions, result.Exceptions...)
+	checkResult.Queries = append(checkResult.Queries, result.Queries...)
+	checkResult.ExpiredExceptions = append(checkResult.ExpiredExceptions, result.ExpiredExceptions...)
 }
 
 // CheckCombined combines the input and evaluates the policies against the combined result.
 func (e *Engine) CheckCombined(ctx context.Context, configs map[string]any, namespace string) (output.CheckResult, error) {
-	combinedConfigs := parser.CombineConfigurations(configs)
+	combinedConfigs := parser.CombineConfigurationsWithObjects(configs, e.objects)
 
 	result, err := e.check(ctx, "Combined", combinedConfigs["Combined"], namespace)
 	if err != nil {
@@ -232,6 +343,107 @@ func (e *Engine) CheckCombined(ctx context.Context, configs map[string]any, name
 	return result, nil
 }
 
+// CheckStream executes all of the loaded policies in the given namespaces against the
+// documents of a single file, evaluating every document as soon as it is decoded by the
+// stream. The results of all documents are aggregated per namespace under the file name,
+// in the same way as Check aggregates the documents of a multi-document file. They are
+// followed by the results of validating the documents against their schemas and of
+// parsing them strictly, as reported by CheckSchemas and CheckStrict.
+func (e *Engine) CheckStream(ctx context.Context, path string, namespaces []string, stream func(fn parser.StreamFunc) error) (output.CheckResults, error) {
+	checkResults := make(output.CheckResults, len(namespaces))
+	for i, namespace := range namespaces {
+		checkResults[i] = output.CheckResult{
+			FileName:  path,
+			Namespace: namespace,
+		}
+	}
+
+	schemaResult := output.CheckResult{FileName: path, Namespace: schemaNamespace}
+	strictResult := output.CheckResult{FileName: path, Namespace: strictNamespace}
+	var validated, checked bool
+
+	evaluate := func(config any, index int, prefix []string, details parser.DocumentDetails, object *kubernetes.Object, validation *kubernetes.Validation) error {
+		locate := func() (location.Map, []string) {
+			return document(details.Locations.Documents(), 0), prefix
+		}
+		for i, namespace := range namespaces {
+			result, err := e.checkDocument(ctx, path, config, namespace, index, locate, details.Suppressions, object)
+			if err != nil {
+				return err
+			}
+			aggregate(&checkResults[i], result)
+		}
+
+		if validation != nil {
+			validated = true
+			aggregate(&schemaResult, validationResult(path, validation, object, locate))
+		}
+
+		return nil
+	}
+
+	// A document that is a list is only identified item by item when it is the
+	// only document of the file, see Check.
+	evaluateDocument := func(config any, index int, details parser.DocumentDetails) error {
+		if _, isList := config.([]any); isList {
+			return evaluate(config, index, nil, details, nil, nil)
+		}
+
+		return evaluate(config, index, nil, details, document(details.Objects, 0), document(details.Validations, 0))
+	}
+
+	// The first document is held back until the second document is decoded. A file
+	// that only contains a single document is checked as a whole, see Check.
+	var first any
+	var firstDetails parser.DocumentDetails
+	var documents int
+	err := stream(func(config any, details parser.DocumentDetails) error {
+		if details.Findings != nil {
+			checked = true
+			aggregate(&strictResult, findingsResult(path, details.Findings))
+		}
+
+		documents++
+		switch documents {
+		case 1:
+			first, firstDetails = config, details
+			return nil
+		case 2:
+			if err := evaluateDocument(first, 0, firstDetails); err != nil {
+				return err
+			}
+			first, firstDetails = nil, parser.DocumentDetails{}
+		}
+
+		return evaluateDocument(config, documents-1, details)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("stream: %w", err)
+	}
+
+	if documents == 1 {
+		if items, isList := first.([]any); isList {
+			for i, item := range items {
+				prefix := []string{strconv.Itoa(i)}
+				if err := evaluate(item, 0, prefix, firstDetails, document(firstDetails.Objects, i), document(firstDetails.Validations, i)); err != nil {
+					return nil, err
+				}
+			}
+		} else if err := evaluate(first, 0, nil, firstDetails, document(firstDetails.Objects, 0), document(firstDetails.Validations, 0)); err != nil {
+			return nil, err
+		}
+	}
+
+	if validated {
+		checkResults = append(checkResults, schemaResult)
+	}
+	if checked {
+		checkResults = append(checkResults, strictResult)
+	}
+
+	return checkResults, nil
+}
+
 // Namespaces returns all of the namespaces in the engine.
 func (e *Engine) Namespaces() []string {
 	var namespaces []string
@@ -297,7 +509,8 @@ func (e *Engine) Runtime() *ast.Term {
 }
 
 func (e *Engine) check(ctx context.Context, path string, config any, namespace string) (output.CheckResult, error) {
-	if err := e.addFileInfo(ctx, path); err != nil {
+	ctx, err := withFileInfo(ctx, path)
+	if err != nil {
 		return output.CheckResult{}, fmt.Errorf("add file info: %w", err)
 	}
 
@@ -342,6 +555,37 @@ func (e *Engine) check(ctx context.Context, path string, config any, namespace s
 		FileName:  path,
 		Namespace: namespace,
 	}
+
+	// Exceptions declared as objects can expire, in which case they are no
+	// longer honored, and a warning is reported in the week before they do.
+	// The exceptions depend on the input, so they are evaluated for every
+	// document, but only in the namespaces that declare any.
+	var timeBoxed []timeBoxedException
+	var legacy map[string]int
+	var timeBoxedQueryResult output.QueryResult
+	if e.declaresExceptions(ctx, namespace) {
+		timeBoxed, legacy, timeBoxedQueryResult, err = e.exceptions(ctx, inputValue, namespace)
+		if err != nil {
+			return output.CheckResult{}, fmt.Errorf("query exceptions: %w", err)
+		}
+	}
+	if len(timeBoxed) > 0 {
+		checkResult.Queries = append(checkResult.Queries, timeBoxedQueryResult)
+	}
+
+	now := time.Now()
+	var expiring []output.Result
+	for _, exception := range timeBoxed {
+		switch {
+		case exception.expired(now):
+			status := fmt.Sprintf("expired on %s", exception.expires.Format(time.DateOnly))
+			checkResult.ExpiredExceptions = append(checkResult.ExpiredExceptions, exception.result(timeBoxedQueryResult.Query, status))
+		case exception.expiring(now):
+			status := fmt.Sprintf("expires on %s", exception.expires.Format(time.DateOnly))
+			expiring = append(expiring, exception.result(timeBoxedQueryResult.Query, status))
+		}
+	}
+
 	var successes int
 	for _, rule := range rules {
 
@@ -354,18 +598,33 @@ func (e *Engine) check(ctx context.Context, path string, config any, namespace s
 			return output.CheckResult{}, fmt.Errorf("query exception: %w", err)
 		}
 
+		// The query also matches the values of the exceptions declared as
+		// objects, such as their owner, so only as many of its results are
+		// kept as the rule is named by exceptions declared as lists.
 		var exceptions []output.Result
 		for _, exceptionResult := range exceptionQueryResult.Results {
 
 			// When an exception is found, set the message of the exception
 			// to the query that triggered the exception so that it is known
 			// which exception was trigged.
-			if exceptionResult.Passed() {
+			if exceptionResult.Passed() && len(exceptions) < legacy[removeRulePrefix(rule)] {
 				exceptionResult.Message = exceptionQuery
 				exceptions = append(exceptions, exceptionResult)
 			}
 		}
 
+		for _, exception := range timeBoxed {
+			if !exception.appliesTo(removeRulePrefix(rule)) || exception.expired(now) {
+				continue
+			}
+
+			var status string
+			if !exception.expires.IsZero() {
+				status = fmt.Sprintf("until %s", exception.expires.Format(time.DateOnly))
+			}
+			exceptions = append(exceptions, exception.result(timeBoxedQueryResult.Query, status))
+		}
+
 		ruleQuery := fmt.Sprintf("data.%s.%s", namespace, rule)
 		ruleQueryResult, err := e.query(ctx, inputValue, ruleQuery)
 		if err != nil {
@@ -411,88 +670,216 @@ func (e *Engine) check(ctx context.Context, path string, config any, namespace s
 	}
 
 	checkResult.Successes = successes
+
+	// The warnings of expiring exceptions are not the result of a rule, so they
+	// are only added once the successes have been counted.
+	checkResult.Warnings = append(checkResult.Warnings, expiring...)
+
 	return checkResult, nil
 }
 
-// addFileInfo adds the file name and directory to data.conftest.file so that it is accessible to
-// the policies to be used during evaluation.
-func (e *Engine) addFileInfo(ctx context.Context, path string) error {
-	abs, err := filepath.Abs(path)
-	if err != nil {
-		return fmt.Errorf("get absolute path: %w", err)
-	}
-	if e.store == nil {
-		e.store = inmem.New()
+// document returns the details of the document at the given index, such as
+// its locations, or the zero value when the details of the document are unknown.
+func document[T any](documents []T, index int) T {
+	if index >= len(documents) {
+		var zero T
+		return zero
 	}
-	tx, err := e.store.NewTransaction(ctx, storage.WriteParams)
-	if err != nil {
-		return fmt.Errorf("begin store tx: %w", err)
-	}
-	if err := storage.MakeDir(ctx, e.store, tx, storage.Path{"conftest"}); err != nil {
-		return fmt.Errorf("create dir in store: %w", err)
+
+	return documents[index]
+}
+
+// documentLocations returns the locations of the document at the given index
+// of a file with multiple documents, and the prefix of the paths reported for
+// it. A single document with a list at its root is also checked item by item,
+// in which case the reported paths are relative to the item.
+func (e *Engine) documentLocations(path string, index int) (location.Map, []string) {
+	documents := e.locations[path].Documents()
+	if len(documents) == 1 {
+		return documents[0], []string{strconv.Itoa(index)}
 	}
-	if err := e.store.Write(
-		ctx, tx, storage.AddOp, storage.Path{"conftest", "file"},
-		map[string]string{
-			"name": filepath.Base(abs),
-			"dir":  filepath.Dir(abs),
-		},
-	); err != nil {
-		return fmt.Errorf("write file info to storage: %w", err)
+
+	return document(documents, index), nil
+}
+
+// locator returns the locations of a checked document and the prefix of the
+// paths reported for it. It is only called once a result needs them, as the
+// locations of a parsed file are found by parsing it again.
+type locator func() (location.Map, []string)
+
+// locateFixes sets the document the fixes of the failures and warnings apply
+// to, which is the document at the given index of the file, unless the items
+// of a single document were checked one by one, in which case the fixes apply
+// to the item at the prefix.
+func locateFixes(checkResult output.CheckResult, index int, locate locator) {
+	var located bool
+	var prefix []string
+	for _, results := range [][]output.Result{checkResult.Failures, checkResult.Warnings} {
+		for i := range results {
+			if results[i].Fix == nil {
+				continue
+			}
+
+			if !located {
+				_, prefix = locate()
+				located = true
+			}
+			if prefix != nil {
+				results[i].Fix.Path = location.Pointer(prefix)
+			} else {
+				results[i].Fix.Document = index
+			}
+		}
 	}
-	if err := e.store.Commit(ctx, tx); err != nil {
-		return fmt.Errorf("commit file info to storage: %w", err)
+}
+
+// resolveLocations sets the location of the failures and warnings that report
+// the path of the offending value, but did not set a location themselves. The
+// locations are those of the document that was checked, and the prefix is
+// prepended to every reported path.
+func resolveLocations(checkResult output.CheckResult, path string, locate locator) {
+	var located bool
+	var locations location.Map
+	var prefix []string
+	for _, results := range [][]output.Result{checkResult.Failures, checkResult.Warnings} {
+		for i := range results {
+			if results[i].Location != nil {
+				continue
+			}
+
+			valuePath, ok := results[i].Metadata[pathField]
+			if !ok {
+				continue
+			}
+
+			if !located {
+				locations, prefix = locate()
+				located = true
+			}
+			if locations == nil {
+				return
+			}
+
+			segments, err := location.ParsePath(valuePath)
+			if err != nil {
+				continue
+			}
+
+			pos, ok := locations.Lookup(slices.Concat(prefix, segments))
+			if !ok {
+				continue
+			}
+
+			results[i].Location = &output.Location{
+				File:   path,
+				Line:   json.Number(strconv.Itoa(pos.Line)),
+				Column: json.Number(strconv.Itoa(pos.Column)),
+			}
+		}
 	}
+}
 
-	return nil
+// preparedQueries is a set of queries that have been prepared for evaluation. A
+// set is only used by a single evaluation at a time, as the built-in errors of a
+// prepared query are collected into a list that is shared between evaluations.
+type preparedQueries struct {
+	store   storage.Store
+	queries map[string]*preparedQuery
 }
 
-// query is a low-level method that returns the result of executing a single query against the input.
-//
-// Example queries could include:
-// data.main.deny to query the deny rule in the main namespace
-// data.main.warn to query the warn rule in the main namespace
-func (e *Engine) query(ctx context.Context, input ast.Value, query string) (output.QueryResult, error) {
-	ph := printHook{s: &[]string{}}
-	builtInErrors := &[]topdown.Error{}
-	options := []func(r *rego.Rego){
-		rego.ParsedInput(input),
+type preparedQuery struct {
+	query         rego.PreparedEvalQuery
+	builtinErrors *[]topdown.Error
+
+	// rules are the bodies of the rule the query refers to, if any.
+	rules []*ast.Rule
+}
+
+// prepare returns the prepared query for the given query, preparing it the first
+// time it is requested. Compiling a query is expensive, so preparing a query once
+// and reusing it for every input considerably speeds up checking many files.
+func (e *Engine) prepare(ctx context.Context, prepared *preparedQueries, query string) (*preparedQuery, error) {
+	if pq, ok := prepared.queries[query]; ok {
+		return pq, nil
+	}
+
+	builtinErrors := &[]topdown.Error{}
+	regoInstance := rego.New(
 		rego.Query(query),
 		rego.Compiler(e.Compiler()),
-		rego.Store(e.Store()),
+		rego.Store(prepared.store),
 		rego.Runtime(e.Runtime()),
-		rego.Trace(e.trace),
-		rego.PrintHook(ph),
-		rego.BuiltinErrorList(builtInErrors),
+		rego.BuiltinErrorList(builtinErrors),
+	)
+
+	pq, err := regoInstance.PrepareForEval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("prepare for eval: %w", err)
 	}
-	if e.enableInterQueryCache {
-		options = append(options, rego.InterQueryBuiltinCache(cache.NewInterQueryCacheWithContext(ctx, nil)))
+
+	var rules []*ast.Rule
+	if ref, err := ast.ParseRef(query); err == nil {
+		rules = e.compiler.GetRulesExact(ref)
 	}
 
-	regoInstance := rego.New(options...)
-	resultSet, err := regoInstance.Eval(ctx)
-	if err != nil {
-		return output.QueryResult{}, fmt.Errorf("evaluating policy: %w", err)
+	prepared.queries[query] = &preparedQuery{
+		query:         pq,
+		builtinErrors: builtinErrors,
+		rules:         rules,
 	}
 
-	if e.builtinErrors && len(*builtInErrors) > 0 {
-		return output.QueryResult{}, fmt.Errorf("built-in error: %+v", (*builtInErrors))
+	return prepared.queries[query], nil
+}
+
+// getPreparedQueries returns a set of prepared queries that is not in use by
+// any other evaluation. The set must be returned with putPreparedQueries. The
+// sets are kept for the lifetime of the engine, so there are at most as many of
+// them as there are concurrent evaluations, and every query is prepared at most
+// once per set.
+func (e *Engine) getPreparedQueries() *preparedQueries {
+	e.preparedQueriesMu.Lock()
+	if n := len(e.preparedQueries); n > 0 {
+		prepared := e.preparedQueries[n-1]
+		e.preparedQueries = e.preparedQueries[:n-1]
+		e.preparedQueriesMu.Unlock()
+		return prepared
 	}
+	e.preparedQueriesMu.Unlock()
 
-	// After the evaluation of the policy, the results of the trace (stdout) will be populated
-	// for the query. Once populated, format the trace results into a human readable format.
-	buf := new(bytes.Buffer)
-	rego.PrintTrace(buf, regoInstance)
+	// The file information is overlaid on top of the documents in the store
+	// for every evaluation, see withFileInfo.
+	base := e.store
+	if base == nil {
+		base = inmem.New()
+	}
 
-	var traces []string
-	for _, line := range strings.Split(buf.String(), "\n") {
-		if len(line) > 0 {
-			traces = append(traces, line)
-		}
+	return &preparedQueries{
+		store:   &fileInfoStore{Store: base},
+		queries: make(map[string]*preparedQuery),
 	}
+}
+
+func (e *Engine) putPreparedQueries(prepared *preparedQueries) {
+	e.preparedQueriesMu.Lock()
+	defer e.preparedQueriesMu.Unlock()
+
+	e.preparedQueries = append(e.preparedQueries, prepared)
+}
+
+// query is a low-level method that returns the result of executing a single query against the input.
+//
+// Example queries could include:
+// data.main.deny to query the deny rule in the main namespace
+// data.main.warn to query the warn rule in the main namespace
+func (e *Engine) query(ctx context.Context, input ast.Value, query string) (output.QueryResult, error) {
+	evaluated, err := e.eval(ctx, input, query)
+	if err != nil {
+		return output.QueryResult{}, err
+	}
+	rules := evaluated.rules
 
 	var results []output.Result
-	for _, result := range resultSet {
+	for _, result := range evaluated.resultSet {
 		for _, expression := range result.Expressions {
 
 			// Rego rules that are intended for evaluation should return a slice of values.
@@ -520,6 +907,8 @@ func (e *Engine) query(ctx context.Context, input ast.Value, query string) (outp
 							"query": query,
 						},
 					}
+					result.Rule = e.resultRule(rules, v)
+					result.Severity = ruleSeverity(result)
 					results = append(results, result)
 
 				// Policies that return metadata (e.g. `deny contains {"msg": msg} if`)
@@ -531,6 +920,8 @@ func (e *Engine) query(ctx context.Context, input ast.Value, query string) (outp
 
 					// Safe to set as Metadata map is initialized by NewResult
 					result.Metadata["query"] = query
+					result.Rule = e.resultRule(rules, v)
+					result.Severity = ruleSeverity(result)
 
 					results = append(results, result)
 				}
@@ -541,13 +932,89 @@ func (e *Engine) query(ctx context.Context, input ast.Value, query string) (outp
 	queryResult := output.QueryResult{
 		Query:   query,
 		Results: results,
-		Traces:  traces,
-		Outputs: *ph.s,
+		Traces:  evaluated.traces,
+		Outputs: evaluated.outputs,
 	}
 
 	return queryResult, nil
 }
 
+// evaluation is the outcome of evaluating a single query against the input,
+// before its values are converted into results.
+type evaluation struct {
+	resultSet rego.ResultSet
+	rules     *ruleTracer
+	traces    []string
+	outputs   []string
+}
+
+// eval evaluates a single query against the input, see query.
+func (e *Engine) eval(ctx context.Context, input ast.Value, query string) (evaluation, error) {
+	prepared := e.getPreparedQueries()
+	defer e.putPreparedQueries(prepared)
+
+	pq, err := e.prepare(ctx, prepared, query)
+	if err != nil {
+		return evaluation{}, fmt.Errorf("prepare query: %w", err)
+	}
+
+	ph := printHook{s: &[]string{}}
+	options := []rego.EvalOption{
+		rego.EvalParsedInput(input),
+		rego.EvalPrintHook(ph),
+	}
+
+	var tracer *topdown.BufferTracer
+	if e.trace {
+		tracer = topdown.NewBufferTracer()
+		options = append(options, rego.EvalQueryTracer(tracer))
+	}
+
+	if e.enableInterQueryCache {
+		options = append(options, rego.EvalInterQueryBuiltinCache(cache.NewInterQueryCacheWithContext(ctx, nil)))
+	}
+
+	// When the query refers to a rule, keep track of which rule body produced
+	// each of the results.
+	rules := newRuleTracer(pq.rules)
+	if rules.Enabled() {
+		options = append(options, rego.EvalQueryTracer(rules))
+	}
+
+	*pq.builtinErrors = (*pq.builtinErrors)[:0]
+	resultSet, err := pq.query.Eval(ctx, options...)
+	if err != nil {
+		return evaluation{}, fmt.Errorf("evaluating policy: %w", err)
+	}
+
+	if e.builtinErrors && len(*pq.builtinErrors) > 0 {
+		return evaluation{}, fmt.Errorf("built-in error: %+v", (*pq.builtinErrors))
+	}
+
+	// After the evaluation of the policy, the results of the trace (stdout) will be populated
+	// for the query. Once populated, format the trace results into a human readable format.
+	var traces []string
+	if tracer != nil {
+		buf := new(bytes.Buffer)
+		topdown.PrettyTrace(buf, *tracer)
+
+		for _, line := range strings.Split(buf.String(), "\n") {
+			if len(line) > 0 {
+				traces = append(traces, line)
+			}
+		}
+	}
+
+	evaluated := evaluation{
+		resultSet: resultSet,
+		rules:     rules,
+		traces:    traces,
+		outputs:   *ph.s,
+	}
+
+	return evaluated, nil
+}
+
 func isWarning(rule string) bool {
 	return warningRegex.MatchString(rule)
 }
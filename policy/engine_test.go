@@ -2,14 +2,21 @@ package policy
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
 	"sort"
 	"testing"
 	"testing/fstest"
+	"time"
 
+	"github.com/open-policy-agent/conftest/output"
 	"github.com/open-policy-agent/conftest/parser"
+	"github.com/open-policy-agent/conftest/parser/kubernetes"
+	"github.com/open-policy-agent/conftest/parser/location"
+	"github.com/open-policy-agent/conftest/parser/strict"
+	"github.com/open-policy-agent/conftest/parser/suppression"
 	"github.com/open-policy-agent/opa/v1/ast"
 	"github.com/open-policy-agent/opa/v1/loader"
 )
@@ -295,8 +302,8 @@ deny contains {"msg": msg} if {
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
 			var e Engine
-			ctx := context.Background()
-			if err := e.addFileInfo(ctx, tt.input); err != nil {
+			ctx, err := withFileInfo(context.Background(), tt.input)
+			if err != nil {
 				t.Error(err)
 			}
 
@@ -668,3 +675,890 @@ violation contains result if {
 		})
 	}
 }
+
+func TestCheckResolvesLocations(t *testing.T) {
+	ctx := context.Background()
+
+	files := fstest.MapFS{
+		"policy.rego": &fstest.MapFile{
+			Data: []byte(`package main
+
+deny contains {"msg": "replicas must be at least 2", "path": "spec.replicas"} if {
+	input.spec.replicas < 2
+}
+
+deny contains {"msg": "explicit location", "path": "kind", "_loc": {"file": "other.yaml", "line": 42}} if {
+	input.kind == "Deployment"
+}
+
+warn contains {"msg": "missing labels", "path": ["metadata", "labels"]} if {
+	not input.metadata.labels
+}`),
+		},
+	}
+
+	l := loader.NewFileLoader().WithFS(files)
+	pols, err := l.All([]string{"policy.rego"})
+	if err != nil {
+		t.Fatalf("Load policies: %v", err)
+	}
+
+	engine := Engine{
+		modules:  pols.ParsedModules(),
+		compiler: ast.NewCompiler().WithEnablePrintStatements(true),
+	}
+	engine.compiler.Compile(engine.modules)
+	if engine.compiler.Failed() {
+		t.Fatalf("Compiler error: %v", engine.compiler.Errors)
+	}
+
+	configFile := filepath.Join(t.TempDir(), "deployment.yaml")
+	config := `kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1
+`
+	if err := os.WriteFile(configFile, []byte(config), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	configs, locations, err := parser.ParseConfigurationsWithLocations([]string{configFile}, "")
+	if err != nil {
+		t.Fatalf("parse configurations: %v", err)
+	}
+	engine.SetLocations(locations)
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	want := map[string]string{
+		"replicas must be at least 2": configFile + " L5",
+		"explicit location":           "other.yaml L42",
+		"missing labels":              configFile + " L2",
+	}
+
+	got := make(map[string]string)
+	for _, result := range append(results[0].Failures, results[0].Warnings...) {
+		if result.Location == nil {
+			t.Fatalf("result %q has no location", result.Message)
+		}
+		got[result.Message] = result.Location.String()
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected locations. got %v, want %v", got, want)
+	}
+}
+
+func TestCheckLocatesOnlyWhenNeeded(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		policy     string
+		wantLocate bool
+	}{
+		{
+			name:       "result without a path",
+			policy:     `deny contains "denied" if true`,
+			wantLocate: false,
+		},
+		{
+			name:       "result with a path",
+			policy:     `deny contains {"msg": "denied", "path": ["spec"]} if true`,
+			wantLocate: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compiler, err := ast.CompileModules(map[string]string{"policy.rego": "package main\n\n" + tt.policy + "\n"})
+			if err != nil {
+				t.Fatalf("compile modules: %v", err)
+			}
+
+			engine := Engine{
+				modules:  compiler.Modules,
+				compiler: compiler,
+			}
+
+			var located bool
+			engine.SetLocations(map[string]*parser.Locations{
+				"config.yaml": parser.NewLocations(func() []location.Map {
+					located = true
+					return []location.Map{{"/spec": {Line: 2, Column: 1}}}
+				}),
+			})
+
+			if _, err := engine.Check(ctx, map[string]any{"config.yaml": map[string]any{}}, "main"); err != nil {
+				t.Fatalf("check: %v", err)
+			}
+
+			if located != tt.wantLocate {
+				t.Errorf("got located %v, want %v", located, tt.wantLocate)
+			}
+		})
+	}
+}
+
+func TestCheckParallel(t *testing.T) {
+	ctx := context.Background()
+
+	modules := map[string]string{
+		"test.rego": `package main
+
+deny contains msg if {
+	msg := data.conftest.file.name
+}
+`,
+	}
+
+	compiler, err := ast.CompileModules(modules)
+	if err != nil {
+		t.Fatalf("compile modules: %v", err)
+	}
+
+	engine := Engine{
+		modules:  compiler.Modules,
+		compiler: compiler,
+	}
+	engine.SetParallelism(4)
+
+	configs := make(map[string]any)
+	for i := range 50 {
+		configs[fmt.Sprintf("config-%02d.yaml", i)] = map[string]any{"index": i}
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	if len(results) != len(configs) {
+		t.Fatalf("got %d results, want %d", len(results), len(configs))
+	}
+
+	for i, result := range results {
+		want := fmt.Sprintf("config-%02d.yaml", i)
+		if result.FileName != want {
+			t.Errorf("result %d: got file %q, want %q", i, result.FileName, want)
+		}
+		if len(result.Failures) != 1 || result.Failures[0].Message != want {
+			t.Errorf("result %d: got failures %v, want the file name %q", i, result.Failures, want)
+		}
+	}
+}
+
+func TestCheckStream(t *testing.T) {
+	ctx := context.Background()
+
+	policies := []string{"../examples/kubernetes/policy"}
+	engine, err := Load(policies, testOptions(t))
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	listFile := filepath.Join(t.TempDir(), "list.yaml")
+	list := `- kind: Service
+  metadata:
+    name: first
+- kind: Deployment
+  metadata:
+    name: second
+`
+	if err := os.WriteFile(listFile, []byte(list), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{
+			name:   "multiple documents",
+			config: "../examples/kubernetes/deployment+service.yaml",
+		},
+		{
+			name:   "single document",
+			config: "../examples/kubernetes/service.yaml",
+		},
+		{
+			name:   "list of documents",
+			config: listFile,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs, locations, err := parser.ParseConfigurationsWithLocations([]string{tt.config}, "")
+			if err != nil {
+				t.Fatalf("loading configs: %v", err)
+			}
+			engine.SetLocations(locations)
+
+			want, err := engine.Check(ctx, configs, "main")
+			if err != nil {
+				t.Fatalf("check: %v", err)
+			}
+
+			stream := func(fn parser.StreamFunc) error {
+				return parser.StreamConfiguration(tt.config, "", fn)
+			}
+
+			got, err := engine.CheckStream(ctx, tt.config, []string{"main"}, stream)
+			if err != nil {
+				t.Fatalf("check stream: %v", err)
+			}
+
+			// Queries contain the input of each evaluation, and the order in which rules
+			// are evaluated is not deterministic, so neither is part of the comparison.
+			for _, results := range []output.CheckResults{want, got} {
+				for i := range results {
+					results[i].Queries = nil
+					for _, r := range [][]output.Result{results[i].Failures, results[i].Warnings} {
+						sort.Slice(r, func(a, b int) bool { return r[a].Message < r[b].Message })
+					}
+				}
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("unexpected results. got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestCheckRuleIdentity(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir := t.TempDir()
+	policyFile := filepath.Join(policyDir, "policy.rego")
+	policy := `package main
+
+# METADATA
+# title: Replicas
+# description: Deployments need at least two replicas
+# custom:
+#   id: K8S-001
+#   severity: high
+deny contains msg if {
+	input.spec.replicas < 2
+	msg := "too few replicas"
+}
+
+deny contains result if {
+	not input.metadata.labels
+	result := {"msg": "missing labels"}
+}
+`
+	if err := os.WriteFile(policyFile, []byte(policy), 0600); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+
+	opts := CompilerOptions{
+		Capabilities: ast.CapabilitiesForThisVersion(),
+		RegoVersion:  "v1",
+	}
+	engine, err := Load([]string{policyDir}, opts)
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	configs := map[string]any{
+		"deployment.yaml": map[string]any{"spec": map[string]any{"replicas": 1}},
+	}
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	want := map[string]output.Rule{
+		"too few replicas": {
+			Package:     "main",
+			Name:        "deny",
+			File:        policyFile,
+			Row:         9,
+			ID:          "K8S-001",
+			Title:       "Replicas",
+			Description: "Deployments need at least two replicas",
+			Severity:    "high",
+		},
+		"missing labels": {
+			Package: "main",
+			Name:    "deny",
+			File:    policyFile,
+			Row:     14,
+		},
+	}
+
+	got := make(map[string]output.Rule)
+	for _, failure := range results[0].Failures {
+		if failure.Rule == nil {
+			t.Fatalf("failure %q has no rule", failure.Message)
+		}
+		got[failure.Message] = *failure.Rule
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected rules. got %+v, want %+v", got, want)
+	}
+}
+
+func TestCheckSeverity(t *testing.T) {
+	ctx := context.Background()
+
+	modules := map[string]string{
+		"policy.rego": `package main
+
+# METADATA
+# custom:
+#   severity: high
+deny contains "annotated" if true
+
+# METADATA
+# custom:
+#   severity: high
+deny contains {"msg": "overridden", "severity": "low"} if true
+
+warn contains "undeclared" if true
+`,
+	}
+
+	compiler, err := ast.CompileModulesWithOpt(modules, ast.CompileOpts{ParserOptions: ast.ParserOptions{ProcessAnnotation: true}})
+	if err != nil {
+		t.Fatalf("compile modules: %v", err)
+	}
+
+	engine := Engine{
+		modules:  compiler.Modules,
+		compiler: compiler,
+	}
+
+	results, err := engine.Check(ctx, map[string]any{"config.yaml": map[string]any{}}, "main")
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	want := map[string]string{
+		"annotated":  output.SeverityHigh,
+		"overridden": output.SeverityLow,
+		"undeclared": "",
+	}
+
+	got := make(map[string]string)
+	for _, result := range append(results[0].Failures, results[0].Warnings...) {
+		got[result.Message] = result.Severity
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected severities. got %v, want %v", got, want)
+	}
+}
+
+func TestCheckSuppressions(t *testing.T) {
+	ctx := context.Background()
+
+	modules := map[string]string{
+		"policy.rego": `package main
+
+deny_privileged contains "privileged" if true
+
+warn_latest contains "latest" if true
+
+# METADATA
+# custom:
+#   id: K8S-001
+deny contains "replicas" if true
+`,
+	}
+
+	compiler, err := ast.CompileModulesWithOpt(modules, ast.CompileOpts{ParserOptions: ast.ParserOptions{ProcessAnnotation: true}})
+	if err != nil {
+		t.Fatalf("compile modules: %v", err)
+	}
+
+	expired := time.Now().AddDate(0, 0, -1)
+
+	tests := []struct {
+		name           string
+		directives     []suppression.Directive
+		requirements   suppression.Requirements
+		wantFailures   []string
+		wantWarnings   []string
+		wantExceptions []string
+	}{
+		{
+			name:         "no directives",
+			wantFailures: []string{"privileged", "replicas"},
+			wantWarnings: []string{"latest"},
+		},
+		{
+			name: "rule names",
+			directives: []suppression.Directive{
+				{Rules: []string{"deny_privileged", "latest"}, Reason: "legacy", Line: 3},
+			},
+			wantFailures:   []string{"replicas"},
+			wantExceptions: []string{"latest (ignored at line 3: legacy)", "privileged (ignored at line 3: legacy)"},
+		},
+		{
+			name: "rule id",
+			directives: []suppression.Directive{
+				{Rules: []string{"K8S-001"}, Line: 1},
+			},
+			wantFailures:   []string{"privileged"},
+			wantWarnings:   []string{"latest"},
+			wantExceptions: []string{"replicas (ignored at line 1)"},
+		},
+		{
+			name: "missing reason",
+			directives: []suppression.Directive{
+				{Rules: []string{"deny_privileged"}, Line: 1},
+			},
+			requirements: suppression.Requirements{Reason: true},
+			wantFailures: []string{"privileged", "replicas"},
+			wantWarnings: []string{"latest"},
+		},
+		{
+			name: "expired",
+			directives: []suppression.Directive{
+				{Rules: []string{"deny_privileged"}, Reason: "legacy", Expires: expired, Line: 1},
+			},
+			wantFailures: []string{"privileged", "replicas"},
+			wantWarnings: []string{"latest"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := Engine{
+				modules:  compiler.Modules,
+				compiler: compiler,
+			}
+			engine.SetSuppressions(map[string][][]suppression.Directive{"config.yaml": {tt.directives}}, tt.requirements)
+
+			results, err := engine.Check(ctx, map[string]any{"config.yaml": map[string]any{}}, "main")
+			if err != nil {
+				t.Fatalf("check: %v", err)
+			}
+
+			messages := func(results []output.Result) []string {
+				var messages []string
+				for _, result := range results {
+					messages = append(messages, result.Message)
+				}
+				sort.Strings(messages)
+				return messages
+			}
+
+			if got := messages(results[0].Failures); !reflect.DeepEqual(got, tt.wantFailures) {
+				t.Errorf("unexpected failures. got %v, want %v", got, tt.wantFailures)
+			}
+			if got := messages(results[0].Warnings); !reflect.DeepEqual(got, tt.wantWarnings) {
+				t.Errorf("unexpected warnings. got %v, want %v", got, tt.wantWarnings)
+			}
+			if got := messages(results[0].Exceptions); !reflect.DeepEqual(got, tt.wantExceptions) {
+				t.Errorf("unexpected exceptions. got %v, want %v", got, tt.wantExceptions)
+			}
+		})
+	}
+
+	t.Run("multiple documents", func(t *testing.T) {
+		engine := Engine{
+			modules:  compiler.Modules,
+			compiler: compiler,
+		}
+		suppressions := [][]suppression.Directive{
+			nil,
+			{{Rules: []string{"deny_privileged"}, Line: 5}},
+		}
+		engine.SetSuppressions(map[string][][]suppression.Directive{"config.yaml": suppressions}, suppression.Requirements{})
+
+		configs := map[string]any{"config.yaml": []any{map[string]any{}, map[string]any{}}}
+		results, err := engine.Check(ctx, configs, "main")
+		if err != nil {
+			t.Fatalf("check: %v", err)
+		}
+
+		if len(results[0].Exceptions) != 1 {
+			t.Fatalf("expected only the second document to be suppressed, got exceptions %v", results[0].Exceptions)
+		}
+		if len(results[0].Failures) != 3 {
+			t.Errorf("expected 3 failures, got %v", results[0].Failures)
+		}
+	})
+}
+
+func TestCheckTimeBoxedExceptions(t *testing.T) {
+	ctx := context.Background()
+
+	date := func(days int) string {
+		return time.Now().AddDate(0, 0, days).Format(time.DateOnly)
+	}
+
+	dir := t.TempDir()
+	policy := fmt.Sprintf(`package main
+
+deny_run_as_root contains "root" if true
+
+deny_latest contains "latest" if true
+
+deny_privileged contains "privileged" if true
+
+exception contains {"rules": ["run_as_root"], "owner": "team-a", "expires": %q} if true
+
+exception contains {"rules": ["latest"], "owner": "team-b", "expires": %q} if true
+
+exception contains e if {
+	some e in data.exceptions
+}
+`, date(30), date(3))
+	if err := os.WriteFile(filepath.Join(dir, "policy.rego"), []byte(policy), 0600); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+
+	data := fmt.Sprintf(`{"exceptions": [{"rules": ["privileged"], "owner": "team-c", "expires": %q, "ticket": "OPS-1"}]}`, date(-1))
+	dataFile := filepath.Join(dir, "data.json")
+	if err := os.WriteFile(dataFile, []byte(data), 0600); err != nil {
+		t.Fatalf("write data: %v", err)
+	}
+
+	opts := CompilerOptions{
+		Capabilities: ast.CapabilitiesForThisVersion(),
+		RegoVersion:  "v1",
+	}
+	engine, err := LoadWithData([]string{filepath.Join(dir, "policy.rego")}, []string{dataFile}, opts)
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	results, err := engine.Check(ctx, map[string]any{"config.yaml": map[string]any{}}, "main")
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	messages := func(results []output.Result) []string {
+		var messages []string
+		for _, result := range results {
+			messages = append(messages, result.Message)
+		}
+		sort.Strings(messages)
+		return messages
+	}
+
+	result := results[0]
+	if got, want := messages(result.Failures), []string{"privileged"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected failures. got %v, want %v", got, want)
+	}
+
+	wantExceptions := []string{
+		fmt.Sprintf(`exception for rules ["latest"] owned by team-b until %s`, date(3)),
+		fmt.Sprintf(`exception for rules ["run_as_root"] owned by team-a until %s`, date(30)),
+	}
+	if got := messages(result.Exceptions); !reflect.DeepEqual(got, wantExceptions) {
+		t.Errorf("unexpected exceptions. got %v, want %v", got, wantExceptions)
+	}
+
+	wantWarnings := []string{fmt.Sprintf(`exception for rules ["latest"] owned by team-b expires on %s`, date(3))}
+	if got := messages(result.Warnings); !reflect.DeepEqual(got, wantWarnings) {
+		t.Errorf("unexpected warnings. got %v, want %v", got, wantWarnings)
+	}
+
+	if len(result.ExpiredExceptions) != 1 {
+		t.Fatalf("expected a single expired exception, got %v", result.ExpiredExceptions)
+	}
+	expired := result.ExpiredExceptions[0]
+	wantExpired := fmt.Sprintf(`exception for rules ["privileged"] owned by team-c expired on %s`, date(-1))
+	if expired.Message != wantExpired {
+		t.Errorf("unexpected expired exception. got %q, want %q", expired.Message, wantExpired)
+	}
+	if expired.Metadata["ticket"] != "OPS-1" || expired.Metadata["owner"] != "team-c" {
+		t.Errorf("expected the fields of the exception in the metadata, got %v", expired.Metadata)
+	}
+}
+
+func TestCheckTimeBoxedAndLegacyExceptions(t *testing.T) {
+	ctx := context.Background()
+
+	// The owner of the expired exception is also the name of a rule, which
+	// the legacy exceptions of lists of rule names must not match.
+	expires := time.Now().AddDate(0, 0, -1).Format(time.DateOnly)
+	policy := fmt.Sprintf(`package main
+
+deny_run_as_root contains "root" if true
+
+deny_latest contains "latest" if true
+
+deny_privileged contains "privileged" if true
+
+exception contains {"rules": ["privileged"], "owner": "run_as_root", "expires": %q} if true
+
+exception contains rules if {
+	rules := ["latest"]
+}
+`, expires)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "policy.rego"), []byte(policy), 0600); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+
+	opts := CompilerOptions{
+		Capabilities: ast.CapabilitiesForThisVersion(),
+		RegoVersion:  "v1",
+	}
+	engine, err := Load([]string{filepath.Join(dir, "policy.rego")}, opts)
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	results, err := engine.Check(ctx, map[string]any{"config.yaml": map[string]any{}}, "main")
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	messages := func(results []output.Result) []string {
+		var messages []string
+		for _, result := range results {
+			messages = append(messages, result.Message)
+		}
+		sort.Strings(messages)
+		return messages
+	}
+
+	result := results[0]
+	if got, want := messages(result.Failures), []string{"privileged", "root"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected failures. got %v, want %v", got, want)
+	}
+	if got, want := messages(result.Exceptions), []string{`data.main.exception[_][_] == "latest"`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected exceptions. got %v, want %v", got, want)
+	}
+	if len(result.ExpiredExceptions) != 1 {
+		t.Errorf("expected a single expired exception, got %v", result.ExpiredExceptions)
+	}
+}
+
+func TestCheckDataExceptions(t *testing.T) {
+	ctx := context.Background()
+
+	// The namespace has no exception rules, only an exception document.
+	dir := t.TempDir()
+	policy := `package main
+
+deny_latest contains "latest" if true
+
+deny_privileged contains "privileged" if true
+`
+	if err := os.WriteFile(filepath.Join(dir, "policy.rego"), []byte(policy), 0600); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	dataFile := filepath.Join(dir, "data.json")
+	if err := os.WriteFile(dataFile, []byte(`{"main": {"exception": [["latest"]]}}`), 0600); err != nil {
+		t.Fatalf("write data: %v", err)
+	}
+
+	opts := CompilerOptions{
+		Capabilities: ast.CapabilitiesForThisVersion(),
+		RegoVersion:  "v1",
+	}
+	engine, err := LoadWithData([]string{filepath.Join(dir, "policy.rego")}, []string{dataFile}, opts)
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	results, err := engine.Check(ctx, map[string]any{"config.yaml": map[string]any{}}, "main")
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	result := results[0]
+	if len(result.Failures) != 1 || result.Failures[0].Message != "privileged" {
+		t.Errorf("unexpected failures. got %v, want [privileged]", result.Failures)
+	}
+	if len(result.Exceptions) != 1 || result.Exceptions[0].Message != `data.main.exception[_][_] == "latest"` {
+		t.Errorf("unexpected exceptions. got %v, want the exception of latest", result.Exceptions)
+	}
+}
+
+func TestCheckSchemas(t *testing.T) {
+	ctx := context.Background()
+
+	modules := map[string]string{
+		"policy.rego": `package main
+
+deny contains "denied" if input.kind == "Service"
+`,
+	}
+
+	compiler, err := ast.CompileModules(modules)
+	if err != nil {
+		t.Fatalf("compile modules: %v", err)
+	}
+
+	engine := Engine{
+		modules:  compiler.Modules,
+		compiler: compiler,
+	}
+
+	deployment := &kubernetes.Object{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "web", Name: "app"}
+	service := &kubernetes.Object{APIVersion: "v1", Kind: "Service", Name: "app"}
+	configMap := &kubernetes.Object{APIVersion: "v1", Kind: "ConfigMap", Name: "app"}
+
+	configs := map[string]any{
+		"manifests.yaml": []any{
+			map[string]any{"kind": "Deployment"},
+			map[string]any{"kind": "Service"},
+			map[string]any{"kind": "ConfigMap"},
+		},
+		"other.yaml": map[string]any{},
+	}
+	engine.SetObjects(map[string][]*kubernetes.Object{
+		"manifests.yaml": {deployment, service, configMap},
+	})
+	engine.SetValidations(map[string][]*kubernetes.Validation{
+		"manifests.yaml": {
+			{
+				Schema: "deployment-apps-v1.json",
+				Errors: []kubernetes.SchemaError{{Path: []string{"spec", "replicas"}, Message: "got string, want integer"}},
+			},
+			{Schema: "service-v1.json"},
+			{},
+		},
+	})
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	for _, result := range results {
+		if result.FileName != "manifests.yaml" {
+			continue
+		}
+		if len(result.Failures) != 1 {
+			t.Fatalf("expected 1 failure, got %v", result.Failures)
+		}
+		want := &output.Object{APIVersion: "v1", Kind: "Service", Name: "app"}
+		if !reflect.DeepEqual(result.Failures[0].Object, want) {
+			t.Errorf("unexpected object of the failure. got %v, want %v", result.Failures[0].Object, want)
+		}
+	}
+
+	schemaResults := engine.CheckSchemas(configs)
+	if len(schemaResults) != 1 {
+		t.Fatalf("expected only the validated file to be reported, got %v", schemaResults)
+	}
+
+	result := schemaResults[0]
+	if result.Namespace != "kubernetes" || result.Successes != 1 {
+		t.Errorf("unexpected namespace %q or successes %d", result.Namespace, result.Successes)
+	}
+	if len(result.Failures) != 1 {
+		t.Fatalf("expected 1 failure, got %v", result.Failures)
+	}
+	if want := "apps/v1 Deployment does not match its schema at /spec/replicas: got string, want integer"; result.Failures[0].Message != want {
+		t.Errorf("unexpected failure. got %q, want %q", result.Failures[0].Message, want)
+	}
+	if result.Failures[0].Object == nil || result.Failures[0].Object.Name != "app" {
+		t.Errorf("expected the failure to identify the deployment, got %v", result.Failures[0].Object)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Message != "no schema found for v1 ConfigMap" {
+		t.Errorf("unexpected warnings %v", result.Warnings)
+	}
+}
+
+func TestCheckStrict(t *testing.T) {
+	var engine Engine
+
+	configs := map[string]any{
+		"config.yaml": []any{map[string]any{}, map[string]any{}},
+		"other.yaml":  map[string]any{},
+		"plain.yaml":  map[string]any{},
+	}
+	engine.SetFindings(map[string][][]strict.Finding{
+		"config.yaml": {
+			{
+				{
+					Kind:     strict.Boolean,
+					Path:     []string{"country"},
+					Position: location.Position{Line: 2, Column: 10},
+					Message:  "NO is read as the boolean false, quote it to keep it a string",
+				},
+			},
+			{},
+		},
+		"other.yaml": {{}},
+		"plain.yaml": nil,
+	})
+
+	results := engine.CheckStrict(configs)
+	if len(results) != 2 {
+		t.Fatalf("expected only the strictly parsed files to be reported, got %v", results)
+	}
+
+	result := results[0]
+	if result.FileName != "config.yaml" || result.Namespace != "strict" || result.Successes != 1 {
+		t.Errorf("unexpected file %q, namespace %q or successes %d", result.FileName, result.Namespace, result.Successes)
+	}
+	if len(result.Failures) != 1 {
+		t.Fatalf("expected 1 failure, got %v", result.Failures)
+	}
+
+	failure := result.Failures[0]
+	if failure.Message != "NO is read as the boolean false, quote it to keep it a string" {
+		t.Errorf("unexpected failure %q", failure.Message)
+	}
+	if want := (&output.Location{File: "config.yaml", Line: "2", Column: "10"}); !reflect.DeepEqual(failure.Location, want) {
+		t.Errorf("unexpected location. got %v, want %v", failure.Location, want)
+	}
+	if failure.Metadata["kind"] != "yaml11-boolean" {
+		t.Errorf("unexpected kind %v", failure.Metadata["kind"])
+	}
+
+	if results[1].FileName != "other.yaml" || results[1].Successes != 1 || len(results[1].Failures) != 0 {
+		t.Errorf("unexpected result %v", results[1])
+	}
+}
+
+func BenchmarkCheck(b *testing.B) {
+	ctx := context.Background()
+
+	policies := []string{"../examples/kubernetes/policy"}
+	opts := CompilerOptions{
+		Capabilities: ast.CapabilitiesForThisVersion(),
+		RegoVersion:  "v0",
+	}
+	engine, err := Load(policies, opts)
+	if err != nil {
+		b.Fatalf("loading policies: %v", err)
+	}
+
+	configFiles := []string{"../examples/kubernetes/deployment.yaml"}
+	parsed, err := parser.ParseConfigurations(configFiles)
+	if err != nil {
+		b.Fatalf("loading configs: %v", err)
+	}
+
+	// Every file is checked on its own. Without prepared queries, the prepared
+	// sets are dropped before every file, so that its queries are prepared
+	// again, as when every evaluation compiled its query.
+	for _, prepared := range []bool{false, true} {
+		for _, files := range []int{1, 100, 1000} {
+			b.Run(fmt.Sprintf("prepared=%t/files=%d", prepared, files), func(b *testing.B) {
+				for b.Loop() {
+					for i := range files {
+						if !prepared {
+							engine.preparedQueries = nil
+						}
+
+						configs := map[string]any{fmt.Sprintf("deployment-%d.yaml", i): parsed[configFiles[0]]}
+						if _, err := engine.Check(ctx, configs, "main"); err != nil {
+							b.Fatalf("check: %v", err)
+						}
+					}
+				}
+			})
+		}
+	}
+}
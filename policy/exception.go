@@ -0,0 +1,186 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/open-policy-agent/conftest/output"
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/storage"
+)
+
+// expiryWarningPeriod is how long before an exception expires a warning is
+// reported for it, so that it can be renewed or the failures fixed in time.
+const expiryWarningPeriod = 7 * 24 * time.Hour
+
+// timeBoxedException is an exception declared as an object rather than as a
+// list of rule names, e.g.
+//
+//	exception contains {"rules": ["run_as_root"], "owner": "team-a", "expires": "2025-12-31"} if { ... }
+//
+// The exception is honored until the end of the day it expires on.
+type timeBoxedException struct {
+	rules   []string
+	owner   string
+	expires time.Time
+
+	// metadata holds all of the fields of the exception object, including
+	// any that conftest does not know about.
+	metadata map[string]any
+}
+
+// newTimeBoxedException parses an exception object.
+func newTimeBoxedException(object map[string]any) (timeBoxedException, error) {
+	exception := timeBoxedException{
+		metadata: object,
+	}
+
+	rules, ok := object["rules"].([]any)
+	if !ok {
+		return timeBoxedException{}, fmt.Errorf("exception must declare the rules it applies to as a list")
+	}
+	for _, rule := range rules {
+		name, ok := rule.(string)
+		if !ok {
+			return timeBoxedException{}, fmt.Errorf("exception rules must be strings, got %v", rule)
+		}
+		exception.rules = append(exception.rules, name)
+	}
+
+	if owner, ok := object["owner"]; ok {
+		exception.owner = fmt.Sprint(owner)
+	}
+
+	if expires, ok := object["expires"]; ok {
+		date, err := time.Parse(time.DateOnly, fmt.Sprint(expires))
+		if err != nil {
+			return timeBoxedException{}, fmt.Errorf("exception expires must be a date formatted as YYYY-MM-DD: %v", expires)
+		}
+		exception.expires = date
+	}
+
+	return exception, nil
+}
+
+// declaresExceptions returns true if the given namespace declares exceptions,
+// either with exception rules or as an exception document of the data. It is
+// only determined once for every namespace, as neither changes after the
+// engine has been loaded.
+func (e *Engine) declaresExceptions(ctx context.Context, namespace string) bool {
+	if declared, ok := e.exceptionNamespaces.Load(namespace); ok {
+		return declared.(bool)
+	}
+
+	declared := false
+	for _, module := range e.Modules() {
+		if strings.Replace(module.Package.Path.String(), "data.", "", 1) != namespace {
+			continue
+		}
+		for _, rule := range module.Rules {
+			if rule.Head.Name.String() == "exception" {
+				declared = true
+			}
+		}
+	}
+
+	if !declared && e.store != nil {
+		path := append(storage.Path(strings.Split(namespace, ".")), "exception")
+		if _, err := storage.ReadOne(ctx, e.store, path); err == nil {
+			declared = true
+		}
+	}
+
+	e.exceptionNamespaces.Store(namespace, declared)
+	return declared
+}
+
+// exceptions returns the exceptions in the given namespace that are declared
+// as objects, and the number of times every rule is named by the exceptions
+// that are declared as lists of rule names.
+func (e *Engine) exceptions(ctx context.Context, input ast.Value, namespace string) ([]timeBoxedException, map[string]int, output.QueryResult, error) {
+	query := fmt.Sprintf("data.%s.exception", namespace)
+	evaluated, err := e.eval(ctx, input, query)
+	if err != nil {
+		return nil, nil, output.QueryResult{}, err
+	}
+
+	var timeBoxed []timeBoxedException
+	legacy := make(map[string]int)
+	for _, result := range evaluated.resultSet {
+		for _, expression := range result.Expressions {
+			values, _ := expression.Value.([]any)
+			for _, value := range values {
+				switch value := value.(type) {
+				case map[string]any:
+					exception, err := newTimeBoxedException(value)
+					if err != nil {
+						return nil, nil, output.QueryResult{}, fmt.Errorf("%s: %w", query, err)
+					}
+
+					timeBoxed = append(timeBoxed, exception)
+				case []any:
+					for _, rule := range value {
+						if name, ok := rule.(string); ok {
+							legacy[name]++
+						}
+					}
+				}
+			}
+		}
+	}
+
+	queryResult := output.QueryResult{
+		Query:   query,
+		Traces:  evaluated.traces,
+		Outputs: evaluated.outputs,
+	}
+
+	return timeBoxed, legacy, queryResult, nil
+}
+
+// appliesTo returns true when the exception applies to the rule with the
+// given name, without its deny, warn or violation prefix.
+func (x timeBoxedException) appliesTo(name string) bool {
+	return slices.Contains(x.rules, name)
+}
+
+// expired returns true when the exception is no longer honored at the given time.
+func (x timeBoxedException) expired(now time.Time) bool {
+	return !x.expires.IsZero() && !now.Before(x.end())
+}
+
+// expiring returns true when the exception is still honored at the given
+// time, but expires within the warning period.
+func (x timeBoxedException) expiring(now time.Time) bool {
+	return !x.expires.IsZero() && !x.expired(now) && !now.Add(expiryWarningPeriod).Before(x.end())
+}
+
+// end returns the time from which the exception is no longer honored.
+func (x timeBoxedException) end() time.Time {
+	return x.expires.AddDate(0, 0, 1)
+}
+
+// result reports the exception, describing its rules and owner followed by the
+// given status. The fields of the exception are kept in the metadata of the
+// result.
+func (x timeBoxedException) result(query string, status string) output.Result {
+	message := fmt.Sprintf("exception for rules %q", x.rules)
+	if x.owner != "" {
+		message += fmt.Sprintf(" owned by %s", x.owner)
+	}
+	if status != "" {
+		message += " " + status
+	}
+
+	metadata := maps.Clone(x.metadata)
+	metadata["query"] = query
+
+	return output.Result{
+		Message:  message,
+		Metadata: metadata,
+	}
+}
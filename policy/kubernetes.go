@@ -0,0 +1,151 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/open-policy-agent/conftest/output"
+	"github.com/open-policy-agent/conftest/parser/kubernetes"
+	"github.com/open-policy-agent/conftest/parser/location"
+)
+
+// schemaNamespace is the namespace the results of validating documents against
+// the schemas of their kinds are reported under.
+const schemaNamespace = "kubernetes"
+
+// SetObjects sets the Kubernetes objects described by the documents of the
+// configurations, as returned by parser.ParseConfigurationsWithDetails. When
+// set, results report the object of the document they were produced for, and
+// combined configurations include the object of every document.
+func (e *Engine) SetObjects(objects map[string][]*kubernetes.Object) {
+	e.objects = objects
+}
+
+// SetValidations sets the results of validating the documents of the
+// configurations against the schemas of their kinds, as returned by
+// parser.ParseConfigurationsWithDetails, which are reported by CheckSchemas.
+func (e *Engine) SetValidations(validations map[string][]*kubernetes.Validation) {
+	e.validations = validations
+}
+
+// CheckSchemas reports the validations of the documents of the given
+// configurations, under the kubernetes namespace. Every document that matches
+// its schema is a success, every value that does not match it is a failure,
+// and a document whose kind has no schema is a warning. Files without any
+// validated documents are not reported.
+func (e *Engine) CheckSchemas(configs map[string]any) output.CheckResults {
+	paths := make([]string, 0, len(e.validations))
+	for path := range e.validations {
+		if _, ok := configs[path]; ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var checkResults output.CheckResults
+	for _, path := range paths {
+		_, multiple := configs[path].([]any)
+
+		checkResult := output.CheckResult{
+			FileName:  path,
+			Namespace: schemaNamespace,
+		}
+		validated := false
+		for i, validation := range e.validations[path] {
+			if validation == nil {
+				continue
+			}
+			validated = true
+
+			locate := func() (location.Map, []string) {
+				if multiple {
+					return e.documentLocations(path, i)
+				}
+				return document(e.locations[path].Documents(), 0), nil
+			}
+			aggregate(&checkResult, validationResult(path, validation, document(e.objects[path], i), locate))
+		}
+
+		if validated {
+			checkResults = append(checkResults, checkResult)
+		}
+	}
+
+	return checkResults
+}
+
+// validationResult returns the result of validating a single document of the
+// file at the given path against the schema of its kind, located within the
+// document and attributed to its object.
+func validationResult(path string, validation *kubernetes.Validation, object *kubernetes.Object, locate locator) output.CheckResult {
+	var result output.CheckResult
+	switch {
+	case validation.Schema == "":
+		result.Warnings = append(result.Warnings, output.Result{
+			Message: fmt.Sprintf("no schema found for %s", describe(object)),
+		})
+	case len(validation.Errors) == 0:
+		result.Successes++
+	default:
+		for _, schemaErr := range validation.Errors {
+			result.Failures = append(result.Failures, schemaFailure(object, validation.Schema, schemaErr))
+		}
+	}
+
+	resolveLocations(result, path, locate)
+	identify(result, object)
+
+	return result
+}
+
+// schemaFailure returns the failure reported for a value of a document that
+// does not match its schema. The path of the value is kept in the metadata,
+// so that the failure is attributed to the line the value is declared at.
+func schemaFailure(object *kubernetes.Object, schema string, schemaErr kubernetes.SchemaError) output.Result {
+	path := make([]any, 0, len(schemaErr.Path))
+	for _, segment := range schemaErr.Path {
+		path = append(path, segment)
+	}
+
+	pointer := location.Pointer(schemaErr.Path)
+	if pointer == "" {
+		pointer = "/"
+	}
+
+	return output.Result{
+		Message: fmt.Sprintf("%s does not match its schema at %s: %s", describe(object), pointer, schemaErr.Message),
+		Metadata: map[string]any{
+			pathField: path,
+			"schema":  schema,
+		},
+	}
+}
+
+// describe returns the API version and kind of the object.
+func describe(object *kubernetes.Object) string {
+	if object == nil {
+		return "document"
+	}
+
+	return fmt.Sprintf("%s %s", object.APIVersion, object.Kind)
+}
+
+// identify sets the object of the failures, warnings and exceptions of the
+// result to the object of the document that produced them.
+func identify(checkResult output.CheckResult, object *kubernetes.Object) {
+	if object == nil {
+		return
+	}
+
+	identity := &output.Object{
+		APIVersion: object.APIVersion,
+		Kind:       object.Kind,
+		Namespace:  object.Namespace,
+		Name:       object.Name,
+	}
+	for _, results := range [][]output.Result{checkResult.Failures, checkResult.Warnings, checkResult.Exceptions} {
+		for i := range results {
+			results[i].Object = identity
+		}
+	}
+}
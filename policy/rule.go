@@ -0,0 +1,145 @@
+package policy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/output"
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/topdown"
+)
+
+// ruleTracer records which body of a rule produced each of the values of the
+// rule while it is evaluated. The values of partial rules such as deny are the
+// union of the values produced by all bodies, so the query result alone does
+// not tell them apart.
+type ruleTracer struct {
+	rules  []*ast.Rule
+	values []ruleValue
+}
+
+type ruleValue struct {
+	value ast.Value
+	rule  *ast.Rule
+}
+
+// newRuleTracer returns a tracer for the rule with the given bodies.
+func newRuleTracer(rules []*ast.Rule) *ruleTracer {
+	return &ruleTracer{rules: rules}
+}
+
+// Enabled returns true when the rule has to be traced. Tracing slows down the
+// evaluation considerably, and every value of a rule with a single body is
+// produced by that body.
+func (t *ruleTracer) Enabled() bool {
+	return len(t.rules) > 1
+}
+
+func (t *ruleTracer) Config() topdown.TraceConfig {
+	return topdown.TraceConfig{}
+}
+
+// TraceEvent records the value of a body of the traced rule when the body has
+// been evaluated successfully, at which point its variables are bound.
+func (t *ruleTracer) TraceEvent(event topdown.Event) {
+	if event.Op != topdown.ExitOp {
+		return
+	}
+
+	rule, ok := event.Node.(*ast.Rule)
+	if !ok || rule.Head.Key == nil || !slices.Contains(t.rules, rule) {
+		return
+	}
+
+	t.values = append(t.values, ruleValue{
+		value: event.Plug(rule.Head.Key).Value,
+		rule:  rule,
+	})
+}
+
+// rule returns the first body of the rule that produced the given value.
+func (t *ruleTracer) rule(value any) *ast.Rule {
+	switch len(t.rules) {
+	case 0:
+		return nil
+	case 1:
+		return t.rules[0]
+	}
+
+	v, err := ast.InterfaceToValue(value)
+	if err != nil {
+		return nil
+	}
+
+	for _, traced := range t.values {
+		if traced.value.Compare(v) == 0 {
+			return traced.rule
+		}
+	}
+
+	return nil
+}
+
+// resultRule describes the rule body that produced the given value of a
+// query, or returns nil when it is unknown.
+func (e *Engine) resultRule(tracer *ruleTracer, value any) *output.Rule {
+	rule := tracer.rule(value)
+	if rule == nil {
+		return nil
+	}
+
+	return e.ruleInfo(rule)
+}
+
+// ruleInfo describes the given rule, including its METADATA annotations. Both
+// annotations scoped to the rule body and to the document are considered, with
+// the former taking precedence.
+func (e *Engine) ruleInfo(rule *ast.Rule) *output.Rule {
+	info := output.Rule{
+		Package: strings.TrimPrefix(rule.Module.Package.Path.String(), "data."),
+		Name:    rule.Head.Ref()[0].String(),
+	}
+	if rule.Location != nil {
+		info.File = rule.Location.File
+		info.Row = rule.Location.Row
+	}
+
+	annotationSet := e.compiler.GetAnnotationSet()
+	annotations := slices.Clone(annotationSet.GetRuleScope(rule))
+	if document := annotationSet.GetDocumentScope(rule.Path()); document != nil {
+		annotations = append(annotations, document)
+	}
+
+	for _, annotation := range annotations {
+		if info.Title == "" {
+			info.Title = annotation.Title
+		}
+		if info.Description == "" {
+			info.Description = annotation.Description
+		}
+		if id, ok := annotation.Custom["id"]; ok && info.ID == "" {
+			info.ID = fmt.Sprint(id)
+		}
+		if severity, ok := annotation.Custom["severity"]; ok && info.Severity == "" {
+			info.Severity = fmt.Sprint(severity)
+		}
+	}
+
+	return &info
+}
+
+// ruleSeverity returns the severity of the result. A severity declared by the
+// result itself takes precedence over the severity annotation of its rule.
+func ruleSeverity(result output.Result) string {
+	if result.Severity != "" || result.Rule == nil {
+		return result.Severity
+	}
+
+	severity, err := output.ParseSeverity(result.Rule.Severity)
+	if err != nil {
+		return ""
+	}
+
+	return severity
+}
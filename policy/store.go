@@ -0,0 +1,126 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+
+	"github.com/open-policy-agent/opa/v1/storage"
+)
+
+type fileInfoKey struct{}
+
+// withFileInfo returns a context that carries the name and directory of the
+// file at the given path, to be exposed to the policies by a fileInfoStore.
+func withFileInfo(ctx context.Context, path string) (context.Context, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("get absolute path: %w", err)
+	}
+
+	fileInfo := map[string]any{
+		"name": filepath.Base(abs),
+		"dir":  filepath.Dir(abs),
+	}
+
+	return context.WithValue(ctx, fileInfoKey{}, fileInfo), nil
+}
+
+// fileInfoStore is a read-only overlay of a store that adds the name and
+// directory of the file being evaluated under data.conftest.file.
+//
+// The file information is taken from the context of the evaluation, rather
+// than written to the store of the engine, which allows the same prepared
+// queries to evaluate multiple files at the same time.
+type fileInfoStore struct {
+	storage.Store
+}
+
+// Read returns the document at the given path, merging the file information
+// into any document that contains data.conftest.file.
+func (s *fileInfoStore) Read(ctx context.Context, txn storage.Transaction, path storage.Path) (any, error) {
+	fileInfo, ok := ctx.Value(fileInfoKey{}).(map[string]any)
+	if !ok {
+		return s.Store.Read(ctx, txn, path)
+	}
+
+	switch {
+	case len(path) == 0:
+		root, err := s.Store.Read(ctx, txn, path)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.withConftest(ctx, txn, root, fileInfo)
+	case path[0] != "conftest":
+		return s.Store.Read(ctx, txn, path)
+	case len(path) == 1:
+		return s.readConftest(ctx, txn, fileInfo)
+	case path[1] != "file":
+		return s.Store.Read(ctx, txn, path)
+	}
+
+	var document any = fileInfo
+	for _, key := range path[2:] {
+		object, ok := document.(map[string]any)
+		if !ok {
+			return nil, notFoundError(path)
+		}
+
+		document, ok = object[key]
+		if !ok {
+			return nil, notFoundError(path)
+		}
+	}
+
+	return document, nil
+}
+
+// readConftest returns data.conftest from the base store with the file
+// information added to it.
+func (s *fileInfoStore) readConftest(ctx context.Context, txn storage.Transaction, fileInfo map[string]any) (any, error) {
+	conftest, err := s.Store.Read(ctx, txn, storage.Path{"conftest"})
+	if storage.IsNotFound(err) {
+		return map[string]any{"file": fileInfo}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	object, ok := conftest.(map[string]any)
+	if !ok {
+		return conftest, nil
+	}
+
+	merged := maps.Clone(object)
+	merged["file"] = fileInfo
+
+	return merged, nil
+}
+
+// withConftest returns a copy of the root document of the base store with
+// data.conftest replaced by the merged document.
+func (s *fileInfoStore) withConftest(ctx context.Context, txn storage.Transaction, root any, fileInfo map[string]any) (any, error) {
+	object, ok := root.(map[string]any)
+	if !ok {
+		return root, nil
+	}
+
+	conftest, err := s.readConftest(ctx, txn, fileInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := maps.Clone(object)
+	merged["conftest"] = conftest
+
+	return merged, nil
+}
+
+func notFoundError(path storage.Path) error {
+	return &storage.Error{
+		Code:    storage.NotFoundErr,
+		Message: fmt.Sprintf("%v: document does not exist", path),
+	}
+}
@@ -0,0 +1,96 @@
+package policy
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/open-policy-agent/opa/v1/storage"
+	"github.com/open-policy-agent/opa/v1/storage/inmem"
+)
+
+func TestFileInfoStoreRead(t *testing.T) {
+	ctx := context.Background()
+
+	base := inmem.NewFromObject(map[string]any{
+		"conftest": map[string]any{"owner": "platform"},
+		"services": map[string]any{"ports": []any{"ssh"}},
+	})
+
+	store := &fileInfoStore{Store: base}
+	fileCtx, err := withFileInfo(ctx, "/configs/deployment.yaml")
+	if err != nil {
+		t.Fatalf("with file info: %v", err)
+	}
+
+	fileInfo := map[string]any{
+		"name": "deployment.yaml",
+		"dir":  "/configs",
+	}
+
+	tests := []struct {
+		desc string
+		path storage.Path
+		want any
+	}{
+		{
+			desc: "file info",
+			path: storage.Path{"conftest", "file"},
+			want: fileInfo,
+		},
+		{
+			desc: "file name",
+			path: storage.Path{"conftest", "file", "name"},
+			want: "deployment.yaml",
+		},
+		{
+			desc: "merged with existing data",
+			path: storage.Path{"conftest"},
+			want: map[string]any{"owner": "platform", "file": fileInfo},
+		},
+		{
+			desc: "unrelated data",
+			path: storage.Path{"services"},
+			want: map[string]any{"ports": []any{"ssh"}},
+		},
+		{
+			desc: "root document",
+			path: storage.Path{},
+			want: map[string]any{
+				"conftest": map[string]any{"owner": "platform", "file": fileInfo},
+				"services": map[string]any{"ports": []any{"ssh"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := storage.ReadOne(fileCtx, store, tt.path)
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unexpected document. got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := storage.ReadOne(fileCtx, store, storage.Path{"conftest", "file", "missing"}); !storage.IsNotFound(err) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+
+	// Without file information in the context, the base store is read as is.
+	if _, err := storage.ReadOne(ctx, store, storage.Path{"conftest", "file"}); !storage.IsNotFound(err) {
+		t.Errorf("expected not found error without file info, got %v", err)
+	}
+
+	// The base store must not be modified by the overlay.
+	conftest, err := storage.ReadOne(ctx, base, storage.Path{"conftest"})
+	if err != nil {
+		t.Fatalf("read base: %v", err)
+	}
+	if !reflect.DeepEqual(conftest, map[string]any{"owner": "platform"}) {
+		t.Errorf("base store was modified: %v", conftest)
+	}
+}
@@ -0,0 +1,87 @@
+package policy
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/open-policy-agent/conftest/output"
+	"github.com/open-policy-agent/conftest/parser/strict"
+)
+
+// strictNamespace is the namespace the findings of parsing configurations
+// strictly are reported under.
+const strictNamespace = "strict"
+
+// SetFindings sets the findings of parsing the documents of the configurations
+// strictly, as returned by parser.ParseConfigurationsWithDetails, which are
+// reported by CheckStrict.
+func (e *Engine) SetFindings(findings map[string][][]strict.Finding) {
+	e.findings = findings
+}
+
+// CheckStrict reports the findings of parsing the documents of the given
+// configurations strictly, under the strict namespace. Every document without
+// findings is a success, and every finding is a failure. Files that were not
+// parsed strictly are not reported.
+func (e *Engine) CheckStrict(configs map[string]any) output.CheckResults {
+	paths := make([]string, 0, len(e.findings))
+	for path, findings := range e.findings {
+		if _, ok := configs[path]; ok && findings != nil {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var checkResults output.CheckResults
+	for _, path := range paths {
+		checkResult := output.CheckResult{
+			FileName:  path,
+			Namespace: strictNamespace,
+		}
+		for _, findings := range e.findings[path] {
+			aggregate(&checkResult, findingsResult(path, findings))
+		}
+
+		checkResults = append(checkResults, checkResult)
+	}
+
+	return checkResults
+}
+
+// findingsResult returns the result of parsing a single document of the file at
+// the given path strictly, which is a success when there are no findings.
+func findingsResult(path string, findings []strict.Finding) output.CheckResult {
+	var result output.CheckResult
+	if len(findings) == 0 {
+		result.Successes++
+	}
+	for _, finding := range findings {
+		result.Failures = append(result.Failures, strictFailure(path, finding))
+	}
+
+	return result
+}
+
+// strictFailure returns the failure reported for a finding of the file at the
+// given path, at the position of the value. The path of the value and the kind
+// of the finding are kept in the metadata.
+func strictFailure(file string, finding strict.Finding) output.Result {
+	path := make([]any, 0, len(finding.Path))
+	for _, segment := range finding.Path {
+		path = append(path, segment)
+	}
+
+	return output.Result{
+		Message: finding.Message,
+		Location: &output.Location{
+			File:   file,
+			Line:   json.Number(strconv.Itoa(finding.Position.Line)),
+			Column: json.Number(strconv.Itoa(finding.Position.Column)),
+		},
+		Metadata: map[string]any{
+			pathField: path,
+			"kind":    string(finding.Kind),
+		},
+	}
+}
@@ -0,0 +1,130 @@
+package policy
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/open-policy-agent/conftest/output"
+	"github.com/open-policy-agent/conftest/parser/suppression"
+)
+
+// The metadata fields that describe how an inline suppression directive was
+// applied to a result.
+const (
+	suppressionField      = "suppression"
+	suppressionErrorField = "suppression_error"
+)
+
+// suppress reports the failures and warnings of the rules named by the given
+// inline suppression directives as exceptions. A directive that does not meet
+// the requirements of the engine, or has expired, is not applied, and the
+// reason is recorded in the metadata of the results it would have suppressed.
+func (e *Engine) suppress(checkResult output.CheckResult, directives []suppression.Directive) output.CheckResult {
+	if len(directives) == 0 {
+		return checkResult
+	}
+
+	now := time.Now()
+	apply := func(results []output.Result) []output.Result {
+		var remaining []output.Result
+		for _, result := range results {
+			directive, err := e.matchDirective(directives, ruleNames(checkResult.Namespace, result), now)
+			if directive == nil {
+				if err != nil {
+					result.Metadata = withMetadata(result.Metadata, suppressionErrorField, err.Error())
+				}
+
+				remaining = append(remaining, result)
+				continue
+			}
+
+			checkResult.Exceptions = append(checkResult.Exceptions, suppressed(result, *directive))
+		}
+
+		return remaining
+	}
+
+	checkResult.Failures = apply(checkResult.Failures)
+	checkResult.Warnings = apply(checkResult.Warnings)
+
+	return checkResult
+}
+
+// matchDirective returns the first directive that suppresses a rule with any
+// of the given names and can be applied. When only directives that can not be
+// applied match the rule, the reason the first of them can not be applied is
+// returned instead.
+func (e *Engine) matchDirective(directives []suppression.Directive, names []string, now time.Time) (*suppression.Directive, error) {
+	var invalid error
+	for i, directive := range directives {
+		if !directive.Matches(names...) {
+			continue
+		}
+
+		if err := directive.Validate(e.requirements, now); err != nil {
+			if invalid == nil {
+				invalid = err
+			}
+			continue
+		}
+
+		return &directives[i], nil
+	}
+
+	return nil, invalid
+}
+
+// ruleNames returns the names a directive can use to refer to the rule that
+// produced the result: the name of the rule, the name without its deny, warn
+// or violation prefix as used by exceptions, and the ID of the rule.
+func ruleNames(namespace string, result output.Result) []string {
+	var names []string
+	if query, ok := result.Metadata["query"].(string); ok {
+		rule := strings.TrimPrefix(query, fmt.Sprintf("data.%s.", namespace))
+		names = append(names, rule, removeRulePrefix(rule))
+	}
+
+	if result.Rule != nil {
+		names = append(names, result.Rule.ID)
+	}
+
+	return names
+}
+
+// suppressed returns the exception reported for a result that was suppressed
+// by the given directive. The justification of the directive is appended to
+// the message, and the directive itself is recorded in the metadata.
+func suppressed(result output.Result, directive suppression.Directive) output.Result {
+	details := map[string]any{
+		"line": directive.Line,
+	}
+
+	justification := fmt.Sprintf("ignored at line %d", directive.Line)
+	if directive.Reason != "" {
+		justification += ": " + directive.Reason
+		details["reason"] = directive.Reason
+	}
+
+	if !directive.Expires.IsZero() {
+		details["expires"] = directive.Expires.Format(time.DateOnly)
+	}
+
+	result.Message = fmt.Sprintf("%s (%s)", result.Message, justification)
+	result.Metadata = withMetadata(result.Metadata, suppressionField, details)
+
+	return result
+}
+
+// withMetadata returns a copy of the metadata with the given field set, so
+// that the metadata of other results sharing the same map is not modified.
+func withMetadata(metadata map[string]any, field string, value any) map[string]any {
+	metadata = maps.Clone(metadata)
+	if metadata == nil {
+		metadata = make(map[string]any)
+	}
+	metadata[field] = value
+
+	return metadata
+}
@@ -0,0 +1,101 @@
+// Package render renders the configurations that are generated from a source
+// directory, such as a Helm chart, rather than being written as they are.
+package render
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+)
+
+// releaseName is the name of the release the charts are rendered for, which is
+// the name used by helm template when none is given.
+const releaseName = "release-name"
+
+// IsChart returns true when the directory at the path is a Helm chart.
+func IsChart(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, chartutil.ChartfileName))
+	return err == nil && !info.IsDir()
+}
+
+// Helm renders the templates of the chart in the given directory, in the same
+// way as helm template, with the values of the chart overridden by the given
+// values files in order. The rendered manifests are returned by the path of
+// the template they were rendered from, e.g. chart/templates/service.yaml.
+// Templates that render to nothing, and the notes of the chart, are omitted.
+func Helm(dir string, valuesFiles []string) (map[string][]byte, error) {
+	chart, err := loader.Load(dir)
+	if err != nil {
+		return nil, fmt.Errorf("load chart: %w", err)
+	}
+
+	values := map[string]any{}
+	for _, valuesFile := range valuesFiles {
+		fileValues, err := chartutil.ReadValuesFile(valuesFile)
+		if err != nil {
+			return nil, fmt.Errorf("read values file %s: %w", valuesFile, err)
+		}
+		values = mergeValues(values, fileValues)
+	}
+
+	if err := chartutil.ProcessDependenciesWithMerge(chart, values); err != nil {
+		return nil, fmt.Errorf("process dependencies: %w", err)
+	}
+
+	options := chartutil.ReleaseOptions{
+		Name:      releaseName,
+		Namespace: "default",
+		Revision:  1,
+		IsInstall: true,
+	}
+	renderValues, err := chartutil.ToRenderValues(chart, values, options, chartutil.DefaultCapabilities)
+	if err != nil {
+		return nil, fmt.Errorf("values: %w", err)
+	}
+
+	rendered, err := engine.Render(chart, renderValues)
+	if err != nil {
+		return nil, fmt.Errorf("render: %w", err)
+	}
+
+	manifests := make(map[string][]byte, len(rendered))
+	for name, manifest := range rendered {
+		if path.Base(name) == "NOTES.txt" || strings.TrimSpace(manifest) == "" {
+			continue
+		}
+
+		// The templates are named after the chart rather than its directory,
+		// e.g. mychart/templates/service.yaml.
+		_, template, _ := strings.Cut(name, "/")
+		manifests[filepath.Join(dir, filepath.FromSlash(template))] = []byte(manifest)
+	}
+
+	return manifests, nil
+}
+
+// mergeValues merges the values of b into a, with the values of b taking
+// precedence, in the same way as values files are merged by helm.
+func mergeValues(a, b map[string]any) map[string]any {
+	merged := make(map[string]any, len(a))
+	for key, value := range a {
+		merged[key] = value
+	}
+
+	for key, value := range b {
+		if table, ok := value.(map[string]any); ok {
+			if existing, ok := merged[key].(map[string]any); ok {
+				merged[key] = mergeValues(existing, table)
+				continue
+			}
+		}
+		merged[key] = value
+	}
+
+	return merged
+}
@@ -0,0 +1,107 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHelm(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Chart.yaml": `apiVersion: v2
+name: app
+version: 0.1.0
+`,
+		"values.yaml": `service:
+  type: ClusterIP
+  port: 80
+`,
+		"templates/service.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Release.Name }}
+spec:
+  type: {{ .Values.service.type }}
+  port: {{ .Values.service.port }}
+`,
+		"templates/ingress.yaml": `{{- if .Values.ingress }}
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+{{- end }}
+`,
+		"templates/NOTES.txt": `Installed {{ .Release.Name }}`,
+		"prod.yaml": `service:
+  type: LoadBalancer
+`,
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	if !IsChart(dir) {
+		t.Errorf("expected %s to be a chart", dir)
+	}
+	if IsChart(filepath.Join(dir, "templates")) {
+		t.Errorf("expected the templates directory not to be a chart")
+	}
+
+	tests := []struct {
+		name        string
+		valuesFiles []string
+		want        string
+	}{
+		{
+			name: "default values",
+			want: `apiVersion: v1
+kind: Service
+metadata:
+  name: release-name
+spec:
+  type: ClusterIP
+  port: 80
+`,
+		},
+		{
+			name:        "values files",
+			valuesFiles: []string{filepath.Join(dir, "prod.yaml")},
+			want: `apiVersion: v1
+kind: Service
+metadata:
+  name: release-name
+spec:
+  type: LoadBalancer
+  port: 80
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifests, err := Helm(dir, tt.valuesFiles)
+			if err != nil {
+				t.Fatalf("Helm: unexpected error %v", err)
+			}
+
+			want := map[string][]byte{
+				filepath.Join(dir, "templates", "service.yaml"): []byte(tt.want),
+			}
+			if !reflect.DeepEqual(manifests, want) {
+				t.Errorf("unexpected manifests. got %q, want %q", manifests, want)
+			}
+		})
+	}
+
+	t.Run("missing values file", func(t *testing.T) {
+		if _, err := Helm(dir, []string{filepath.Join(dir, "missing.yaml")}); err == nil {
+			t.Error("expected an error for a missing values file")
+		}
+	})
+}
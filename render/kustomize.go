@@ -0,0 +1,217 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+// IsKustomization returns true when the directory at the path holds a
+// kustomization file.
+func IsKustomization(dir string) bool {
+	_, ok := kustomizationFile(dir)
+	return ok
+}
+
+// Kustomize builds the kustomization in the given directory, in the same way
+// as kustomize build. Only local resources can be built, so a kustomization
+// that refers to a remote base or file is an error rather than being fetched.
+// The built resources are returned by the path of the file they originate
+// from, e.g. base/deployment.yaml for an overlay of base, with the resources
+// generated by a kustomization returned by the path of its kustomization file.
+// Resources that originate from the same file are returned as the documents
+// of a single manifest, in the order they are built in.
+func Kustomize(dir string) (map[string][]byte, error) {
+	if err := checkLocal(dir, map[string]bool{}); err != nil {
+		return nil, err
+	}
+
+	kustomization, _ := kustomizationFile(dir)
+	abs, err := filepath.Abs(kustomization)
+	if err != nil {
+		return nil, fmt.Errorf("get abs: %w", err)
+	}
+
+	fs := &originFS{
+		FileSystem:    filesys.MakeFsOnDisk(),
+		kustomization: abs,
+	}
+	resources, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fs, dir)
+	if err != nil {
+		return nil, fmt.Errorf("build: %w", err)
+	}
+
+	origins := make([]string, resources.Size())
+	for i, resource := range resources.Resources() {
+		origin, err := resource.GetOrigin()
+		if err != nil {
+			return nil, fmt.Errorf("get origin of %s: %w", resource.CurId(), err)
+		}
+
+		origins[i] = kustomization
+		switch {
+		case origin == nil:
+		case origin.Path != "":
+			origins[i] = filepath.Join(dir, filepath.FromSlash(origin.Path))
+		case origin.ConfiguredIn != "":
+			origins[i] = filepath.Join(dir, filepath.FromSlash(origin.ConfiguredIn))
+		}
+	}
+
+	if !fs.requested {
+		if err := resources.RemoveOriginAnnotations(); err != nil {
+			return nil, fmt.Errorf("remove origin annotations: %w", err)
+		}
+	}
+
+	var files []string
+	documents := make(map[string][][]byte)
+	for i, resource := range resources.Resources() {
+		document, err := resource.AsYAML()
+		if err != nil {
+			return nil, fmt.Errorf("marshal %s: %w", resource.CurId(), err)
+		}
+
+		file := origins[i]
+		if _, ok := documents[file]; !ok {
+			files = append(files, file)
+		}
+		documents[file] = append(documents[file], document)
+	}
+
+	manifests := make(map[string][]byte, len(files))
+	for _, file := range files {
+		manifests[file] = bytes.Join(documents[file], []byte("---\n"))
+	}
+
+	return manifests, nil
+}
+
+// originFS reads the files of the kustomization from disk, with the origin
+// annotations build option set in the kustomization being built, so that the
+// resources can be attributed to the files they originate from.
+type originFS struct {
+	filesys.FileSystem
+
+	// kustomization is the absolute path of the kustomization being built.
+	kustomization string
+
+	// requested is true when the kustomization sets the origin annotations
+	// build option itself, in which case the annotations are kept.
+	requested bool
+}
+
+// ReadFile reads the file at the path, setting the origin annotations build
+// option when the file is the kustomization being built.
+func (fs *originFS) ReadFile(path string) ([]byte, error) {
+	contents, err := fs.FileSystem.ReadFile(path)
+	if err != nil || filepath.Clean(path) != fs.kustomization {
+		return contents, err
+	}
+
+	var kustomization map[string]any
+	if err := yaml.Unmarshal(contents, &kustomization); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", path, err)
+	}
+	if kustomization == nil {
+		kustomization = map[string]any{}
+	}
+
+	buildMetadata, _ := kustomization["buildMetadata"].([]any)
+	if slices.Contains(buildMetadata, any(types.OriginAnnotations)) {
+		fs.requested = true
+		return contents, nil
+	}
+	kustomization["buildMetadata"] = append(buildMetadata, types.OriginAnnotations)
+
+	return yaml.Marshal(kustomization)
+}
+
+// checkLocal returns an error when the kustomization in the given directory,
+// or any of the kustomizations it refers to, refers to a resource, base or
+// component that does not exist on the local filesystem, or to a file that is
+// fetched over the network.
+func checkLocal(dir string, visited map[string]bool) error {
+	path, ok := kustomizationFile(dir)
+	if !ok {
+		return nil
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("get abs: %w", err)
+	}
+	if visited[abs] {
+		return nil
+	}
+	visited[abs] = true
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read kustomization: %w", err)
+	}
+
+	var kustomization types.Kustomization
+	if err := yaml.Unmarshal(contents, &kustomization); err != nil {
+		return fmt.Errorf("unmarshal %s: %w", path, err)
+	}
+
+	references := slices.Concat(kustomization.Resources, kustomization.Bases, kustomization.Components, kustomization.Crds)
+	for _, reference := range references {
+		referencePath := filepath.Join(dir, reference)
+		info, err := os.Stat(referencePath)
+		if isRemote(reference) || err != nil {
+			return fmt.Errorf("%s refers to %s, which is not a local file or directory", path, reference)
+		}
+
+		if info.IsDir() {
+			if err := checkLocal(referencePath, visited); err != nil {
+				return err
+			}
+		}
+	}
+
+	var patches []string
+	for _, patch := range slices.Concat(kustomization.Patches, kustomization.PatchesJson6902) {
+		patches = append(patches, patch.Path)
+	}
+	for _, patch := range kustomization.PatchesStrategicMerge {
+		patches = append(patches, string(patch))
+	}
+	for _, patch := range patches {
+		if isRemote(patch) {
+			return fmt.Errorf("%s refers to %s, which is not a local file", path, patch)
+		}
+	}
+
+	return nil
+}
+
+// isRemote returns true when kustomize fetches the file at the path over
+// the network.
+func isRemote(path string) bool {
+	u, err := url.Parse(path)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+// kustomizationFile returns the path of the kustomization file in the
+// directory, when it has one.
+func kustomizationFile(dir string) (string, bool) {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,101 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestKustomize(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base/kustomization.yaml": `resources:
+- service.yaml
+`,
+		"base/service.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  type: ClusterIP
+`,
+		"overlay/kustomization.yaml": `namespace: prod
+resources:
+- ../base
+configMapGenerator:
+- name: settings
+  literals:
+  - level=debug
+  options:
+    disableNameSuffixHash: true
+patches:
+- path: service.yaml
+`,
+		"overlay/service.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  type: LoadBalancer
+`,
+		"remote/kustomization.yaml": `resources:
+- https://github.com/kubernetes-sigs/kustomize//examples/helloWorld?ref=v3.3.1
+`,
+		"missing/kustomization.yaml": `resources:
+- missing.yaml
+`,
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	overlay := filepath.Join(dir, "overlay")
+	if !IsKustomization(overlay) {
+		t.Errorf("expected %s to be a kustomization", overlay)
+	}
+	if IsKustomization(dir) {
+		t.Errorf("expected %s not to be a kustomization", dir)
+	}
+
+	manifests, err := Kustomize(overlay)
+	if err != nil {
+		t.Fatalf("Kustomize: unexpected error %v", err)
+	}
+
+	want := map[string][]byte{
+		filepath.Join(dir, "base", "service.yaml"): []byte(`apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: prod
+spec:
+  type: LoadBalancer
+`),
+		filepath.Join(overlay, "kustomization.yaml"): []byte(`apiVersion: v1
+data:
+  level: debug
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: prod
+`),
+	}
+	if !reflect.DeepEqual(manifests, want) {
+		t.Errorf("unexpected manifests. got %q, want %q", manifests, want)
+	}
+
+	for _, name := range []string{"remote", "missing"} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Kustomize(filepath.Join(dir, name)); err == nil {
+				t.Error("expected an error for a resource that is not local")
+			}
+		})
+	}
+}
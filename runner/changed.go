@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// changedFiles returns the files of the list that changed between the git ref
+// and the working tree of the repository of the current directory, which is
+// read from its .git directory. A file changed when the ref does not have it,
+// or has different contents. Standard input is always kept.
+func changedFiles(files []string, ref string) ([]string, error) {
+	repository, err := git.PlainOpenWithOptions(".", &git.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open git repository: %w", err)
+	}
+
+	hash, err := repository.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", ref, err)
+	}
+	commit, err := repository.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("get commit of %s: %w", ref, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("get tree of %s: %w", ref, err)
+	}
+
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("get worktree: %w", err)
+	}
+	root, err := filepath.EvalSymlinks(worktree.Filesystem.Root())
+	if err != nil {
+		return nil, fmt.Errorf("resolve worktree: %w", err)
+	}
+
+	var changed []string
+	for _, file := range files {
+		if file == "-" {
+			changed = append(changed, file)
+			continue
+		}
+
+		path, err := repositoryPath(root, file)
+		if err != nil {
+			return nil, err
+		}
+
+		entry, err := tree.FindEntry(path)
+		if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+			changed = append(changed, file)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("find %s in %s: %w", path, ref, err)
+		}
+
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read file: %w", err)
+		}
+		if !sameBlob(entry.Hash, contents) {
+			changed = append(changed, file)
+		}
+	}
+
+	return changed, nil
+}
+
+// repositoryPath returns the path of the file relative to the root of the
+// worktree, as used in the trees of the repository.
+func repositoryPath(root, file string) (string, error) {
+	path, err := filepath.Abs(file)
+	if err != nil {
+		return "", fmt.Errorf("get absolute path: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	relative, err := filepath.Rel(root, path)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is not in the git repository at %s", file, root)
+	}
+
+	return filepath.ToSlash(relative), nil
+}
+
+// sameBlob returns true if the contents are those of the blob with the given
+// hash. Files checked out with Windows line endings are compared with their
+// contents as committed, with Unix line endings.
+func sameBlob(hash plumbing.Hash, contents []byte) bool {
+	if plumbing.ComputeHash(plumbing.BlobObject, contents) == hash {
+		return true
+	}
+
+	if bytes.Contains(contents, []byte("\r\n")) {
+		normalized := bytes.ReplaceAll(contents, []byte("\r\n"), []byte("\n"))
+		return plumbing.ComputeHash(plumbing.BlobObject, normalized) == hash
+	}
+
+	return false
+}
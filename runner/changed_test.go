@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	repository, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("init repository: %v", err)
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		t.Fatalf("get worktree: %v", err)
+	}
+
+	writeFile(t, filepath.Join(dir, "unchanged.yaml"), "a: 1\n")
+	writeFile(t, filepath.Join(dir, "modified.yaml"), "a: 1\n")
+	writeFile(t, filepath.Join(dir, "crlf.yaml"), "a: 1\n")
+	writeFile(t, filepath.Join(dir, "nested", "unchanged.json"), "{}\n")
+	if err := worktree.AddGlob("."); err != nil {
+		t.Fatalf("add files: %v", err)
+	}
+	if _, err := worktree.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	writeFile(t, filepath.Join(dir, "modified.yaml"), "a: 2\n")
+	writeFile(t, filepath.Join(dir, "crlf.yaml"), "a: 1\r\n")
+	writeFile(t, filepath.Join(dir, "nested", "added.json"), "{}\n")
+
+	t.Chdir(dir)
+	files := []string{"crlf.yaml", "modified.yaml", "nested/added.json", "nested/unchanged.json", "unchanged.yaml", "-"}
+
+	changed, err := changedFiles(files, "HEAD")
+	if err != nil {
+		t.Fatalf("changed files: %v", err)
+	}
+
+	expected := []string{"modified.yaml", "nested/added.json", "-"}
+	if !reflect.DeepEqual(changed, expected) {
+		t.Errorf("changed files = %v, want %v", changed, expected)
+	}
+
+	if _, err := changedFiles(files, "unknown"); err == nil {
+		t.Error("expected an error for an unknown ref")
+	}
+}
@@ -0,0 +1,277 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/open-policy-agent/conftest/output"
+	"github.com/open-policy-agent/conftest/parser"
+	"github.com/open-policy-agent/conftest/parser/location"
+	jsonpatch "gopkg.in/evanphx/json-patch.v4"
+)
+
+// FixRunner is the runner for the Fix command, applying the fixes of the
+// failures and warnings found by the policies to the configuration files.
+type FixRunner struct {
+	TestRunner `mapstructure:",squash"`
+
+	// DryRun leaves the files unchanged.
+	DryRun bool `mapstructure:"dry-run"`
+}
+
+// FileFix is the result of fixing a single configuration file.
+type FileFix struct {
+	FileName string
+
+	// Original and Fixed are the contents of the file before and after the
+	// fixes were applied.
+	Original []byte
+	Fixed    []byte
+
+	// Applied are the results whose fixes were applied, and Errors the
+	// errors of the fixes that could not be.
+	Applied []output.Result
+	Errors  []error
+}
+
+// Changed returns true if the fixes changed the file.
+func (f FileFix) Changed() bool {
+	return string(f.Original) != string(f.Fixed)
+}
+
+// Run tests the configuration files, and applies the fixes of the failures
+// and warnings to the files they were found in, one after the other. Fixes
+// that cannot be applied are skipped. The files are written unless the run is
+// a dry run.
+//
+// The fixes are applied to the documents of the files that produced them, so
+// the files are neither combined, streamed nor rendered, whatever the settings
+// of the runner.
+func (r *FixRunner) Run(ctx context.Context, fileList []string) ([]FileFix, error) {
+	if slices.Contains(fileList, "-") {
+		return nil, fmt.Errorf("fixing standard input is not supported")
+	}
+
+	r.Combine = false
+	r.CombineUnchanged = false
+	r.Stream = false
+	r.Render = false
+
+	results, err := r.TestRunner.Run(ctx, fileList)
+	if err != nil {
+		return nil, err
+	}
+
+	fixes := fileFixes(results)
+	paths := make([]string, 0, len(fixes))
+	for path := range fixes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	fileFixes := make([]FileFix, 0, len(paths))
+	for _, path := range paths {
+		fileFix, err := r.fixFile(path, fixes[path])
+		if err != nil {
+			return nil, fmt.Errorf("fix %s: %w", path, err)
+		}
+
+		if !r.DryRun && fileFix.Changed() {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, fmt.Errorf("stat %s: %w", path, err)
+			}
+			if err := os.WriteFile(path, fileFix.Fixed, info.Mode().Perm()); err != nil {
+				return nil, fmt.Errorf("write %s: %w", path, err)
+			}
+		}
+
+		fileFixes = append(fileFixes, fileFix)
+	}
+
+	return fileFixes, nil
+}
+
+// fileFixes returns the failures and warnings with fixes by the file they
+// were found in. The same fix reported by several rules is only kept once.
+func fileFixes(results output.CheckResults) map[string][]output.Result {
+	fixes := make(map[string][]output.Result)
+	seen := make(map[string]map[string]bool)
+	for _, checkResult := range results {
+		for _, result := range slices.Concat(checkResult.Failures, checkResult.Warnings) {
+			if result.Fix == nil {
+				continue
+			}
+
+			key, err := json.Marshal(result.Fix)
+			if err != nil || seen[checkResult.FileName][string(key)] {
+				continue
+			}
+			if seen[checkResult.FileName] == nil {
+				seen[checkResult.FileName] = make(map[string]bool)
+			}
+			seen[checkResult.FileName][string(key)] = true
+
+			fixes[checkResult.FileName] = append(fixes[checkResult.FileName], result)
+		}
+	}
+
+	return fixes
+}
+
+func (r *FixRunner) fixFile(path string, results []output.Result) (FileFix, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return FileFix{}, fmt.Errorf("read file: %w", err)
+	}
+
+	var fileParser parser.Parser
+	if r.Parser != "" {
+		fileParser, err = parser.New(r.Parser)
+	} else {
+		fileParser, _, err = parser.NewFromContents(path, contents)
+	}
+	if err != nil {
+		return FileFix{}, fmt.Errorf("new parser: %w", err)
+	}
+	if p, ok := fileParser.(parser.PathAwareParser); ok {
+		p.SetPath(path)
+	}
+
+	fileFix := FileFix{
+		FileName: path,
+		Original: contents,
+		Fixed:    contents,
+	}
+
+	fixer, ok := fileParser.(parser.Fixer)
+	if !ok {
+		fileFix.Errors = append(fileFix.Errors, fmt.Errorf("the parser of %s cannot apply fixes", path))
+		return fileFix, nil
+	}
+
+	for _, result := range results {
+		fixed, err := fixer.Fix(fileFix.Fixed, result.Fix.Document, func(document any) (any, error) {
+			return applyFix(document, *result.Fix)
+		})
+		if err != nil {
+			fileFix.Errors = append(fileFix.Errors, fmt.Errorf("fix %q: %w", result.Message, err))
+			continue
+		}
+
+		fileFix.Fixed = fixed
+		fileFix.Applied = append(fileFix.Applied, result)
+	}
+
+	return fileFix, nil
+}
+
+// applyFix returns the document with the patch of the fix applied to it. The
+// paths of JSON Patch operations are relative to the path of the fix, as is
+// the value a merge patch is merged into.
+func applyFix(document any, fix output.Fix) (any, error) {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("marshal document: %w", err)
+	}
+
+	var operations []any
+	switch fix.Type {
+	case output.FixJSONPatch:
+		patch, _ := fix.Patch.([]any)
+		for _, operation := range patch {
+			operation, ok := operation.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("JSON Patch operations must be objects")
+			}
+
+			prefixed := make(map[string]any, len(operation))
+			for key, value := range operation {
+				if s, ok := value.(string); ok && (key == "path" || key == "from") {
+					value = fix.Path + s
+				}
+				prefixed[key] = value
+			}
+			operations = append(operations, prefixed)
+		}
+	case output.FixMergePatch:
+		target, err := valueAt(document, fix.Path)
+		if err != nil {
+			return nil, err
+		}
+		targetData, err := json.Marshal(target)
+		if err != nil {
+			return nil, fmt.Errorf("marshal value: %w", err)
+		}
+		patchData, err := json.Marshal(fix.Patch)
+		if err != nil {
+			return nil, fmt.Errorf("marshal patch: %w", err)
+		}
+
+		merged, err := jsonpatch.MergePatch(targetData, patchData)
+		if err != nil {
+			return nil, fmt.Errorf("merge patch: %w", err)
+		}
+		if fix.Path == "" {
+			return unmarshalPatched(merged)
+		}
+		operations = []any{map[string]any{"op": "replace", "path": fix.Path, "value": json.RawMessage(merged)}}
+	default:
+		return nil, fmt.Errorf("unknown fix type %q", fix.Type)
+	}
+
+	operationsData, err := json.Marshal(operations)
+	if err != nil {
+		return nil, fmt.Errorf("marshal patch: %w", err)
+	}
+	patch, err := jsonpatch.DecodePatch(operationsData)
+	if err != nil {
+		return nil, fmt.Errorf("decode patch: %w", err)
+	}
+	patched, err := patch.Apply(data)
+	if err != nil {
+		return nil, fmt.Errorf("apply patch: %w", err)
+	}
+
+	return unmarshalPatched(patched)
+}
+
+func unmarshalPatched(patched []byte) (any, error) {
+	var value any
+	if err := json.Unmarshal(patched, &value); err != nil {
+		return nil, fmt.Errorf("unmarshal patched document: %w", err)
+	}
+
+	return value, nil
+}
+
+// valueAt returns the value at the JSON pointer within the document.
+func valueAt(document any, pointer string) (any, error) {
+	segments, err := location.ParsePath(pointer)
+	if err != nil {
+		return nil, fmt.Errorf("parse path: %w", err)
+	}
+
+	value := document
+	for _, segment := range segments {
+		switch v := value.(type) {
+		case map[string]any:
+			value = v[segment]
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, fmt.Errorf("index %s not found at %s", segment, pointer)
+			}
+			value = v[index]
+		default:
+			return nil, fmt.Errorf("%s not found", pointer)
+		}
+	}
+
+	return value, nil
+}
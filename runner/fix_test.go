@@ -0,0 +1,211 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/open-policy-agent/conftest/output"
+)
+
+func TestApplyFix(t *testing.T) {
+	document := func() any {
+		return []any{
+			map[string]any{"name": "web", "replicas": float64(1)},
+			map[string]any{"name": "api", "replicas": float64(2)},
+		}
+	}
+
+	tests := []struct {
+		desc     string
+		fix      output.Fix
+		expected any
+		wantErr  bool
+	}{
+		{
+			desc: "JSON Patch",
+			fix: output.Fix{
+				Type:  output.FixJSONPatch,
+				Patch: []any{map[string]any{"op": "replace", "path": "/0/replicas", "value": float64(3)}},
+			},
+			expected: []any{
+				map[string]any{"name": "web", "replicas": float64(3)},
+				map[string]any{"name": "api", "replicas": float64(2)},
+			},
+		},
+		{
+			desc: "JSON Patch relative to the path of the fix",
+			fix: output.Fix{
+				Type:  output.FixJSONPatch,
+				Patch: []any{map[string]any{"op": "remove", "path": "/replicas"}},
+				Path:  "/1",
+			},
+			expected: []any{
+				map[string]any{"name": "web", "replicas": float64(1)},
+				map[string]any{"name": "api"},
+			},
+		},
+		{
+			desc: "merge patch relative to the path of the fix",
+			fix: output.Fix{
+				Type:  output.FixMergePatch,
+				Patch: map[string]any{"replicas": nil, "owner": "platform"},
+				Path:  "/0",
+			},
+			expected: []any{
+				map[string]any{"name": "web", "owner": "platform"},
+				map[string]any{"name": "api", "replicas": float64(2)},
+			},
+		},
+		{
+			desc: "failed test operation",
+			fix: output.Fix{
+				Type: output.FixJSONPatch,
+				Patch: []any{
+					map[string]any{"op": "test", "path": "/0/name", "value": "api"},
+					map[string]any{"op": "remove", "path": "/0"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := applyFix(document(), test.fix)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("applyFix() error = %v, want %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("applyFix() = %v, want %v", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestFixRunner(t *testing.T) {
+	dir := t.TempDir()
+	policyFile := filepath.Join(dir, "policy", "main.rego")
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(policyFile), 0700); err != nil {
+		t.Fatalf("create directory: %v", err)
+	}
+
+	policy := `package main
+
+deny contains {"msg": "replicas must be at least 2", "fix": {"replicas": 2}} if input.replicas < 2
+
+deny contains {"msg": "the name must be set", "fix": [{"op": "test", "path": "/name", "value": "web"}]} if not input.owner
+`
+	config := "# web\nname: web\nreplicas: 1 # scaled by hand\n---\nname: api\nreplicas: 3\n"
+	if err := os.WriteFile(policyFile, []byte(policy), 0600); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	if err := os.WriteFile(configFile, []byte(config), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	runner := &FixRunner{
+		TestRunner: TestRunner{
+			Policy:      []string{filepath.Dir(policyFile)},
+			RegoVersion: "v1",
+			Namespace:   []string{"main"},
+		},
+		DryRun: true,
+	}
+
+	fileFixes, err := runner.Run(context.Background(), []string{configFile})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(fileFixes) != 1 {
+		t.Fatalf("got fixes for %d files, want 1", len(fileFixes))
+	}
+
+	fileFix := fileFixes[0]
+	expected := "# web\nname: web\nreplicas: 2 # scaled by hand\n---\nname: api\nreplicas: 3\n"
+	if string(fileFix.Fixed) != expected {
+		t.Errorf("unexpected fixed file:\n%s\nwant:\n%s", fileFix.Fixed, expected)
+	}
+	if len(fileFix.Applied) != 2 {
+		t.Errorf("got %d applied fixes, want 2", len(fileFix.Applied))
+	}
+	if len(fileFix.Errors) != 1 {
+		t.Errorf("got errors %v, want 1 error", fileFix.Errors)
+	}
+
+	contents, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if string(contents) != config {
+		t.Errorf("the file was written by a dry run:\n%s", contents)
+	}
+
+	runner.DryRun = false
+	if _, err := runner.Run(context.Background(), []string{configFile}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	contents, err = os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if string(contents) != expected {
+		t.Errorf("unexpected written file:\n%s\nwant:\n%s", contents, expected)
+	}
+}
+
+func TestFixRunnerTestsFilesAsWritten(t *testing.T) {
+	dir := t.TempDir()
+	policyFile := filepath.Join(dir, "policy", "main.rego")
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(policyFile), 0700); err != nil {
+		t.Fatalf("create directory: %v", err)
+	}
+
+	policy := `package main
+
+deny contains {"msg": "replicas must be at least 2", "fix": {"replicas": 2}} if input.replicas < 2
+`
+	config := "name: web\nreplicas: 3\n---\nname: api\nreplicas: 1\n"
+	if err := os.WriteFile(policyFile, []byte(policy), 0600); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	if err := os.WriteFile(configFile, []byte(config), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	// Settings that are read from a configuration file, which would otherwise
+	// combine the documents, or stream them without the fixes of the test run
+	// applying to the same documents.
+	runner := &FixRunner{
+		TestRunner: TestRunner{
+			Policy:      []string{filepath.Dir(policyFile)},
+			RegoVersion: "v1",
+			Namespace:   []string{"main"},
+			Combine:     true,
+			Stream:      true,
+			Render:      true,
+		},
+		DryRun: true,
+	}
+
+	fileFixes, err := runner.Run(context.Background(), []string{configFile})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(fileFixes) != 1 {
+		t.Fatalf("got fixes for %d files, want 1", len(fileFixes))
+	}
+
+	expected := "name: web\nreplicas: 3\n---\nname: api\nreplicas: 2\n"
+	if string(fileFixes[0].Fixed) != expected {
+		t.Errorf("unexpected fixed file:\n%s\nwant:\n%s", fileFixes[0].Fixed, expected)
+	}
+}
@@ -0,0 +1,120 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/open-policy-agent/conftest/parser/ignore"
+)
+
+// ignoreFile is the name of the files with the gitignore patterns of the
+// paths that are skipped when looking for files in directories.
+const ignoreFile = ".conftestignore"
+
+// ignoreFiles matches paths against the patterns of the ignore files of their
+// directories, and of the directories above them up to the root. The patterns
+// of deeper directories take precedence, as do those of a .conftestignore file
+// over those of a .gitignore file in the same directory.
+type ignoreFiles struct {
+	root          string
+	names         []string
+	withGitignore bool
+	patterns      map[string][]gitignore.Pattern
+}
+
+// newIgnoreFiles returns the ignore files of the paths within the directory.
+// The root is the working directory when the directory is within it, so that
+// the ignore files of the working tree apply to any of its directories.
+func newIgnoreFiles(directory string, withGitignore bool) (*ignoreFiles, error) {
+	root, err := filepath.Abs(directory)
+	if err != nil {
+		return nil, fmt.Errorf("get absolute path: %w", err)
+	}
+
+	if wd, err := os.Getwd(); err == nil {
+		if _, ok := relativePath(wd, root); ok {
+			root = wd
+		}
+	}
+
+	names := []string{ignoreFile}
+	if withGitignore {
+		names = []string{".gitignore", ignoreFile}
+	}
+
+	return &ignoreFiles{
+		root:          root,
+		names:         names,
+		withGitignore: withGitignore,
+		patterns:      make(map[string][]gitignore.Pattern),
+	}, nil
+}
+
+// ignored returns true if the path is ignored by the ignore files.
+func (i *ignoreFiles) ignored(path string, isDir bool) (bool, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false, fmt.Errorf("get absolute path: %w", err)
+	}
+
+	relative, ok := relativePath(i.root, abs)
+	if !ok || relative == "." {
+		return false, nil
+	}
+	components := strings.Split(filepath.ToSlash(relative), "/")
+
+	var patterns []gitignore.Pattern
+	for depth := 0; depth < len(components); depth++ {
+		directoryPatterns, err := i.load(components[:depth])
+		if err != nil {
+			return false, err
+		}
+		patterns = append(patterns, directoryPatterns...)
+	}
+
+	return gitignore.NewMatcher(patterns).Match(components, isDir), nil
+}
+
+// load returns the patterns of the ignore files of the directory with the given
+// path components relative to the root.
+func (i *ignoreFiles) load(directory []string) ([]gitignore.Pattern, error) {
+	key := strings.Join(directory, "/")
+	if patterns, ok := i.patterns[key]; ok {
+		return patterns, nil
+	}
+
+	var patterns []gitignore.Pattern
+	for _, name := range i.names {
+		path := filepath.Join(i.root, filepath.Join(directory...), name)
+		contents, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read ignore file: %w", err)
+		}
+
+		filePatterns, err := ignore.Patterns(contents, directory)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		patterns = append(patterns, filePatterns...)
+	}
+
+	i.patterns[key] = patterns
+	return patterns, nil
+}
+
+// relativePath returns the path relative to the base, if it is within it.
+func relativePath(base, path string) (string, bool) {
+	relative, err := filepath.Rel(base, path)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return relative, true
+}
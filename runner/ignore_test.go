@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestListFilesIgnoreFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".conftestignore"), "vendor/\n*.gen.yaml\n")
+	writeFile(t, filepath.Join(dir, ".gitignore"), "build/\n")
+	writeFile(t, filepath.Join(dir, "config.yaml"), "")
+	writeFile(t, filepath.Join(dir, "build", "output.yaml"), "")
+	writeFile(t, filepath.Join(dir, "vendor", "module", "config.yaml"), "")
+	writeFile(t, filepath.Join(dir, "k8s", ".conftestignore"), "!crds.gen.yaml\nlocal/\n")
+	writeFile(t, filepath.Join(dir, "k8s", "crds.gen.yaml"), "")
+	writeFile(t, filepath.Join(dir, "k8s", "deployment.yaml"), "")
+	writeFile(t, filepath.Join(dir, "k8s", "local", "overrides.yaml"), "")
+	writeFile(t, filepath.Join(dir, "k8s", "service.gen.yaml"), "")
+	t.Chdir(dir)
+
+	tests := []struct {
+		name      string
+		fileList  []string
+		gitignore bool
+		expected  []string
+	}{
+		{
+			name:     "conftestignore files",
+			fileList: []string{"."},
+			expected: []string{".gitignore", "build/output.yaml", "config.yaml", "k8s/crds.gen.yaml", "k8s/deployment.yaml"},
+		},
+		{
+			name:      "gitignore files",
+			fileList:  []string{"."},
+			gitignore: true,
+			expected:  []string{".gitignore", "config.yaml", "k8s/crds.gen.yaml", "k8s/deployment.yaml"},
+		},
+		{
+			name:     "ignore files of the working directory apply to subdirectories",
+			fileList: []string{"k8s"},
+			expected: []string{"k8s/crds.gen.yaml", "k8s/deployment.yaml"},
+		},
+		{
+			name:     "files given explicitly",
+			fileList: []string{"k8s/service.gen.yaml", "k8s/local"},
+			expected: []string{"k8s/service.gen.yaml"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			files, err := ListFiles(test.fileList, "", test.gitignore)
+			if err != nil {
+				t.Fatalf("list files: %v", err)
+			}
+
+			if !reflect.DeepEqual(files, test.expected) {
+				t.Errorf("files = %v, want %v", files, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/open-policy-agent/conftest/output"
+	"github.com/open-policy-agent/conftest/parser"
+)
+
+// Mapping routes the files that match one of its paths to their own policies,
+// namespaces, parser and data, which take the place of those of the runner.
+// The fields that are not set are those of the runner. Mappings are declared
+// in the configuration file, e.g.:
+//
+//	[[mapping]]
+//	paths = ["terraform/**"]
+//	policy = ["policy/terraform"]
+//	namespace = ["terraform"]
+//	parser = "hcl2"
+type Mapping struct {
+	// Paths are the globs of the paths of the files, relative to the working
+	// directory. A ** matches any number of directories, and a glob that
+	// matches a directory matches all of the files within it.
+	Paths []string
+
+	Policy    []string
+	Namespace []string
+	Parser    string
+	Data      []string
+}
+
+// runMappings tests the files of every mapping with a runner of its own, and
+// the files that match no mapping with the settings of the runner itself. A
+// file belongs to the first mapping it matches. The results of all of the
+// runners are reported together.
+func (t *TestRunner) runMappings(ctx context.Context, fileList []string) (output.CheckResults, error) {
+	matchers, err := compileMappings(t.Mappings)
+	if err != nil {
+		return nil, err
+	}
+
+	sources, fileList := splitSources(fileList, t.Render)
+
+	var files []string
+	if len(fileList) > 0 {
+		files, err = ListFiles(fileList, t.Ignore, t.GitIgnore)
+		if err != nil {
+			return nil, fmt.Errorf("parse files: %w", err)
+		}
+	}
+
+	groups := make([][]string, len(t.Mappings)+1)
+	for _, file := range files {
+		index := matchMapping(matchers, file)
+		if index < 0 {
+			index = len(t.Mappings)
+		}
+		groups[index] = append(groups[index], file)
+	}
+	groups[len(t.Mappings)] = append(groups[len(t.Mappings)], sources...)
+
+	if len(t.mapped) == 0 {
+		t.mapped = make([]*TestRunner, len(groups))
+	}
+
+	var results output.CheckResults
+	for i, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+
+		if t.mapped[i] == nil {
+			t.mapped[i] = t.mappedRunner(i)
+		}
+
+		groupResults, err := t.mapped[i].Run(ctx, group)
+		if err != nil {
+			if i < len(t.Mappings) {
+				return nil, fmt.Errorf("mapping %s: %w", strings.Join(t.Mappings[i].Paths, ", "), err)
+			}
+			return nil, err
+		}
+		results = append(results, groupResults...)
+	}
+
+	return results, nil
+}
+
+// mappedRunner returns a runner for the files of the mapping at the given
+// index, or for the files that match no mapping when the index is past the
+// last mapping. Only the latter downloads the policies to update.
+func (t *TestRunner) mappedRunner(index int) *TestRunner {
+	runner := &TestRunner{}
+	*runner = *t
+	runner.Mappings = nil
+	runner.mapped = nil
+	runner.engine = nil
+	runner.configurations = nil
+	runner.details = parser.NewDetails()
+
+	if index >= len(t.Mappings) {
+		return runner
+	}
+
+	mapping := t.Mappings[index]
+	runner.Update = nil
+	if len(mapping.Policy) > 0 {
+		runner.Policy = mapping.Policy
+	}
+	if len(mapping.Namespace) > 0 {
+		runner.Namespace = mapping.Namespace
+		runner.AllNamespaces = false
+	}
+	if mapping.Parser != "" {
+		runner.Parser = mapping.Parser
+	}
+	if len(mapping.Data) > 0 {
+		runner.Data = mapping.Data
+	}
+
+	return runner
+}
+
+func compileMappings(mappings []Mapping) ([][]glob.Glob, error) {
+	matchers := make([][]glob.Glob, len(mappings))
+	for i, mapping := range mappings {
+		if len(mapping.Paths) == 0 {
+			return nil, fmt.Errorf("mapping %d has no paths", i+1)
+		}
+
+		for _, pattern := range mapping.Paths {
+			pattern = strings.TrimSuffix(path.Clean(filepath.ToSlash(pattern)), "/")
+			for _, variant := range anyDirectories(pattern) {
+				matcher, err := glob.Compile(variant, '/')
+				if err != nil {
+					return nil, fmt.Errorf("compile mapping path %q: %w", pattern, err)
+				}
+				matchers[i] = append(matchers[i], matcher)
+			}
+		}
+	}
+
+	return matchers, nil
+}
+
+// anyDirectories returns the pattern along with its variants without each of
+// its **/, which the globs require to match at least one directory, whereas
+// e.g. terraform/**/*.tf is expected to match terraform/main.tf.
+func anyDirectories(pattern string) []string {
+	index := strings.Index(pattern, "**/")
+	if index < 0 {
+		return []string{pattern}
+	}
+
+	var patterns []string
+	for _, rest := range anyDirectories(pattern[index+3:]) {
+		patterns = append(patterns, pattern[:index+3]+rest, pattern[:index]+rest)
+	}
+	return patterns
+}
+
+// matchMapping returns the index of the first mapping with a path that matches
+// the file or one of its directories, or -1 when none does.
+func matchMapping(matchers [][]glob.Glob, file string) int {
+	if file == "-" {
+		return -1
+	}
+
+	if filepath.IsAbs(file) {
+		if wd, err := os.Getwd(); err == nil {
+			if relative, err := filepath.Rel(wd, file); err == nil {
+				file = relative
+			}
+		}
+	}
+	file = path.Clean(filepath.ToSlash(file))
+
+	for i, globs := range matchers {
+		for _, matcher := range globs {
+			for candidate := file; candidate != "." && candidate != "/"; candidate = path.Dir(candidate) {
+				if matcher.Match(candidate) {
+					return i
+				}
+			}
+		}
+	}
+
+	return -1
+}
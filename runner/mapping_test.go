@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestMatchMapping(t *testing.T) {
+	matchers, err := compileMappings([]Mapping{
+		{Paths: []string{"terraform/**/*.tf"}},
+		{Paths: []string{"./k8s", "charts/*/values.yaml"}},
+		{Paths: []string{"**/Dockerfile"}},
+	})
+	if err != nil {
+		t.Fatalf("compile mappings: %v", err)
+	}
+
+	tests := []struct {
+		file     string
+		expected int
+	}{
+		{file: "terraform/modules/vpc/main.tf", expected: 0},
+		{file: "./terraform/prod/main.tf", expected: 0},
+		{file: "terraform/main.tf", expected: 0},
+		{file: "terraform/README.md", expected: -1},
+		{file: "k8s/deployment.yaml", expected: 1},
+		{file: "k8s/base/service.yaml", expected: 1},
+		{file: "k8s.yaml", expected: -1},
+		{file: "charts/web/values.yaml", expected: 1},
+		{file: "services/api/Dockerfile", expected: 2},
+		{file: "Dockerfile", expected: 2},
+		{file: "-", expected: -1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			if got := matchMapping(matchers, test.file); got != test.expected {
+				t.Errorf("matchMapping(%q) = %d, want %d", test.file, got, test.expected)
+			}
+		})
+	}
+
+	if _, err := compileMappings([]Mapping{{Policy: []string{"policy"}}}); err == nil {
+		t.Error("expected an error for a mapping without paths")
+	}
+}
+
+func TestRunnerMappings(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "policy", "main.rego"), "package main\n\ndeny contains \"default\" if true\n")
+	writeFile(t, filepath.Join(dir, "policy", "k8s", "k8s.rego"), "package k8s\n\ndeny contains \"kubernetes\" if input.kind\n")
+	writeFile(t, filepath.Join(dir, "k8s", "deployment.yaml"), "kind: Deployment\n")
+	writeFile(t, filepath.Join(dir, "config.yaml"), "name: config\n")
+	t.Chdir(dir)
+
+	runner := &TestRunner{
+		Policy:      []string{"policy"},
+		RegoVersion: "v1",
+		Namespace:   []string{"main"},
+		Mappings: []Mapping{
+			{Paths: []string{"k8s"}, Policy: []string{"policy/k8s"}, Namespace: []string{"k8s"}},
+		},
+	}
+
+	results, err := runner.Run(context.Background(), []string{"."})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	var got []string
+	for _, result := range results {
+		for _, failure := range result.Failures {
+			got = append(got, result.FileName+" "+result.Namespace+" "+failure.Message)
+		}
+	}
+	sort.Strings(got)
+
+	expected := []string{
+		"config.yaml main default",
+		"k8s/deployment.yaml k8s kubernetes",
+	}
+	if len(got) != len(expected) || got[0] != expected[0] || got[1] != expected[1] {
+		t.Errorf("got failures %v, want %v", got, expected)
+	}
+}
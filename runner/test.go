@@ -3,16 +3,22 @@ package runner
 import (
 	"context"
 	"fmt"
+	"maps"
 	"os"
 	"path"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"slices"
 	"strings"
 
 	"github.com/open-policy-agent/conftest/downloader"
 	"github.com/open-policy-agent/conftest/output"
 	"github.com/open-policy-agent/conftest/parser"
+	"github.com/open-policy-agent/conftest/parser/suppression"
 	"github.com/open-policy-agent/conftest/policy"
+	"github.com/open-policy-agent/conftest/render"
+	"golang.org/x/sync/errgroup"
 )
 
 // TestRunner is the runner for the Test command, executing
@@ -26,8 +32,12 @@ type TestRunner struct {
 	Data               []string
 	Update             []string
 	Ignore             string
+	GitIgnore          bool `mapstructure:"gitignore"`
 	Parser             string
 	StdinFilename      string `mapstructure:"stdin-filename"`
+	FailOn             string `mapstructure:"fail-on"`
+	Baseline           string
+	BaselineCreate     string `mapstructure:"baseline-create"`
 	Namespace          []string
 	AllNamespaces      bool `mapstructure:"all-namespaces"`
 	FailOnWarn         bool `mapstructure:"fail-on-warn"`
@@ -36,51 +46,135 @@ type TestRunner struct {
 	SuppressExceptions bool `mapstructure:"suppress-exceptions"`
 	ShowBuiltinErrors  bool `mapstructure:"show-builtin-errors"`
 	Combine            bool
+	CombineUnchanged   bool   `mapstructure:"combine-unchanged"`
+	ChangedSince       string `mapstructure:"changed-since"`
 	Quiet              bool
 	Output             string
+	Parallel           int
+	Stream             bool
+	Watch              bool
+	HCL2Evaluate       bool `mapstructure:"hcl2-evaluate"`
+	Render             bool
+	Values             []string
+
+	// The mappings of the files to their own policies, see Mapping.
+	Mappings []Mapping `mapstructure:"mapping"`
+
+	// The attributes that inline suppression directives must declare to be applied.
+	RequireSuppressionReason bool `mapstructure:"require-suppression-reason"`
+	RequireSuppressionExpiry bool `mapstructure:"require-suppression-expiry"`
+
+	// State that is kept between runs when watching for changes, see Invalidate.
+	engine         *policy.Engine
+	configurations map[string]any
+	details        parser.Details
+	updated        bool
+	mapped         []*TestRunner
 }
 
 // Run executes the TestRunner, verifying all Rego policies against the given
 // list of configuration files.
 func (t *TestRunner) Run(ctx context.Context, fileList []string) (output.CheckResults, error) {
-	files, err := parseFileList(fileList, t.Ignore)
-	if err != nil {
-		return nil, fmt.Errorf("parse files: %w", err)
+	if len(t.Mappings) > 0 {
+		return t.runMappings(ctx, fileList)
+	}
+
+	var err error
+
+	// With Render, Helm charts and kustomizations are rendered rather than
+	// tested file by file, see renderSources.
+	sources, fileList := splitSources(fileList, t.Render)
+
+	var files []string
+	if len(fileList) > 0 || len(sources) == 0 {
+		files, err = ListFiles(fileList, t.Ignore, t.GitIgnore)
+		if err != nil {
+			return nil, fmt.Errorf("parse files: %w", err)
+		}
+	}
+
+	// Only the files that changed since the given ref are tested. When the
+	// configurations are combined, the unchanged files can be kept as context
+	// for the changed ones, but are not tested on their own.
+	var unchanged []string
+	if t.CombineUnchanged && (!t.Combine || t.ChangedSince == "") {
+		return nil, fmt.Errorf("including unchanged files requires combining the files that changed since a ref")
 	}
+	if t.ChangedSince != "" {
+		changed, err := changedFiles(files, t.ChangedSince)
+		if err != nil {
+			return nil, fmt.Errorf("find changed files: %w", err)
+		}
+		if len(changed) == 0 && len(sources) == 0 {
+			return nil, nil
+		}
 
-	var configurations map[string]any
-	if t.Parser != "" {
-		configurations, err = parser.ParseConfigurationsAs(files, t.Parser)
-	} else {
-		configurations, err = parser.ParseConfigurations(files)
+		if t.CombineUnchanged {
+			for _, file := range files {
+				if !slices.Contains(changed, file) {
+					unchanged = append(unchanged, file)
+				}
+			}
+		} else {
+			files = changed
+		}
 	}
+
+	// Files that are streamed are decoded one document at a time after the policies
+	// have been loaded, rather than being parsed up front with all other files.
+	var streamed []string
+	if t.Stream {
+		if t.Combine {
+			return nil, fmt.Errorf("streaming is not supported when combining configurations")
+		}
+
+		files, streamed = splitStreamable(files, t.Parser)
+	}
+
+	configurations, details, err := t.parseConfigurations(files)
 	if err != nil {
 		return nil, fmt.Errorf("parse configurations: %w", err)
 	}
+	if err := t.renderSources(sources, configurations, details); err != nil {
+		return nil, fmt.Errorf("render: %w", err)
+	}
 	renameStdinConfiguration(configurations, t.StdinFilename)
+	renameStdinConfiguration(details.Locations, t.StdinFilename)
+	renameStdinConfiguration(details.Suppressions, t.StdinFilename)
+	renameStdinConfiguration(details.Objects, t.StdinFilename)
+	renameStdinConfiguration(details.Validations, t.StdinFilename)
+	renameStdinConfiguration(details.Findings, t.StdinFilename)
 
 	// When there are policies to download, they are currently placed in the first
-	// directory that appears in the list of policies.
-	if len(t.Update) > 0 {
+	// directory that appears in the list of policies. Policies are only downloaded
+	// once, even when the runner is run again after a change.
+	if len(t.Update) > 0 && !t.updated {
 		if err := downloader.Download(ctx, t.Policy[0], t.Update, downloader.WithOverwrite()); err != nil {
 			return nil, fmt.Errorf("update policies: %w", err)
 		}
+		t.updated = true
 	}
 
-	capabilities, err := policy.LoadCapabilities(t.Capabilities)
+	engine, err := t.loadEngine()
 	if err != nil {
-		return nil, fmt.Errorf("load capabilities: %w", err)
-	}
-	opts := policy.CompilerOptions{
-		Strict:       t.Strict,
-		RegoVersion:  t.RegoVersion,
-		Capabilities: capabilities,
+		return nil, err
 	}
-	engine, err := policy.LoadWithData(t.Policy, t.Data, opts)
-	if err != nil {
-		return nil, fmt.Errorf("load: %w", err)
+	engine.SetLocations(details.Locations)
+	engine.SetSuppressions(details.Suppressions, suppression.Requirements{
+		Reason: t.RequireSuppressionReason,
+		Expiry: t.RequireSuppressionExpiry,
+	})
+	engine.SetObjects(details.Objects)
+	engine.SetValidations(details.Validations)
+	engine.SetFindings(details.Findings)
+
+	// A parallelism of zero or less evaluates as many files at the same time
+	// as there are CPUs available.
+	parallelism := t.Parallel
+	if parallelism < 1 {
+		parallelism = runtime.NumCPU()
 	}
-	engine.EnableInterQueryCache()
+	engine.SetParallelism(parallelism)
 
 	if t.Trace {
 		engine.EnableTracing()
@@ -112,17 +206,245 @@ func (t *TestRunner) Run(ctx context.Context, fileList []string) (output.CheckRe
 		} else {
 			result, err := engine.Check(ctx, configurations, namespace)
 			if err != nil {
-				return nil, fmt.Errorf("query rule: %w", err)
+				return nil, fmt.Errorf("check: %w", err)
 			}
 
 			results = append(results, result...)
 		}
 	}
 
+	// Documents are validated against their schemas once, rather than for
+	// every namespace, and only when their files are tested.
+	tested := maps.Clone(configurations)
+	for _, file := range unchanged {
+		delete(tested, file)
+	}
+	results = append(results, engine.CheckSchemas(tested)...)
+	results = append(results, engine.CheckStrict(tested)...)
+
+	streamResults, err := t.checkStreams(ctx, engine, streamed, namespaces, parallelism)
+	if err != nil {
+		return nil, fmt.Errorf("check streams: %w", err)
+	}
+	results = append(results, streamResults...)
+
 	return results, nil
 }
 
-func renameStdinConfiguration(configurations map[string]any, stdinFilename string) {
+// Invalidate discards the state kept from previous runs that depends on the
+// given paths, which have changed since the last run. The policies are only
+// loaded again when a Rego file or a data file changed, and only the input
+// files that changed are parsed again.
+func (t *TestRunner) Invalidate(paths []string) {
+	for _, runner := range t.mapped {
+		if runner != nil {
+			runner.Invalidate(paths)
+		}
+	}
+
+	for _, path := range paths {
+		if filepath.Ext(path) == ".rego" || withinPaths(path, t.Data) {
+			t.engine = nil
+		}
+
+		// The evaluated values of HCL2 files depend on the other files of their
+		// module and on the variable files, so these are all parsed again.
+		hcl2 := t.HCL2Evaluate && isHCL2(path)
+
+		for file := range t.configurations {
+			if filepath.Clean(file) == filepath.Clean(path) || (hcl2 && isHCL2(file)) {
+				delete(t.configurations, file)
+				t.details.Delete(file)
+			}
+		}
+	}
+}
+
+// isHCL2 returns true when the file at the path is parsed as HCL2 by default.
+func isHCL2(path string) bool {
+	return slices.Contains([]string{".hcl", ".tf", ".tfvars"}, filepath.Ext(path))
+}
+
+// parseConfigurations parses the given files, reusing the configurations of
+// the files that were parsed by a previous run and have not changed since.
+func (t *TestRunner) parseConfigurations(files []string) (map[string]any, parser.Details, error) {
+	var changed []string
+	for _, file := range files {
+		if _, ok := t.configurations[file]; !ok || file == "-" {
+			changed = append(changed, file)
+		}
+	}
+
+	parsed, parsedDetails, err := parser.ParseConfigurationsWithDetails(changed, t.Parser)
+	if err != nil {
+		return nil, parser.Details{}, err
+	}
+
+	configurations := make(map[string]any, len(files))
+	details := parser.NewDetails()
+	for _, file := range files {
+		if configuration, ok := parsed[file]; ok {
+			configurations[file] = configuration
+			details.Copy(file, parsedDetails)
+			continue
+		}
+
+		configurations[file] = t.configurations[file]
+		details.Copy(file, t.details)
+	}
+
+	// Files that no longer exist are dropped from the kept state. The returned
+	// maps are copies, as the configuration from stdin is renamed afterwards.
+	t.configurations, t.details = configurations, details
+	copied := parser.NewDetails()
+	for file := range configurations {
+		copied.Copy(file, details)
+	}
+
+	return maps.Clone(configurations), copied, nil
+}
+
+// renderSources renders the given Helm charts, with the values files of the
+// runner, and builds the given kustomizations, adding the rendered manifests to
+// the configurations under the path of the template or the file they originate
+// from. The sources are rendered on every run, as the manifests depend on more
+// files than the ones they are named after.
+func (t *TestRunner) renderSources(sources []string, configurations map[string]any, details parser.Details) error {
+	parserName := t.Parser
+	if parserName == "" {
+		parserName = parser.KUBERNETES
+	}
+
+	for _, source := range sources {
+		var manifests map[string][]byte
+		var err error
+		if render.IsChart(source) {
+			manifests, err = render.Helm(source, t.Values)
+		} else {
+			manifests, err = render.Kustomize(source)
+		}
+		if err != nil {
+			return fmt.Errorf("render %s: %w", source, err)
+		}
+
+		rendered, renderedDetails, err := parser.ParseContentsWithDetails(manifests, parserName)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", source, err)
+		}
+
+		for file, configuration := range rendered {
+			// The lines of a rendered manifest are not the lines of its source,
+			// so the results of rendered manifests are not given a location.
+			delete(renderedDetails.Locations, file)
+
+			configurations[file] = configuration
+			details.Copy(file, renderedDetails)
+		}
+	}
+
+	return nil
+}
+
+// splitSources separates the directories of Helm charts and kustomizations
+// from the other files and directories in the given list, when they are to be
+// rendered. Otherwise, their files are tested as they are.
+func splitSources(fileList []string, enabled bool) (sources []string, files []string) {
+	if !enabled {
+		return nil, fileList
+	}
+
+	for _, file := range fileList {
+		if file != "-" && (render.IsChart(file) || render.IsKustomization(file)) {
+			sources = append(sources, file)
+		} else {
+			files = append(files, file)
+		}
+	}
+
+	return sources, files
+}
+
+// loadEngine loads the policies and data, reusing the engine of a previous
+// run when none of them have changed since.
+func (t *TestRunner) loadEngine() (*policy.Engine, error) {
+	if t.engine != nil {
+		return t.engine, nil
+	}
+
+	capabilities, err := policy.LoadCapabilities(t.Capabilities)
+	if err != nil {
+		return nil, fmt.Errorf("load capabilities: %w", err)
+	}
+	opts := policy.CompilerOptions{
+		Strict:       t.Strict,
+		RegoVersion:  t.RegoVersion,
+		Capabilities: capabilities,
+	}
+	engine, err := policy.LoadWithData(t.Policy, t.Data, opts)
+	if err != nil {
+		return nil, fmt.Errorf("load: %w", err)
+	}
+	engine.EnableInterQueryCache()
+
+	t.engine = engine
+	return engine, nil
+}
+
+// splitStreamable separates the files that can be decoded one document at a
+// time from the files that must be parsed as a whole.
+func splitStreamable(files []string, parserName string) (parsed []string, streamed []string) {
+	for _, file := range files {
+		if parser.Streamable(file, parserName) {
+			streamed = append(streamed, file)
+		} else {
+			parsed = append(parsed, file)
+		}
+	}
+
+	return parsed, streamed
+}
+
+// checkStreams evaluates the policies in the given namespaces against the
+// documents of every file as they are decoded. Up to parallelism files are
+// streamed at the same time.
+func (t *TestRunner) checkStreams(ctx context.Context, engine *policy.Engine, files []string, namespaces []string, parallelism int) (output.CheckResults, error) {
+	fileResults := make([]output.CheckResults, len(files))
+	group, ctx := errgroup.WithContext(ctx)
+	group.SetLimit(parallelism)
+	for i, file := range files {
+		group.Go(func() error {
+			name := file
+			if file == "-" && t.StdinFilename != "" {
+				name = t.StdinFilename
+			}
+
+			stream := func(fn parser.StreamFunc) error {
+				return parser.StreamConfiguration(file, t.Parser, fn)
+			}
+
+			result, err := engine.CheckStream(ctx, name, namespaces, stream)
+			if err != nil {
+				return fmt.Errorf("check stream: %w", err)
+			}
+
+			fileResults[i] = result
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	var results output.CheckResults
+	for _, result := range fileResults {
+		results = append(results, result...)
+	}
+
+	return results, nil
+}
+
+func renameStdinConfiguration[T any](configurations map[string]T, stdinFilename string) {
 	if stdinFilename == "" {
 		return
 	}
@@ -136,7 +458,11 @@ func renameStdinConfiguration(configurations map[string]any, stdinFilename strin
 	configurations[stdinFilename] = configuration
 }
 
-func parseFileList(fileList []string, ignoreRegex string) ([]string, error) {
+// ListFiles returns the files of the list, with the directories of the list
+// replaced by the supported files within them. The paths within directories
+// that match the ignore regex, or are ignored by the .conftestignore files, and
+// the .gitignore files when enabled, are skipped. Standard input is kept.
+func ListFiles(fileList []string, ignoreRegex string, gitignore bool) ([]string, error) {
 	var files []string
 	for _, file := range fileList {
 		if file == "" {
@@ -154,7 +480,7 @@ func parseFileList(fileList []string, ignoreRegex string) ([]string, error) {
 		}
 
 		if fileInfo.IsDir() {
-			directoryFiles, err := getFilesFromDirectory(file, ignoreRegex)
+			directoryFiles, err := getFilesFromDirectory(file, ignoreRegex, gitignore)
 			if err != nil {
 				return nil, fmt.Errorf("get files from directory: %w", err)
 			}
@@ -172,7 +498,7 @@ func parseFileList(fileList []string, ignoreRegex string) ([]string, error) {
 	return files, nil
 }
 
-func getWalkFn(visitedDirs map[string]bool, files *[]string, ignoreRegex string, regexp *regexp.Regexp) filepath.WalkFunc {
+func getWalkFn(visitedDirs map[string]bool, files *[]string, ignoreRegex string, regexp *regexp.Regexp, ignored *ignoreFiles) filepath.WalkFunc {
 	return func(currentPath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return fmt.Errorf("walk path: %w", err)
@@ -183,6 +509,14 @@ func getWalkFn(visitedDirs map[string]bool, files *[]string, ignoreRegex string,
 				return filepath.SkipDir
 			}
 			visitedDirs[currentPath] = true
+
+			skip, err := ignored.ignored(currentPath, true)
+			if err != nil {
+				return err
+			}
+			if skip {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
@@ -190,6 +524,14 @@ func getWalkFn(visitedDirs map[string]bool, files *[]string, ignoreRegex string,
 			return nil
 		}
 
+		skip, err := ignored.ignored(currentPath, false)
+		if err != nil {
+			return err
+		}
+		if skip {
+			return nil
+		}
+
 		if info.Mode()&os.ModeSymlink == 0 {
 			if parser.FileSupported(currentPath) {
 				*files = append(*files, currentPath)
@@ -208,7 +550,11 @@ func getWalkFn(visitedDirs map[string]bool, files *[]string, ignoreRegex string,
 		}
 
 		if ri.IsDir() {
-			return filepath.Walk(realPath, getWalkFn(visitedDirs, files, ignoreRegex, regexp))
+			realIgnored, err := newIgnoreFiles(realPath, ignored.withGitignore)
+			if err != nil {
+				return err
+			}
+			return filepath.Walk(realPath, getWalkFn(visitedDirs, files, ignoreRegex, regexp, realIgnored))
 		}
 
 		if parser.FileSupported(realPath) {
@@ -219,15 +565,20 @@ func getWalkFn(visitedDirs map[string]bool, files *[]string, ignoreRegex string,
 	}
 }
 
-func getFilesFromDirectory(directory string, ignoreRegex string) ([]string, error) {
+func getFilesFromDirectory(directory string, ignoreRegex string, gitignore bool) ([]string, error) {
 	regexp, err := regexp.Compile(ignoreRegex)
 	if err != nil {
 		return nil, fmt.Errorf("given regexp couldn't be parsed :%w", err)
 	}
 
+	ignored, err := newIgnoreFiles(directory, gitignore)
+	if err != nil {
+		return nil, err
+	}
+
 	var files []string
 	visitedDirs := make(map[string]bool)
-	err = filepath.Walk(directory, getWalkFn(visitedDirs, &files, ignoreRegex, regexp))
+	err = filepath.Walk(directory, getWalkFn(visitedDirs, &files, ignoreRegex, regexp, ignored))
 	if err != nil {
 		return nil, err
 	}
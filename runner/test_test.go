@@ -1,8 +1,16 @@
 package runner
 
 import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
 	"testing"
+
+	"github.com/open-policy-agent/conftest/output"
+	"github.com/spf13/viper"
 )
 
 func TestRenameStdinConfiguration(t *testing.T) {
@@ -214,3 +222,288 @@ func TestFilterNamespaces(t *testing.T) {
 		})
 	}
 }
+
+func TestRunnerInvalidate(t *testing.T) {
+	dir := t.TempDir()
+	policyFile := filepath.Join(dir, "policy", "main.rego")
+	configFile := filepath.Join(dir, "config.yaml")
+	failures := func(runner *TestRunner) int {
+		t.Helper()
+		results, err := runner.Run(context.Background(), []string{configFile})
+		if err != nil {
+			t.Fatalf("run: %v", err)
+		}
+
+		return len(results[0].Failures)
+	}
+
+	writeFile(t, policyFile, "package main\n\ndeny contains \"enabled\" if input.enabled\n")
+	writeFile(t, configFile, "enabled: true\n")
+
+	runner := &TestRunner{
+		Policy:      []string{filepath.Dir(policyFile)},
+		RegoVersion: "v1",
+		Namespace:   []string{"main"},
+	}
+	if got := failures(runner); got != 1 {
+		t.Fatalf("got %d failures, want 1", got)
+	}
+
+	// Without invalidating, the configuration parsed by the previous run is used.
+	writeFile(t, configFile, "enabled: false\n")
+	if got := failures(runner); got != 1 {
+		t.Errorf("got %d failures before invalidating the configuration, want 1", got)
+	}
+
+	runner.Invalidate([]string{configFile})
+	if got := failures(runner); got != 0 {
+		t.Errorf("got %d failures after invalidating the configuration, want 0", got)
+	}
+
+	writeFile(t, policyFile, "package main\n\ndeny contains \"disabled\" if not input.enabled\n")
+	runner.Invalidate([]string{policyFile})
+	if got := failures(runner); got != 1 {
+		t.Errorf("got %d failures after invalidating the policy, want 1", got)
+	}
+}
+
+func TestRunnerInvalidateHCL2Evaluate(t *testing.T) {
+	dir := t.TempDir()
+	policyFile := filepath.Join(dir, "policy", "main.rego")
+	configFile := filepath.Join(dir, "main.tf")
+	varFile := filepath.Join(dir, "prod.tfvars")
+	viper.Set("hcl2-evaluate", true)
+	t.Cleanup(func() {
+		viper.Set("hcl2-evaluate", false)
+	})
+
+	writeFile(t, policyFile, "package main\n\ndeny contains \"enabled\" if input.enabled\n")
+	writeFile(t, configFile, "variable \"enabled\" {}\n\nenabled = var.enabled\n")
+	writeFile(t, varFile, "enabled = true\n")
+
+	runner := &TestRunner{
+		Policy:       []string{filepath.Dir(policyFile)},
+		RegoVersion:  "v1",
+		Namespace:    []string{"main"},
+		HCL2Evaluate: true,
+	}
+	failures := func() int {
+		t.Helper()
+		results, err := runner.Run(context.Background(), []string{configFile, varFile})
+		if err != nil {
+			t.Fatalf("run: %v", err)
+		}
+
+		var failures int
+		for _, result := range results {
+			failures += len(result.Failures)
+		}
+		return failures
+	}
+
+	if got := failures(); got != 2 {
+		t.Fatalf("got %d failures, want 2", got)
+	}
+
+	// The configuration depends on the variable file, so it is parsed again
+	// when only the variable file changed.
+	writeFile(t, varFile, "enabled = false\n")
+	runner.Invalidate([]string{varFile})
+	if got := failures(); got != 0 {
+		t.Errorf("got %d failures after invalidating the variable file, want 0", got)
+	}
+}
+
+func TestRunnerSkipsBinaryTerraformPlans(t *testing.T) {
+	runner := &TestRunner{
+		Policy:      []string{"../examples/hcl1/policy"},
+		RegoVersion: "v1",
+		Namespace:   []string{"main"},
+	}
+
+	// The directory has a binary Terraform plan, gke-plan.tfplan, which is
+	// not a supported file, along with the files that are tested.
+	results, err := runner.Run(context.Background(), []string{"../examples/hcl1"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	var files []string
+	for _, result := range results {
+		files = append(files, result.FileName)
+	}
+	sort.Strings(files)
+
+	want := []string{
+		filepath.Join("..", "examples", "hcl1", ".gitignore"),
+		filepath.Join("..", "examples", "hcl1", "gke-show.json"),
+		filepath.Join("..", "examples", "hcl1", "gke.tf"),
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("unexpected files. got %v, want %v", files, want)
+	}
+}
+
+func TestRunnerRenderCharts(t *testing.T) {
+	runner := &TestRunner{
+		Policy:      []string{"../examples/helm/policy"},
+		RegoVersion: "v1",
+		Namespace:   []string{"main"},
+		Values:      []string{"../examples/helm/values-prod.yaml"},
+		Render:      true,
+	}
+
+	results, err := runner.Run(context.Background(), []string{"../examples/helm/chart", "../examples/kubernetes/service.yaml"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	var files []string
+	for _, result := range results {
+		files = append(files, result.FileName)
+	}
+	sort.Strings(files)
+
+	want := []string{
+		filepath.Join("..", "examples", "helm", "chart", "templates", "deployment.yaml"),
+		filepath.Join("..", "examples", "helm", "chart", "templates", "service.yaml"),
+		"../examples/kubernetes/service.yaml",
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("unexpected files. got %v, want %v", files, want)
+	}
+	for _, result := range results {
+		if len(result.Failures) > 0 {
+			t.Errorf("unexpected failures for %s: %v", result.FileName, result.Failures)
+		}
+	}
+}
+
+func TestRunnerStreamMatchesParse(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "policy", "main.rego"), `package main
+
+deny_replicas contains {"msg": "too few replicas", "path": ["spec", "replicas"], "fix": {"spec": {"replicas": 2}}} if {
+	input.spec.replicas < 2
+}
+`)
+	writeFile(t, filepath.Join(dir, "schemas", "deployment-apps-v1.json"), `{"type": "object", "required": ["spec"]}`)
+	writeFile(t, filepath.Join(dir, "multiple.yaml"), `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+---
+# conftest:ignore deny_replicas reason="legacy"
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: legacy
+spec:
+  replicas: 1
+`)
+	writeFile(t, filepath.Join(dir, "list.yaml"), `- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: first
+  spec:
+    replicas: 1
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: second
+`)
+	writeFile(t, filepath.Join(dir, "duplicate.yaml"), "spec:\n  replicas: 1\n  replicas: 1\n")
+	writeFile(t, filepath.Join(dir, "empty.yaml"), "")
+
+	files := []string{
+		filepath.Join(dir, "multiple.yaml"),
+		filepath.Join(dir, "list.yaml"),
+		filepath.Join(dir, "duplicate.yaml"),
+		filepath.Join(dir, "empty.yaml"),
+	}
+
+	tests := []struct {
+		name    string
+		parser  string
+		options map[string]any
+	}{
+		{
+			name:    "yaml",
+			options: map[string]any{"strict-parse": true},
+		},
+		{
+			name:    "kubernetes",
+			parser:  "kubernetes",
+			options: map[string]any{"kubernetes-schema-dir": filepath.Join(dir, "schemas")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.options {
+				viper.Set(key, value)
+			}
+			t.Cleanup(func() {
+				for key := range tt.options {
+					viper.Set(key, nil)
+				}
+			})
+
+			run := func(stream bool) output.CheckResults {
+				t.Helper()
+				runner := &TestRunner{
+					Policy:      []string{filepath.Join(dir, "policy")},
+					RegoVersion: "v1",
+					Namespace:   []string{"main"},
+					Parser:      tt.parser,
+					Stream:      stream,
+				}
+				results, err := runner.Run(context.Background(), files)
+				if err != nil {
+					t.Fatalf("run: %v", err)
+				}
+
+				// Queries contain the input of each evaluation, and streamed files are
+				// reported after the others, so neither is part of the comparison.
+				for i := range results {
+					results[i].Queries = nil
+				}
+				sort.Slice(results, func(a, b int) bool {
+					if results[a].FileName != results[b].FileName {
+						return results[a].FileName < results[b].FileName
+					}
+					return results[a].Namespace < results[b].Namespace
+				})
+
+				return results
+			}
+
+			want := run(false)
+			got := run(true)
+			if !reflect.DeepEqual(got, want) {
+				gotJSON, _ := json.MarshalIndent(got, "", "  ")
+				wantJSON, _ := json.MarshalIndent(want, "", "  ")
+				t.Errorf("streamed results differ from parsed results. got %s, want %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+// writeFile writes the contents to the file at the given path, creating its
+// directory if needed.
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatalf("create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+}
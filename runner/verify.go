@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/open-policy-agent/conftest/output"
@@ -28,6 +29,11 @@ type VerifyRunner struct {
 	ShowBuiltinErrors bool `mapstructure:"show-builtin-errors"`
 	VarValues         bool `mapstructure:"var-values"`
 	Namespace         []string
+	Watch             bool
+
+	// The engine of a previous run, which is kept when watching for changes
+	// until a policy or data file changes, see Invalidate.
+	engine *policy.Engine
 }
 
 const (
@@ -38,18 +44,9 @@ const (
 
 // Run executes the Rego tests for the given policies.
 func (r *VerifyRunner) Run(ctx context.Context) (output.CheckResults, []*tester.Result, error) {
-	capabilities, err := policy.LoadCapabilities(r.Capabilities)
+	engine, err := r.loadEngine()
 	if err != nil {
-		return nil, nil, fmt.Errorf("load capabilities: %w", err)
-	}
-	opts := policy.CompilerOptions{
-		Strict:       r.Strict,
-		RegoVersion:  r.RegoVersion,
-		Capabilities: capabilities,
-	}
-	engine, err := policy.LoadWithData(r.Policy, r.Data, opts)
-	if err != nil {
-		return nil, nil, fmt.Errorf("load: %w", err)
+		return nil, nil, err
 	}
 
 	// Traces should be enabled when Trace or Report options are on
@@ -139,6 +136,42 @@ func (r *VerifyRunner) Run(ctx context.Context) (output.CheckResults, []*tester.
 	return results, rawResults, nil
 }
 
+// Invalidate discards the state kept from previous runs that depends on the
+// given paths, which have changed since the last run. The policies are only
+// loaded again when a Rego file or a data file changed.
+func (r *VerifyRunner) Invalidate(paths []string) {
+	for _, path := range paths {
+		if filepath.Ext(path) == ".rego" || withinPaths(path, r.Data) {
+			r.engine = nil
+		}
+	}
+}
+
+// loadEngine loads the policies and data, reusing the engine of a previous
+// run when none of them have changed since.
+func (r *VerifyRunner) loadEngine() (*policy.Engine, error) {
+	if r.engine != nil {
+		return r.engine, nil
+	}
+
+	capabilities, err := policy.LoadCapabilities(r.Capabilities)
+	if err != nil {
+		return nil, fmt.Errorf("load capabilities: %w", err)
+	}
+	opts := policy.CompilerOptions{
+		Strict:       r.Strict,
+		RegoVersion:  r.RegoVersion,
+		Capabilities: capabilities,
+	}
+	engine, err := policy.LoadWithData(r.Policy, r.Data, opts)
+	if err != nil {
+		return nil, fmt.Errorf("load: %w", err)
+	}
+
+	r.engine = engine
+	return engine, nil
+}
+
 // IsReportOptionOn returns true if the reporting option is turned on, otherwise false.
 func (r *VerifyRunner) IsReportOptionOn() bool {
 	return r.Report == ReportFull ||
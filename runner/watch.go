@@ -0,0 +1,120 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDelay is how long Watch waits for further changes before calling back,
+// as saving a single file in an editor often results in several events.
+const watchDelay = 100 * time.Millisecond
+
+// Watch calls fn with the paths that changed every time a file within the given
+// paths is written, created, removed or renamed, until the context is done. Paths
+// can be files or directories, in which case all of their subdirectories are
+// watched as well.
+func Watch(ctx context.Context, paths []string, fn func(changed []string)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("new watcher: %w", err)
+	}
+	defer watcher.Close() //nolint
+
+	roots := make([]string, 0, len(paths))
+	for _, path := range paths {
+		root := filepath.Clean(path)
+		if err := watchPath(watcher, root); err != nil {
+			return fmt.Errorf("watch %s: %w", path, err)
+		}
+
+		roots = append(roots, root)
+	}
+
+	var changed []string
+	var timer <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+
+			return fmt.Errorf("watch: %w", err)
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			// Files are watched through their parent directory, so events of
+			// their siblings have to be filtered out.
+			if event.Op == fsnotify.Chmod || !withinPaths(event.Name, roots) {
+				continue
+			}
+
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := watchPath(watcher, event.Name); err != nil {
+						return fmt.Errorf("watch %s: %w", event.Name, err)
+					}
+				}
+			}
+
+			if !slices.Contains(changed, event.Name) {
+				changed = append(changed, event.Name)
+			}
+			timer = time.After(watchDelay)
+		case <-timer:
+			fn(changed)
+			changed, timer = nil, nil
+		}
+	}
+}
+
+// watchPath adds the given directory and all of its subdirectories to the
+// watcher. Files are watched through the directory they are in, so that
+// changes are still noticed when editors replace the file on save.
+func watchPath(watcher *fsnotify.Watcher, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("get file info: %w", err)
+	}
+
+	if !info.IsDir() {
+		return watcher.Add(filepath.Dir(path))
+	}
+
+	return filepath.WalkDir(path, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.IsDir() {
+			return nil
+		}
+
+		return watcher.Add(current)
+	})
+}
+
+// withinPaths returns true if the given path is one of the given paths or
+// is contained in one of them.
+func withinPaths(path string, paths []string) bool {
+	for _, parent := range paths {
+		rel, err := filepath.Rel(parent, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	watched := filepath.Join(dir, "watched.yaml")
+	sibling := filepath.Join(dir, "sibling.yaml")
+	for _, file := range []string{watched, sibling} {
+		if err := os.WriteFile(file, []byte("a: 1"), 0600); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan []string, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- Watch(ctx, []string{watched}, func(changed []string) {
+			changes <- changed
+		})
+	}()
+
+	// Give the watcher time to start before making changes.
+	time.Sleep(100 * time.Millisecond)
+	if err := os.WriteFile(sibling, []byte("a: 2"), 0600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(watched, []byte("a: 2"), 0600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	select {
+	case changed := <-changes:
+		if len(changed) != 1 || changed[0] != watched {
+			t.Errorf("got changes %v, want only %s", changed, watched)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for changes")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("watch: %v", err)
+	}
+}
+
+func TestWithinPaths(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path  string
+		paths []string
+		want  bool
+	}{
+		{path: "policy/main.rego", paths: []string{"policy"}, want: true},
+		{path: "policy", paths: []string{"policy"}, want: true},
+		{path: "config.yaml", paths: []string{"."}, want: true},
+		{path: "policy-data/main.rego", paths: []string{"policy"}, want: false},
+		{path: "data/users.json", paths: []string{"policy", "data"}, want: true},
+		{path: "config.yaml", paths: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := withinPaths(tt.path, tt.paths); got != tt.want {
+			t.Errorf("withinPaths(%q, %v) = %v, want %v", tt.path, tt.paths, got, tt.want)
+		}
+	}
+}
@@ -7,3 +7,13 @@
   echo $output
   [[ "$output" =~ "1 test, 1 passed, 0 warnings, 0 failures" ]]
 }
+
+@test "Rule annotations and source are included in JSON results" {
+  run $CONFTEST test -o json --data exclusions service.yaml
+
+  [ "$status" -eq 1 ]
+  echo $output
+  [[ "$output" =~ "\"title\": \"Example using annotations\"" ]]
+  [[ "$output" =~ "\"file\": \"policy/base.rego\"" ]]
+  [[ "$output" =~ "\"row\": 16" ]]
+}